@@ -4,12 +4,17 @@ import (
 	"context"
 	"github.com/emrealsandev/Url-Shortener/internal/cache"
 	appcfg "github.com/emrealsandev/Url-Shortener/internal/config"
+	"github.com/emrealsandev/Url-Shortener/internal/geoip"
 	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/mail"
+	"github.com/emrealsandev/Url-Shortener/internal/oidc"
 	mongorepo "github.com/emrealsandev/Url-Shortener/internal/repo/mongo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
 	"github.com/emrealsandev/Url-Shortener/internal/server"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -41,6 +46,10 @@ func main() {
 
 	db := mcli.Database(cfg.MongoDB)
 	urlRepo := mongorepo.NewURLRepo(db)
+	reportRepo := mongorepo.NewReportRepo(db)
+	userRepo := mongorepo.NewUserRepo(db)
+	workspaceRepo := mongorepo.NewWorkspaceRepo(db)
+	tokenRepo := mongorepo.NewAccessTokenRepo(db)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
@@ -50,8 +59,84 @@ func main() {
 		log.Fatal(err)
 	}
 
+	var geo geoip.Locator
+	if cfg.GeoIPPath != "" {
+		db, err := geoip.Open(cfg.GeoIPPath)
+		if err != nil {
+			log.Fatal("geoip:", err)
+		}
+		geo = db
+	}
+
+	clientIP, err := security.NewClientIPResolver(cfg.TrustedProxies, cfg.ClientIPHeader)
+	if err != nil {
+		log.Fatal("trusted proxies:", err)
+	}
+
+	mailFrom := cfg.MailFrom
+	if mailFrom == "" {
+		mailFrom = "no-reply@localhost"
+	}
+	var mailer mail.Sender
+	switch cfg.MailDriver {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			log.Fatal("mail: SMTP_HOST is required for MAIL_DRIVER=smtp")
+		}
+		port := cfg.SMTPPort
+		if port == "" {
+			port = "587"
+		}
+		mailer = mail.SMTPSender{Host: cfg.SMTPHost, Port: port, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword, From: mailFrom}
+	case "", "file":
+		mailer = mail.FileSender{Dir: cfg.MailDir, From: mailFrom, Logger: loggerInstance}
+	default:
+		log.Fatal("mail: unknown MAIL_DRIVER ", cfg.MailDriver)
+	}
+
+	var sso *oidc.Client
+	if cfg.OIDCIssuer != "" {
+		redirectURL := cfg.OIDCRedirectURL
+		if redirectURL == "" {
+			redirectURL = strings.TrimRight(cfg.BaseURL, "/") + "/v1/auth/oidc/callback"
+		}
+		sso, err = oidc.NewClient(oidc.Config{
+			Issuer:         cfg.OIDCIssuer,
+			ClientID:       cfg.OIDCClientID,
+			ClientSecret:   cfg.OIDCClientSecret,
+			RedirectURL:    redirectURL,
+			Scopes:         cfg.OIDCScopes,
+			AllowedDomains: cfg.OIDCAllowedDomains,
+			AllowedGroups:  cfg.OIDCAllowedGroups,
+			GroupsClaim:    cfg.OIDCGroupsClaim,
+		}, nil, redis, loggerInstance)
+		if err != nil {
+			log.Fatal("oidc:", err)
+		}
+	}
+
+	if cfg.SecretKey == "" {
+		loggerInstance.Warn("SECRET_KEY is empty, signed tokens will not survive restarts")
+	}
+
 	loggerInstance.Info("starting server")
-	srv := server.New(server.Options{Port: cfg.Port, BaseURL: cfg.BaseURL, Repo: urlRepo, Cache: redis, Logger: loggerInstance})
+	srv := server.New(server.Options{
+		Port:            cfg.Port,
+		BaseURL:         cfg.BaseURL,
+		AdminToken:      cfg.AdminToken,
+		SecretKey:       cfg.SecretKey,
+		Repo:            urlRepo,
+		ReportRepo:      reportRepo,
+		UserRepo:        userRepo,
+		WorkspaceRepo:   workspaceRepo,
+		AccessTokenRepo: tokenRepo,
+		Cache:           redis,
+		GeoIP:           geo,
+		ClientIP:        clientIP,
+		Mailer:          mailer,
+		OIDC:            sso,
+		Logger:          loggerInstance,
+	})
 
 	if err := srv.Start(ctx); err != nil {
 		loggerInstance.Error("server stopped with error:", zap.Error(err))
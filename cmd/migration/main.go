@@ -39,5 +39,13 @@ func main() {
 		log.Fatal("migration failed: ", err)
 	}
 
+	codes, err := m.PreviewSuffixCodes(ctx)
+	if err != nil {
+		log.Fatal("preview suffix check: ", err)
+	}
+	if len(codes) > 0 {
+		log.Printf("warning: %d link codes contain \"+\" and open the preview page instead of redirecting: %v", len(codes), codes)
+	}
+
 	log.Println("migration OK")
 }
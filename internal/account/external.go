@@ -0,0 +1,82 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+// ExternalIdentity SSO sağlayıcısının doğruladığı kimlik. E-posta sağlayıcı tarafından doğrulanmış olmalıdır;
+// doğrulanmamış e-postayla mevcut bir hesaba bağlanmak hesabı ele geçirmeye izin verirdi.
+type ExternalIdentity struct {
+	Issuer  string
+	Subject string
+	Email   string
+}
+
+// LoginExternal kimliği kullanıcıya eşler ve oturum açar: önce bağlı kimlik aranır, yoksa aynı e-postalı şifresiz
+// hesaba bağlanır, o da yoksa şifresiz yeni bir hesap (ve sahip ID'si) açılır. Aynı e-postalı şifreli hesap varsa
+// ErrAccountExists döner.
+func (s *Service) LoginExternal(ctx context.Context, ext ExternalIdentity) (*repo.User, string, *Session, error) {
+	if ext.Issuer == "" || ext.Subject == "" {
+		return nil, "", nil, ErrInvalidCredentials
+	}
+	email, err := normalizeEmail(ext.Email)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	u, err := s.externalUser(ctx, ext, email)
+	if errors.Is(err, repo.ErrDuplicate) {
+		// eşzamanlı ilk giriş: diğer istek hesabı/kimliği az önce oluşturdu
+		u, err = s.externalUser(ctx, ext, email)
+	}
+	if errors.Is(err, ErrAccountExists) {
+		s.logger.Warn("sso login refused for password account", "issuer", ext.Issuer)
+		return nil, "", nil, err
+	}
+	if err != nil {
+		s.logger.Error("sso user mapping failed", "issuer", ext.Issuer, "error", err)
+		return nil, "", nil, ErrSystem
+	}
+
+	token, sess, err := s.newSession(ctx, u)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return u, token, sess, nil
+}
+
+func (s *Service) externalUser(ctx context.Context, ext ExternalIdentity, email string) (*repo.User, error) {
+	u, err := s.users.GetByIdentity(ctx, ext.Issuer, ext.Subject)
+	if err != nil || u != nil {
+		return u, err
+	}
+
+	now := time.Now().UTC()
+	identity := repo.Identity{Issuer: ext.Issuer, Subject: ext.Subject, LinkedAt: now}
+
+	u, err = s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if u != nil {
+		// Kayıtta e-posta doğrulanmadığı için şifreli hesap e-postanın sahibine ait olmayabilir; SSO ile bağlanırsa
+		// hesabı önceden açan kişi şifresiyle girmeye devam eder (pre-hijacking). Sadece SSO hesapları bağlanır.
+		if u.PasswordHash != "" {
+			return nil, ErrAccountExists
+		}
+		if err := s.users.AddIdentity(ctx, u.ID, identity); err != nil {
+			return nil, err
+		}
+		return u, nil
+	}
+
+	u = &repo.User{Email: email, Identities: []repo.Identity{identity}, CreatedAt: now, UpdatedAt: now}
+	if err := s.users.Insert(ctx, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
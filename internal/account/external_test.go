@@ -0,0 +1,166 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+const TEST_ISSUER = "https://idp.example.com"
+
+// memUsers SSO girişinin kullandığı metodları bellekte tutar; diğerleri kullanılmaz.
+type memUsers struct {
+	repo.UserRepository
+	mu     sync.Mutex
+	users  map[int64]*repo.User
+	nextID int64
+}
+
+func newMemUsers(users ...repo.User) *memUsers {
+	m := &memUsers{users: map[int64]*repo.User{}, nextID: 100}
+	for i := range users {
+		m.users[users[i].ID] = &users[i]
+	}
+	return m
+}
+
+func (m *memUsers) Insert(ctx context.Context, u *repo.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, x := range m.users {
+		if x.Email == u.Email {
+			return repo.ErrDuplicate
+		}
+	}
+	m.nextID++
+	u.ID = m.nextID
+	cp := *u
+	m.users[u.ID] = &cp
+	return nil
+}
+
+func (m *memUsers) GetByEmail(ctx context.Context, email string) (*repo.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if u.Email == email {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memUsers) GetByIdentity(ctx context.Context, issuer, subject string) (*repo.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		for _, id := range u.Identities {
+			if id.Issuer == issuer && id.Subject == subject {
+				cp := *u
+				return &cp, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (m *memUsers) AddIdentity(ctx context.Context, id int64, identity repo.Identity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users[id].Identities = append(m.users[id].Identities, identity)
+	return nil
+}
+
+// memSessions sadece oturum kaydını tutar.
+type memSessions struct {
+	cache.Cache
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func (c *memSessions) SetString(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys[key] = value
+	return nil
+}
+
+func newTestService(users *memUsers) (*Service, *memSessions) {
+	sessions := &memSessions{keys: map[string]string{}}
+	return &Service{users: users, cache: sessions, logger: logger.GetLogger()}, sessions
+}
+
+func TestLoginExternalLinksSSOAccount(t *testing.T) {
+	// Başka bir sağlayıcıyla açılmış, şifresiz hesap: e-posta zaten bir IdP tarafından doğrulanmış.
+	users := newMemUsers(repo.User{ID: 1, Email: "ada@corp.com", Identities: []repo.Identity{{Issuer: "https://old-idp.example.com", Subject: "old"}}})
+	s, sessions := newTestService(users)
+	ext := ExternalIdentity{Issuer: TEST_ISSUER, Subject: "user-1", Email: "Ada@corp.com"}
+
+	u, token, _, err := s.LoginExternal(context.Background(), ext)
+	if err != nil {
+		t.Fatalf("LoginExternal: %v", err)
+	}
+	if u.ID != 1 || token == "" || len(sessions.keys) != 1 {
+		t.Fatalf("user = %d, token = %q, sessions = %d", u.ID, token, len(sessions.keys))
+	}
+	if ids := users.users[1].Identities; len(ids) != 2 || ids[1].Issuer != TEST_ISSUER || ids[1].Subject != "user-1" {
+		t.Fatalf("identities = %+v", ids)
+	}
+
+	// Sonraki girişler kimlikle bulunur, yeni hesap açılmaz.
+	u, _, _, err = s.LoginExternal(context.Background(), ext)
+	if err != nil || u.ID != 1 || len(users.users) != 1 {
+		t.Fatalf("second login: user = %v, err = %v, users = %d", u, err, len(users.users))
+	}
+}
+
+func TestLoginExternalCreatesAccount(t *testing.T) {
+	users := newMemUsers()
+	s, _ := newTestService(users)
+
+	u, _, _, err := s.LoginExternal(context.Background(), ExternalIdentity{Issuer: TEST_ISSUER, Subject: "user-1", Email: "ada@corp.com"})
+	if err != nil {
+		t.Fatalf("LoginExternal: %v", err)
+	}
+	if u.PasswordHash != "" || len(u.Identities) != 1 || len(users.users) != 1 {
+		t.Fatalf("user = %+v", u)
+	}
+}
+
+func TestLoginExternalRefusesPasswordAccount(t *testing.T) {
+	// Saldırgan kurbanın e-postasıyla önceden kayıt olmuş; kayıt e-postayı doğrulamaz.
+	users := newMemUsers(repo.User{ID: 1, Email: "ada@corp.com", PasswordHash: "$2a$10$attacker"})
+	s, sessions := newTestService(users)
+
+	u, token, sess, err := s.LoginExternal(context.Background(), ExternalIdentity{Issuer: TEST_ISSUER, Subject: "user-1", Email: "ada@corp.com"})
+	if !errors.Is(err, ErrAccountExists) {
+		t.Fatalf("err = %v, want %v", err, ErrAccountExists)
+	}
+	if u != nil || token != "" || sess != nil || len(sessions.keys) != 0 {
+		t.Fatalf("session opened for a refused login: user = %v, token = %q", u, token)
+	}
+	if ids := users.users[1].Identities; len(ids) != 0 {
+		t.Fatalf("identity linked to a password account: %+v", ids)
+	}
+	if len(users.users) != 1 {
+		t.Fatalf("users = %d, want no new account", len(users.users))
+	}
+}
+
+func TestLoginExternalLinkedPasswordAccount(t *testing.T) {
+	// SSO ile açılıp sonradan "şifremi unuttum" ile şifre alan hesap kimliğiyle girmeye devam eder.
+	users := newMemUsers(repo.User{ID: 1, Email: "ada@corp.com", PasswordHash: "$2a$10$owner", Identities: []repo.Identity{{Issuer: TEST_ISSUER, Subject: "user-1"}}})
+	s, _ := newTestService(users)
+
+	u, _, _, err := s.LoginExternal(context.Background(), ExternalIdentity{Issuer: TEST_ISSUER, Subject: "user-1", Email: "ada@corp.com"})
+	if err != nil || u.ID != 1 {
+		t.Fatalf("user = %v, err = %v", u, err)
+	}
+}
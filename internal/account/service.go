@@ -0,0 +1,352 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	mailer "github.com/emrealsandev/Url-Shortener/internal/mail"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidEmail       = errors.New("invalid_email")
+	ErrInvalidPassword    = errors.New("invalid_password")
+	ErrEmailTaken         = errors.New("email_taken")
+	ErrAccountExists      = errors.New("account_exists")
+	ErrInvalidCredentials = errors.New("invalid_credentials")
+	ErrTooManyAttempts    = errors.New("too_many_attempts")
+	ErrInvalidToken       = errors.New("invalid_token")
+	ErrInvalidSession     = errors.New("invalid_session")
+	ErrSystem             = errors.New("system_error")
+)
+
+const (
+	SESSION_COOKIE = "sid"
+	SESSION_TTL    = 7 * 24 * time.Hour
+
+	MIN_PASSWORD_LENGTH = 8
+	MAX_PASSWORD_LENGTH = 72 // bcrypt sınırı
+	MAX_EMAIL_LENGTH    = 254
+
+	LOGIN_WINDOW         = 15 * time.Minute
+	LOGIN_MAX_PER_CLIENT = 10
+	LOGIN_MAX_PER_EMAIL  = 5
+
+	RESET_TOKEN_TTL     = time.Hour
+	RESET_WINDOW        = time.Hour
+	RESET_MAX_PER_EMAIL = 3
+	MAIL_TIMEOUT        = 30 * time.Second
+
+	SESSION_KEY_PREFIX = "sess:"
+	RESET_KEY_PREFIX   = "pwreset:"
+)
+
+// Session Redis'te "sess:<token hash>" altında tutulur; cookie'deki token'ın kendisi saklanmaz.
+type Session struct {
+	UserID    int64     `json:"user_id"`
+	CSRFToken string    `json:"csrf_token"`
+	Version   int64     `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidCSRF header'daki token'ı sabit zamanlı karşılaştırır.
+func (s *Session) ValidCSRF(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.CSRFToken)) == 1
+}
+
+type Service struct {
+	users   repo.UserRepository
+	cache   cache.Cache
+	mail    mailer.Sender
+	baseURL string
+	logger  logger.Logger
+	// dummyHash bilinmeyen e-postalarda da bcrypt çalışsın diye; yanıt süresi hesabın varlığını ele vermesin.
+	dummyHash []byte
+}
+
+func NewService(users repo.UserRepository, c cache.Cache, mail mailer.Sender, baseURL string, logger logger.Logger) *Service {
+	dummy, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return &Service{users: users, cache: c, mail: mail, baseURL: strings.TrimRight(baseURL, "/"), logger: logger, dummyHash: dummy}
+}
+
+// Register hesabı oluşturur ve oturum açar.
+func (s *Service) Register(ctx context.Context, email, password string) (*repo.User, string, *Session, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	now := time.Now().UTC()
+	u := &repo.User{Email: email, PasswordHash: hash, CreatedAt: now, UpdatedAt: now}
+	if err := s.users.Insert(ctx, u); err != nil {
+		if errors.Is(err, repo.ErrDuplicate) {
+			return nil, "", nil, ErrEmailTaken
+		}
+		s.logger.Error("user insert failed", "error", err)
+		return nil, "", nil, ErrSystem
+	}
+
+	token, sess, err := s.newSession(ctx, u)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return u, token, sess, nil
+}
+
+// Login deneme sayısı hem istemci hem e-posta bazında sınırlanır.
+func (s *Service) Login(ctx context.Context, email, password, client string) (*repo.User, string, *Session, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return nil, "", nil, ErrInvalidCredentials
+	}
+
+	perClient, err := s.cache.Incr(ctx, "login:ip:"+client, LOGIN_WINDOW)
+	if err != nil {
+		s.logger.Error("login throttle failed", "error", err)
+		return nil, "", nil, ErrSystem
+	}
+	perEmail, err := s.cache.Incr(ctx, "login:email:"+hashToken(email), LOGIN_WINDOW)
+	if err != nil {
+		s.logger.Error("login throttle failed", "error", err)
+		return nil, "", nil, ErrSystem
+	}
+	if perClient > LOGIN_MAX_PER_CLIENT || perEmail > LOGIN_MAX_PER_EMAIL {
+		return nil, "", nil, ErrTooManyAttempts
+	}
+
+	u, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		s.logger.Error("user lookup failed", "error", err)
+		return nil, "", nil, ErrSystem
+	}
+	// SSO ile açılmış hesapların şifresi yoktur; onlar da bilinmeyen e-posta gibi davranır
+	if u == nil || u.PasswordHash == "" {
+		_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return nil, "", nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		return nil, "", nil, ErrInvalidCredentials
+	}
+
+	// başarılı girişte e-posta sayacı sıfırlanır; IP sayacı kalır
+	if _, err := s.cache.Del(ctx, "login:email:"+hashToken(email)); err != nil {
+		s.logger.Error("login throttle reset failed", "error", err)
+	}
+
+	token, sess, err := s.newSession(ctx, u)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return u, token, sess, nil
+}
+
+// Authenticate cookie'deki token'ın oturumunu ve kullanıcısını döner.
+// Şifre değiştiyse (SessionVersion arttıysa) oturum silinir ve ErrInvalidSession döner.
+func (s *Service) Authenticate(ctx context.Context, token string) (*repo.User, *Session, error) {
+	if token == "" {
+		return nil, nil, ErrInvalidSession
+	}
+	key := SESSION_KEY_PREFIX + hashToken(token)
+	raw, ok, err := s.cache.GetString(ctx, key)
+	if err != nil {
+		s.logger.Error("session read failed", "error", err)
+		return nil, nil, ErrSystem
+	}
+	if !ok {
+		return nil, nil, ErrInvalidSession
+	}
+
+	var sess Session
+	if err := json.Unmarshal([]byte(raw), &sess); err != nil {
+		return nil, nil, ErrInvalidSession
+	}
+
+	u, err := s.users.GetByID(ctx, sess.UserID)
+	if err != nil {
+		s.logger.Error("user lookup failed", "user_id", sess.UserID, "error", err)
+		return nil, nil, ErrSystem
+	}
+	if u == nil || u.SessionVersion != sess.Version {
+		_, _ = s.cache.Del(ctx, key)
+		return nil, nil, ErrInvalidSession
+	}
+	return u, &sess, nil
+}
+
+func (s *Service) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	if _, err := s.cache.Del(ctx, SESSION_KEY_PREFIX+hashToken(token)); err != nil {
+		s.logger.Error("session delete failed", "error", err)
+		return ErrSystem
+	}
+	return nil
+}
+
+// RequestPasswordReset hesap varsa sıfırlama linkini e-postayla gönderir. Hesabın var olup olmadığı
+// dışarıdan anlaşılmasın diye hata dönmez ve gönderim arka planda yapılır.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return
+	}
+
+	n, err := s.cache.Incr(ctx, "pwforgot:"+hashToken(email), RESET_WINDOW)
+	if err != nil {
+		s.logger.Error("reset throttle failed", "error", err)
+		return
+	}
+	if n > RESET_MAX_PER_EMAIL {
+		return
+	}
+
+	u, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		s.logger.Error("user lookup failed", "error", err)
+		return
+	}
+	if u == nil {
+		return
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return
+	}
+	if err := s.cache.SetString(ctx, RESET_KEY_PREFIX+hashToken(token), strconv.FormatInt(u.ID, 10), RESET_TOKEN_TTL); err != nil {
+		s.logger.Error("reset token save failed", "user_id", u.ID, "error", err)
+		return
+	}
+
+	msg := mailer.Message{
+		To:      u.Email,
+		Subject: "Şifre sıfırlama",
+		Body: "Şifreni sıfırlamak için aşağıdaki linki aç (1 saat geçerli):\n\n" +
+			s.baseURL + "/account?reset=" + token + "\n\n" +
+			"Bu isteği sen yapmadıysan bu e-postayı görmezden gelebilirsin.\n",
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), MAIL_TIMEOUT)
+		defer cancel()
+		if err := s.mail.Send(ctx, msg); err != nil {
+			s.logger.Error("reset mail failed", "user_id", u.ID, "error", err)
+		}
+	}()
+}
+
+// ResetPassword token tek kullanımlıktır; şifre değişince bütün oturumlar kapanır.
+func (s *Service) ResetPassword(ctx context.Context, token, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return ErrInvalidToken
+	}
+
+	key := RESET_KEY_PREFIX + hashToken(token)
+	raw, ok, err := s.cache.GetString(ctx, key)
+	if err != nil {
+		s.logger.Error("reset token read failed", "error", err)
+		return ErrSystem
+	}
+	if !ok {
+		return ErrInvalidToken
+	}
+	// Del'i kazanan token'ı kullanır; aynı token'la eşzamanlı iki istekten sadece biri geçer.
+	claimed, err := s.cache.Del(ctx, key)
+	if err != nil {
+		s.logger.Error("reset token delete failed", "error", err)
+		return ErrSystem
+	}
+	if !claimed {
+		return ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if err := s.users.SetPassword(ctx, userID, hash); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrInvalidToken
+		}
+		s.logger.Error("password update failed", "user_id", userID, "error", err)
+		return ErrSystem
+	}
+	return nil
+}
+
+func (s *Service) newSession(ctx context.Context, u *repo.User) (string, *Session, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", nil, ErrSystem
+	}
+	csrf, err := randomToken()
+	if err != nil {
+		return "", nil, ErrSystem
+	}
+
+	sess := &Session{UserID: u.ID, CSRFToken: csrf, Version: u.SessionVersion, CreatedAt: time.Now().UTC()}
+	raw, _ := json.Marshal(sess)
+	if err := s.cache.SetString(ctx, SESSION_KEY_PREFIX+hashToken(token), string(raw), SESSION_TTL); err != nil {
+		s.logger.Error("session save failed", "user_id", u.ID, "error", err)
+		return "", nil, ErrSystem
+	}
+	return token, sess, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || len(email) > MAX_EMAIL_LENGTH {
+		return "", ErrInvalidEmail
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < MIN_PASSWORD_LENGTH || len(password) > MAX_PASSWORD_LENGTH {
+		return "", ErrInvalidPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", ErrSystem
+	}
+	return string(hash), nil
+}
+
+func randomToken() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -12,7 +12,32 @@ type Cache interface {
 
 	GetCodeByURLKey(ctx context.Context, urlKey string) (string, bool, error)
 	SetCodeByURLKey(ctx context.Context, urlKey, code string, ttl time.Duration) error
+	DelCodeByURLKey(ctx context.Context, urlKey string) error
 	IsKeyExists(ctx context.Context, key string) int64
+	// Incr sayacı artırır, anahtar ilk kez oluşuyorsa ttl atar.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// IncrBy TTL'e dokunmadan sayacı n kadar değiştirir.
+	IncrBy(ctx context.Context, key string, n int64) (int64, error)
+	// GetInt anahtar yoksa false döner.
+	GetInt(ctx context.Context, key string) (int64, bool, error)
+	// SetIntNX anahtar yoksa yazar; yazdıysa true.
+	SetIntNX(ctx context.Context, key string, value int64, ttl time.Duration) (bool, error)
+	// GetString anahtar yoksa false döner.
+	GetString(ctx context.Context, key string) (string, bool, error)
+	SetString(ctx context.Context, key, value string, ttl time.Duration) error
+	// Del anahtar silindiyse true döner; tek kullanımlık token'ları sahiplenmek için.
+	Del(ctx context.Context, key string) (bool, error)
+	AddToSet(ctx context.Context, key, member string) error
+	PopFromSet(ctx context.Context, key string, n int64) ([]string, error)
+	// RateLimit key için window içindeki istekleri sayar; limit aşılmadıysa isteği kaydeder.
+	RateLimit(ctx context.Context, key string, limit int64, window time.Duration) (RateLimitResult, error)
 	GetHash(hashKey string, dest any) error
 	SetHash(hashKey string, src any, ttl int16) error
 }
+
+// RateLimitResult Reset penceredeki en eski isteğin düşmesine kalan süre; reddedilen istek en erken o zaman tekrar denenebilir.
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int64
+	Reset     time.Duration
+}
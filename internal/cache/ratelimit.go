@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript sorted set'te pencere içindeki isteklerin zamanını tutar (sliding window log).
+// Saat Redis'ten alınır; böylece replikalar arasındaki saat farkı sonucu etkilemez.
+var slidingWindowScript = redis.NewScript(`
+-- Redis < 7'de TIME sonrası yazma için gerekli, 7+'da etkisiz.
+redis.replicate_commands()
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[3])
+	redis.call('PEXPIRE', key, window)
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
+`)
+
+func (c *Redis) RateLimit(ctx context.Context, key string, limit int64, window time.Duration) (RateLimitResult, error) {
+	// Aynı milisaniyedeki istekler ayrı sayılsın diye her kayıt benzersiz.
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+
+	res, err := slidingWindowScript.Run(ctx, c.Rdb, []string{key}, window.Milliseconds(), limit, hex.EncodeToString(b[:])).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	return RateLimitResult{
+		Allowed:   res[0] == 1,
+		Remaining: res[1],
+		Reset:     time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
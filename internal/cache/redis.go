@@ -42,11 +42,78 @@ func (c *Redis) GetCodeByURLKey(ctx context.Context, urlKey string) (string, boo
 func (c *Redis) SetCodeByURLKey(ctx context.Context, urlKey, code string, ttl time.Duration) error {
 	return c.Rdb.Set(ctx, "u:"+urlKey, code, ttl).Err()
 }
+func (c *Redis) DelCodeByURLKey(ctx context.Context, urlKey string) error {
+	return c.Rdb.Del(ctx, "u:"+urlKey).Err()
+}
 
 func (c *Redis) IsKeyExists(ctx context.Context, key string) int64 {
 	return c.Rdb.Exists(ctx, key).Val()
 }
 
+func (c *Redis) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	n, err := c.Rdb.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 && ttl > 0 {
+		if err := c.Rdb.Expire(ctx, key, ttl).Err(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (c *Redis) IncrBy(ctx context.Context, key string, n int64) (int64, error) {
+	return c.Rdb.IncrBy(ctx, key, n).Result()
+}
+
+func (c *Redis) GetInt(ctx context.Context, key string) (int64, bool, error) {
+	n, err := c.Rdb.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return n, true, nil
+}
+
+func (c *Redis) SetIntNX(ctx context.Context, key string, value int64, ttl time.Duration) (bool, error) {
+	return c.Rdb.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (c *Redis) GetString(ctx context.Context, key string) (string, bool, error) {
+	v, err := c.Rdb.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
+
+func (c *Redis) SetString(ctx context.Context, key, value string, ttl time.Duration) error {
+	return c.Rdb.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *Redis) Del(ctx context.Context, key string) (bool, error) {
+	n, err := c.Rdb.Del(ctx, key).Result()
+	return n > 0, err
+}
+
+func (c *Redis) AddToSet(ctx context.Context, key, member string) error {
+	return c.Rdb.SAdd(ctx, key, member).Err()
+}
+
+func (c *Redis) PopFromSet(ctx context.Context, key string, n int64) ([]string, error) {
+	out, err := c.Rdb.SPopN(ctx, key, n).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return out, err
+}
+
 func (c *Redis) GetHash(hashKey string, dest any) error {
 	v := reflect.ValueOf(dest)
 	if v.Kind() != reflect.Ptr || v.IsNil() {
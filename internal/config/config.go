@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -24,6 +25,34 @@ type Config struct {
 	RedisAddr     string `envconfig:"REDIS_ADDR" default:"localhost:6379"`
 	RedisPassword string `envconfig:"REDIS_PASSWORD" default:""`
 	RedisDB       int    `envconfig:"REDIS_DB"`
+
+	AdminToken string `envconfig:"ADMIN_TOKEN" default:""`
+	SecretKey  string `envconfig:"SECRET_KEY" default:""`
+
+	GeoIPPath string `envconfig:"GEOIP_DB_PATH" default:""`
+
+	// Virgülle ayrılmış CIDR/IP listesi; sadece bu adreslerden gelen proxy başlıklarına güvenilir.
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES" default:""`
+	ClientIPHeader string   `envconfig:"CLIENT_IP_HEADER" default:"X-Forwarded-For"`
+
+	// MailDriver "smtp" veya "file"; file mesajları MailDir'e yazar (boşsa sadece loglar).
+	MailDriver   string `envconfig:"MAIL_DRIVER" default:"file"`
+	MailFrom     string `envconfig:"MAIL_FROM" default:"no-reply@localhost"`
+	MailDir      string `envconfig:"MAIL_DIR" default:""`
+	SMTPHost     string `envconfig:"SMTP_HOST" default:""`
+	SMTPPort     string `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername string `envconfig:"SMTP_USERNAME" default:""`
+	SMTPPassword string `envconfig:"SMTP_PASSWORD" default:""`
+
+	// OIDC SSO; OIDCIssuer boşsa kapalı. Alan adı/grup listeleri virgülle ayrılır, boşsa kısıt yok.
+	OIDCIssuer         string   `envconfig:"OIDC_ISSUER" default:""`
+	OIDCClientID       string   `envconfig:"OIDC_CLIENT_ID" default:""`
+	OIDCClientSecret   string   `envconfig:"OIDC_CLIENT_SECRET" default:""`
+	OIDCRedirectURL    string   `envconfig:"OIDC_REDIRECT_URL" default:""`
+	OIDCScopes         []string `envconfig:"OIDC_SCOPES" default:""`
+	OIDCAllowedDomains []string `envconfig:"OIDC_ALLOWED_DOMAINS" default:""`
+	OIDCAllowedGroups  []string `envconfig:"OIDC_ALLOWED_GROUPS" default:""`
+	OIDCGroupsClaim    string   `envconfig:"OIDC_GROUPS_CLAIM" default:"groups"`
 }
 
 var (
@@ -40,20 +69,51 @@ func Load() *Config {
 		redisDb, _ := strconv.Atoi(os.Getenv("REDIS_DB"))
 
 		cfg = &Config{
-			Port:          os.Getenv("PORT"),
-			BaseURL:       os.Getenv("BASE_URL"),
-			MongoURI:      os.Getenv("MONGO_URI"),
-			MongoDB:       os.Getenv("MONGO_DB"),
-			Environment:   os.Getenv("APP_ENVIRONMENT"),
-			SequenceSalt:  os.Getenv("SEQUENCE_SALT"),
-			RedisAddr:     os.Getenv("REDIS_ADDR"),
-			RedisPassword: os.Getenv("REDIS_PASSWORD"),
-			RedisDB:       redisDb,
+			Port:           os.Getenv("PORT"),
+			BaseURL:        os.Getenv("BASE_URL"),
+			MongoURI:       os.Getenv("MONGO_URI"),
+			MongoDB:        os.Getenv("MONGO_DB"),
+			Environment:    os.Getenv("APP_ENVIRONMENT"),
+			SequenceSalt:   os.Getenv("SEQUENCE_SALT"),
+			RedisAddr:      os.Getenv("REDIS_ADDR"),
+			RedisPassword:  os.Getenv("REDIS_PASSWORD"),
+			RedisDB:        redisDb,
+			AdminToken:     os.Getenv("ADMIN_TOKEN"),
+			SecretKey:      os.Getenv("SECRET_KEY"),
+			GeoIPPath:      os.Getenv("GEOIP_DB_PATH"),
+			TrustedProxies: splitList(os.Getenv("TRUSTED_PROXIES")),
+			ClientIPHeader: os.Getenv("CLIENT_IP_HEADER"),
+			MailDriver:     os.Getenv("MAIL_DRIVER"),
+			MailFrom:       os.Getenv("MAIL_FROM"),
+			MailDir:        os.Getenv("MAIL_DIR"),
+			SMTPHost:       os.Getenv("SMTP_HOST"),
+			SMTPPort:       os.Getenv("SMTP_PORT"),
+			SMTPUsername:   os.Getenv("SMTP_USERNAME"),
+			SMTPPassword:   os.Getenv("SMTP_PASSWORD"),
+
+			OIDCIssuer:         os.Getenv("OIDC_ISSUER"),
+			OIDCClientID:       os.Getenv("OIDC_CLIENT_ID"),
+			OIDCClientSecret:   os.Getenv("OIDC_CLIENT_SECRET"),
+			OIDCRedirectURL:    os.Getenv("OIDC_REDIRECT_URL"),
+			OIDCScopes:         strings.Fields(strings.ReplaceAll(os.Getenv("OIDC_SCOPES"), ",", " ")),
+			OIDCAllowedDomains: splitList(os.Getenv("OIDC_ALLOWED_DOMAINS")),
+			OIDCAllowedGroups:  splitList(os.Getenv("OIDC_ALLOWED_GROUPS")),
+			OIDCGroupsClaim:    os.Getenv("OIDC_GROUPS_CLAIM"),
 		}
 	})
 	return cfg
 }
 
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func Get() *Config {
 	return Load()
 }
@@ -0,0 +1,99 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Locator IP adresinden ISO 3166-1 alpha-2 ülke kodu bulur. Bilinmiyorsa boş döner.
+type Locator interface {
+	Country(ip string) string
+}
+
+type ipRange struct {
+	start   netip.Addr
+	end     netip.Addr
+	country string
+}
+
+// DB "start_ip,end_ip,country" satırlarından oluşan yerel CSV veritabanı
+// (ör. db-ip "IP to Country Lite" CSV). IPv4 ve IPv6 aralıkları desteklenir.
+type DB struct {
+	ranges []ipRange
+}
+
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+func Load(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	db := &DB{}
+	line := 0
+	for {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("geoip line %d: %w", line, err)
+		}
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("geoip line %d: expected start,end,country", line)
+		}
+
+		start, err := parseAddr(rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("geoip line %d: %w", line, err)
+		}
+		end, err := parseAddr(rec[1])
+		if err != nil {
+			return nil, fmt.Errorf("geoip line %d: %w", line, err)
+		}
+		db.ranges = append(db.ranges, ipRange{start: start, end: end, country: strings.ToUpper(strings.TrimSpace(rec[2]))})
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool { return db.ranges[i].start.Less(db.ranges[j].start) })
+	return db, nil
+}
+
+func (d *DB) Country(ip string) string {
+	if d == nil {
+		return ""
+	}
+	addr, err := parseAddr(ip)
+	if err != nil {
+		return ""
+	}
+
+	// start > addr olan ilk aralığın bir öncesi adayımız.
+	i := sort.Search(len(d.ranges), func(i int) bool { return addr.Less(d.ranges[i].start) }) - 1
+	if i < 0 || d.ranges[i].end.Less(addr) {
+		return ""
+	}
+	return d.ranges[i].country
+}
+
+// parseAddr IPv4 adresleri IPv4-mapped IPv6 olarak döner ki tüm aralıklar tek uzayda karşılaştırılsın.
+func parseAddr(s string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return netip.AddrFrom16(addr.As16()), nil
+}
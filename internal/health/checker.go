@@ -0,0 +1,273 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
+	"github.com/emrealsandev/Url-Shortener/internal/config"
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+)
+
+const (
+	SWEEP_TICK     = time.Minute
+	SWEEP_LOCK_KEY = "lock:health_sweep"
+	BATCH_SIZE     = 100
+	WORKER_COUNT   = 8
+	// PER_HOST_LIMIT aynı domaine aynı anda en fazla bu kadar istek gider.
+	PER_HOST_LIMIT = 2
+	CHECK_TIMEOUT  = 10 * time.Second
+	USER_AGENT     = "UrlShortenerHealthCheck/1.0"
+)
+
+// Checker aktif linklerin hedeflerini Settings.HealthCheckInterval aralıklarla kontrol eder.
+// Birden fazla instance'ta süpürme Redis kilidiyle tek bir yerde çalışır.
+type Checker struct {
+	svc      *short.Service
+	repo     repo.Repository
+	settings *config.Provider
+	cache    cache.Cache
+	client   *http.Client
+	notifier Notifier
+	logger   logger.Logger
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+// hostSlot bir domainin semaforu; refs onu bekleyen ya da tutan istek sayısı, 0'a inince map'ten silinir.
+type hostSlot struct {
+	sem  chan struct{}
+	refs int
+}
+
+func NewChecker(svc *short.Service, r repo.Repository, settings *config.Provider, c cache.Cache, n Notifier, logger logger.Logger) *Checker {
+	return &Checker{
+		svc:      svc,
+		repo:     r,
+		settings: settings,
+		cache:    c,
+		client:   security.NewSafeClient(security.SafeClientOptions{Timeout: CHECK_TIMEOUT, MaxRedirects: 5, UserAgent: USER_AGENT}),
+		notifier: n,
+		logger:   logger,
+		hosts:    map[string]*hostSlot{},
+	}
+}
+
+// Run ctx iptal edilene kadar dakikada bir süpürme zamanı gelmiş mi diye bakar.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(SWEEP_TICK)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			settings, err := c.settings.Get()
+			if err != nil {
+				c.logger.Error("health: settings read failed", "error", err)
+				continue
+			}
+			if settings.HealthCheckInterval <= 0 {
+				continue
+			}
+			interval := time.Duration(settings.HealthCheckInterval) * time.Minute
+
+			// Kilit interval boyunca yaşar; o süre içinde başka instance süpürme başlatmaz.
+			n, err := c.cache.Incr(ctx, SWEEP_LOCK_KEY, interval)
+			if err != nil {
+				c.logger.Error("health: lock failed", "error", err)
+				continue
+			}
+			if n != 1 {
+				continue
+			}
+			c.sweep(ctx, settings, time.Now().Add(-interval))
+		}
+	}
+}
+
+// sweep son kontrolü cutoff'tan eski olan linkleri kod sırasıyla sayfa sayfa kontrol eder.
+func (c *Checker) sweep(ctx context.Context, settings repo.Settings, cutoff time.Time) {
+	jobs := make(chan repo.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < WORKER_COUNT; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				c.checkLink(ctx, u, settings)
+			}
+		}()
+	}
+
+	checked, after := 0, ""
+	for ctx.Err() == nil {
+		batch, err := c.repo.ListDueForHealthCheck(ctx, after, cutoff, BATCH_SIZE)
+		if err != nil {
+			c.logger.Error("health: list failed", "error", err)
+			break
+		}
+		for _, u := range batch {
+			select {
+			case jobs <- u:
+				checked++
+			case <-ctx.Done():
+			}
+		}
+		if len(batch) < BATCH_SIZE {
+			break
+		}
+		after = batch[len(batch)-1].Code
+	}
+	close(jobs)
+	wg.Wait()
+
+	c.logger.Info("health: sweep done", "checked", checked)
+}
+
+func (c *Checker) checkLink(ctx context.Context, u repo.URL, settings repo.Settings) {
+	check, ok := c.checkTarget(ctx, u.Target)
+	if !ok {
+		return
+	}
+
+	// Yedekler sadece ana hedef başarısızken kontrol edilir; degraded olunca seçim hazır olur.
+	var fallbacks []repo.FallbackHealth
+	if !check.OK && len(u.FallbackTargets) > 0 {
+		fallbacks = make([]repo.FallbackHealth, 0, len(u.FallbackTargets))
+		for _, target := range u.FallbackTargets {
+			fc, ok := c.checkTarget(ctx, target)
+			if !ok {
+				return
+			}
+			fallbacks = append(fallbacks, repo.FallbackHealth{
+				Target: target, OK: fc.OK, CheckedAt: fc.CheckedAt, StatusCode: fc.StatusCode, Error: fc.Error,
+			})
+		}
+	}
+
+	changed, err := c.svc.RecordHealth(ctx, u, check, fallbacks, settings)
+	if err != nil {
+		c.logger.Error("health: record failed", "code", u.Code, "error", err)
+		return
+	}
+	if changed != "" {
+		c.notifier.Notify(ctx, u, changed, check)
+	}
+}
+
+// checkTarget domain sınırına uyarak hedefi kontrol eder; ctx iptal edilirse false döner.
+func (c *Checker) checkTarget(ctx context.Context, target string) (repo.HealthCheck, bool) {
+	release, ok := c.acquireHost(ctx, target)
+	if !ok {
+		return repo.HealthCheck{}, false
+	}
+	defer release()
+	return c.Check(ctx, target), true
+}
+
+// acquireHost domain başına eşzamanlı istek sayısını sınırlar. Boşta kalan domainlerin kaydı silinir,
+// yoksa her yeni hedef domaini map'te sonsuza kadar kalırdı.
+func (c *Checker) acquireHost(ctx context.Context, target string) (func(), bool) {
+	host := target
+	if parsed, err := url.Parse(target); err == nil {
+		host = parsed.Hostname()
+	}
+
+	c.mu.Lock()
+	slot, ok := c.hosts[host]
+	if !ok {
+		slot = &hostSlot{sem: make(chan struct{}, PER_HOST_LIMIT)}
+		c.hosts[host] = slot
+	}
+	slot.refs++
+	c.mu.Unlock()
+
+	select {
+	case slot.sem <- struct{}{}:
+		return func() {
+			<-slot.sem
+			c.releaseHost(host, slot)
+		}, true
+	case <-ctx.Done():
+		c.releaseHost(host, slot)
+		return nil, false
+	}
+}
+
+func (c *Checker) releaseHost(host string, slot *hostSlot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slot.refs--
+	if slot.refs == 0 && c.hosts[host] == slot {
+		delete(c.hosts, host)
+	}
+}
+
+// Check hedefe önce HEAD atar; HEAD'i desteklemeyen sunucular için başarısızlıkta GET ile tekrar dener.
+func (c *Checker) Check(ctx context.Context, target string) repo.HealthCheck {
+	start := time.Now()
+	check := repo.HealthCheck{CheckedAt: start.UTC(), Method: http.MethodHead}
+
+	status, err := c.do(ctx, http.MethodHead, target)
+	if (err != nil || !okStatus(status)) && !errors.Is(err, security.ErrBlockedAddress) {
+		check.Method = http.MethodGet
+		status, err = c.do(ctx, http.MethodGet, target)
+	}
+
+	check.LatencyMs = time.Since(start).Milliseconds()
+	check.StatusCode = status
+	switch {
+	case err != nil:
+		check.Error = errorCode(err)
+	case !okStatus(status):
+		check.Error = "bad_status"
+	default:
+		check.OK = true
+	}
+	return check
+}
+
+func (c *Checker) do(ctx context.Context, method, target string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Bağlantı tekrar kullanılabilsin diye gövdenin bir kısmı okunur, tamamı indirilmez.
+	_, _ = io.CopyN(io.Discard, resp.Body, 4096)
+	return resp.StatusCode, nil
+}
+
+// okStatus giriş isteyen veya bizi hız sınırına takan hedefler bozuk sayılmaz.
+func okStatus(status int) bool {
+	return status > 0 && status < 400 ||
+		status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusTooManyRequests
+}
+
+// errorCode iç ağ adresi gibi detayları kaydetmemek için hatayı sabit bir koda indirger.
+func errorCode(err error) string {
+	var ne net.Error
+	switch {
+	case errors.Is(err, security.ErrBlockedAddress), errors.Is(err, security.ErrInvalidUrl), errors.Is(err, security.ErrUnsupportedScheme):
+		return "blocked"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &ne) && ne.Timeout():
+		return "timeout"
+	default:
+		return "connection_failed"
+	}
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	mailer "github.com/emrealsandev/Url-Shortener/internal/mail"
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+const MAIL_TIMEOUT = 30 * time.Second
+
+// Notifier link durumu değiştiğinde sahibini bilgilendirir.
+type Notifier interface {
+	Notify(ctx context.Context, u repo.URL, status string, check repo.HealthCheck)
+}
+
+// LogNotifier bildirimi sadece loglar; sahibi olmayan (anonim) linkler ve e-postası bulunamayan sahipler için.
+type LogNotifier struct {
+	Logger logger.Logger
+}
+
+func (n LogNotifier) Notify(_ context.Context, u repo.URL, status string, check repo.HealthCheck) {
+	fields := []any{"code", u.Code, "status", status, "status_code", check.StatusCode, "error", check.Error}
+	if u.OwnerID != nil {
+		fields = append(fields, "owner_id", *u.OwnerID)
+	}
+	if status == repo.HEALTH_DEGRADED {
+		n.Logger.Warn("link degraded", fields...)
+		return
+	}
+	n.Logger.Info("link recovered", fields...)
+}
+
+// MailNotifier durumu loglar ve linkin sahibine e-posta atar. Sahip bir kullanıcıysa ona, workspace ise
+// owner ve admin üyelerine gider; sadece API anahtarı olan sahiplerin e-postası yoktur, onlar için log yeter.
+type MailNotifier struct {
+	Users      repo.UserRepository
+	Workspaces repo.WorkspaceRepository
+	Mail       mailer.Sender
+	BaseURL    string
+	Logger     logger.Logger
+}
+
+func (n MailNotifier) Notify(ctx context.Context, u repo.URL, status string, check repo.HealthCheck) {
+	LogNotifier{Logger: n.Logger}.Notify(ctx, u, status, check)
+	if u.OwnerID == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, MAIL_TIMEOUT)
+	defer cancel()
+	recipients, err := n.recipients(ctx, *u.OwnerID)
+	if err != nil {
+		n.Logger.Error("health: recipient lookup failed", "owner_id", *u.OwnerID, "error", err)
+		return
+	}
+
+	msg := n.message(u, status, check)
+	for _, to := range recipients {
+		msg.To = to
+		if err := n.Mail.Send(ctx, msg); err != nil {
+			n.Logger.Error("health: notification mail failed", "code", u.Code, "owner_id", *u.OwnerID, "error", err)
+		}
+	}
+}
+
+func (n MailNotifier) recipients(ctx context.Context, ownerID int64) ([]string, error) {
+	user, err := n.Users.GetByID(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return []string{user.Email}, nil
+	}
+	if n.Workspaces == nil {
+		return nil, nil
+	}
+
+	members, err := n.Workspaces.ListMembers(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, m := range members {
+		if policy.Role(m.Role).Can(policy.MEMBERS_MANAGE) {
+			out = append(out, m.Email)
+		}
+	}
+	return out, nil
+}
+
+func (n MailNotifier) message(u repo.URL, status string, check repo.HealthCheck) mailer.Message {
+	link := strings.TrimRight(n.BaseURL, "/") + "/" + url.PathEscape(u.Code)
+	if status == repo.HEALTH_DEGRADED {
+		reason := check.Error
+		if check.StatusCode != 0 {
+			reason = fmt.Sprintf("HTTP %d", check.StatusCode)
+		}
+		return mailer.Message{
+			Subject: "Linkinin hedefi yanıt vermiyor: " + link,
+			Body: link + " linkinin hedefi (" + u.Target + ") art arda kontrollerde başarısız oldu (" + reason + ").\n\n" +
+				"Yedek hedef tanımlıysa ziyaretçiler şu an oraya yönlendiriliyor. Ayrıntılar: " +
+				strings.TrimRight(n.BaseURL, "/") + "/v1/links/" + url.PathEscape(u.Code) + "/health\n",
+		}
+	}
+	return mailer.Message{
+		Subject: "Linkinin hedefi tekrar çalışıyor: " + link,
+		Body:    link + " linkinin hedefi (" + u.Target + ") tekrar yanıt veriyor; ziyaretçiler ana hedefe yönlendiriliyor.\n",
+	}
+}
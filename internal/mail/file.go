@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+)
+
+// FileSender yerel geliştirme için: mesajları Dir'e .eml olarak yazar ve loglar. Dir boşsa sadece loglar.
+// Log, gövdeyi (ör. şifre sıfırlama linki) de içerir; production'da kullanılmamalı.
+type FileSender struct {
+	Dir    string
+	From   string
+	Logger logger.Logger
+}
+
+func (s FileSender) Send(ctx context.Context, msg Message) error {
+	if !validHeader(msg.To) || !validHeader(msg.Subject) {
+		return ErrInvalidMessage
+	}
+
+	path := ""
+	if s.Dir != "" {
+		if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+			return err
+		}
+		path = filepath.Join(s.Dir, fmt.Sprintf("%d.eml", time.Now().UnixNano()))
+		if err := os.WriteFile(path, format(s.From, msg), 0o600); err != nil {
+			return err
+		}
+	}
+
+	s.Logger.Info("mail", "to", msg.To, "subject", msg.Subject, "file", path, "body", msg.Body)
+	return nil
+}
@@ -0,0 +1,75 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+)
+
+const (
+	FETCH_TIMEOUT       = 5 * time.Second
+	FETCH_MAX_BYTES     = 512 << 10
+	FETCH_MAX_REDIRECTS = 3
+	FETCH_USER_AGENT    = "UrlShortenerBot/1.0 (+link preview)"
+)
+
+var (
+	ErrNotHTML   = errors.New("not_html")
+	ErrBadStatus = errors.New("bad_status")
+)
+
+// Fetcher hedef sayfayı boyut ve süre sınırlı, SSRF korumalı bir client ile indirip ayrıştırır.
+type Fetcher struct {
+	Client   *http.Client
+	MaxBytes int64
+}
+
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		Client: security.NewSafeClient(security.SafeClientOptions{
+			Timeout:      FETCH_TIMEOUT,
+			MaxRedirects: FETCH_MAX_REDIRECTS,
+			UserAgent:    FETCH_USER_AGENT,
+		}),
+		MaxBytes: FETCH_MAX_BYTES,
+	}
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, target string) (repo.Metadata, error) {
+	target, err := security.NormalizeUrl(target)
+	if err != nil {
+		return repo.Metadata{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return repo.Metadata{}, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return repo.Metadata{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return repo.Metadata{}, fmt.Errorf("%w: %d", ErrBadStatus, resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return repo.Metadata{}, ErrNotHTML
+	}
+
+	// Yönlendirme sonrası son adres göreli linklerin tabanıdır.
+	md := Parse(io.LimitReader(resp.Body, f.MaxBytes), resp.Request.URL)
+	md.FetchedAt = time.Now().UTC()
+	return md, nil
+}
@@ -0,0 +1,203 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+)
+
+// TEST_HOST public görünen bir ad; testFetcher her bağlantıyı httptest sunucusuna yönlendirir.
+const TEST_HOST = "http://site.test"
+
+// testFetcher SafeClient'ın yönlendirme kurallarını korur, sadece bağlantıyı httptest sunucusuna açar.
+// Böylece 127.0.0.1 üzerindeki sunucu, iç ağ engeline takılmadan public bir site gibi test edilir.
+func testFetcher(t *testing.T, h http.Handler) *Fetcher {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	client := security.NewSafeClient(security.SafeClientOptions{
+		Timeout:      FETCH_TIMEOUT,
+		MaxRedirects: FETCH_MAX_REDIRECTS,
+		UserAgent:    FETCH_USER_AGENT,
+	})
+	client.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}
+	return &Fetcher{Client: client, MaxBytes: FETCH_MAX_BYTES}
+}
+
+func htmlHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(body))
+	}
+}
+
+func TestFetch(t *testing.T) {
+	f := testFetcher(t, htmlHandler(`<html><head><title>Başlık</title><meta property="og:image" content="/a.png"></head></html>`))
+
+	md, err := f.Fetch(context.Background(), TEST_HOST+"/page")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if md.Title != "Başlık" || md.OGImage != TEST_HOST+"/a.png" {
+		t.Errorf("title = %q, og image = %q", md.Title, md.OGImage)
+	}
+	if md.FetchedAt.IsZero() {
+		t.Error("FetchedAt not set")
+	}
+}
+
+func TestFetchFollowsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/moved", http.StatusMovedPermanently))
+	mux.Handle("/moved", http.RedirectHandler("/docs/page", http.StatusFound))
+	mux.Handle("/docs/page", htmlHandler(`<head><title>Yeni</title><link rel="icon" href="icon.png"></head>`))
+	f := testFetcher(t, mux)
+
+	md, err := f.Fetch(context.Background(), TEST_HOST+"/old")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if md.Title != "Yeni" {
+		t.Errorf("title = %q", md.Title)
+	}
+	// Göreli adresler yönlendirme sonrası son adrese göre çözülür.
+	if md.Favicon != TEST_HOST+"/docs/icon.png" {
+		t.Errorf("favicon = %q", md.Favicon)
+	}
+}
+
+func TestFetchRedirectLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	f := testFetcher(t, mux)
+
+	_, err := f.Fetch(context.Background(), TEST_HOST+"/loop")
+	if !errors.Is(err, ErrBadStatus) {
+		t.Fatalf("err = %v, want %v", err, ErrBadStatus)
+	}
+}
+
+func TestFetchRedirectToPrivateAddress(t *testing.T) {
+	for _, target := range []string{"http://127.0.0.1/admin", "http://10.0.0.1/", "http://169.254.169.254/latest/meta-data/", "file:///etc/passwd"} {
+		t.Run(target, func(t *testing.T) {
+			f := testFetcher(t, http.RedirectHandler(target, http.StatusFound))
+
+			_, err := f.Fetch(context.Background(), TEST_HOST+"/")
+			if err == nil {
+				t.Fatal("redirect to a private address was followed")
+			}
+			if code := errorCode(err); code != "blocked" {
+				t.Errorf("errorCode = %q, want blocked (err: %v)", code, err)
+			}
+		})
+	}
+}
+
+func TestFetchBlocksPrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(htmlHandler(`<head><title>iç ağ</title></head>`))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	f := NewFetcher()
+
+	tests := []struct {
+		name   string
+		target string
+		want   error
+	}{
+		// IP adresi doğrudan verilirse URL normalize edilirken reddedilir.
+		{"literal ip", srv.URL, security.ErrInvalidUrl},
+		// Ad ile verilirse DNS çözümlemesinden sonra bağlanılan IP kontrol edilir.
+		{"resolved name", "http://localhost:" + port + "/", security.ErrBlockedAddress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.Fetch(context.Background(), tt.target)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if code := errorCode(err); code != "blocked" {
+				t.Errorf("errorCode = %q, want blocked", code)
+			}
+		})
+	}
+}
+
+func TestFetchOversizedBody(t *testing.T) {
+	padding := strings.Repeat("<!-- dolgu -->", 200)
+	f := testFetcher(t, htmlHandler(`<head><title>Önce</title>`+padding+`<meta name="description" content="sonra"></head>`))
+	f.MaxBytes = 1024
+
+	md, err := f.Fetch(context.Background(), TEST_HOST+"/")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if md.Title != "Önce" {
+		t.Errorf("title = %q", md.Title)
+	}
+	if md.Description != "" {
+		t.Errorf("description past MaxBytes was read: %q", md.Description)
+	}
+}
+
+func TestFetchRejectsResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    error
+		code    string
+	}{
+		{
+			name: "json",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"title":"x"}`))
+			},
+			want: ErrNotHTML,
+			code: "not_html",
+		},
+		{
+			name: "image",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				w.Write([]byte{0x89, 'P', 'N', 'G'})
+			},
+			want: ErrNotHTML,
+			code: "not_html",
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "boom", http.StatusInternalServerError)
+			},
+			want: ErrBadStatus,
+			code: "bad_status",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := testFetcher(t, tt.handler)
+
+			_, err := f.Fetch(context.Background(), TEST_HOST+"/")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if code := errorCode(err); code != tt.code {
+				t.Errorf("errorCode = %q, want %q", code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package metadata
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	MAX_TITLE_LEN       = 300
+	MAX_DESCRIPTION_LEN = 1000
+	MAX_URL_LEN         = 2048
+)
+
+// Parse sadece <head> içini okur; <body> başlayınca durur. Göreli favicon ve og:image adresleri base'e göre çözülür.
+func Parse(r io.Reader, base *url.URL) repo.Metadata {
+	var md repo.Metadata
+	z := html.NewTokenizer(r)
+	inTitle := false
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return finish(md, base)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "body":
+				return finish(md, base)
+			case "title":
+				inTitle = md.Title == ""
+			case "meta":
+				if hasAttr {
+					applyMeta(&md, attrs(z))
+				}
+			case "link":
+				if hasAttr {
+					applyLink(&md, attrs(z))
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				md.Title += string(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return finish(md, base)
+			}
+		}
+	}
+}
+
+func attrs(z *html.Tokenizer) map[string]string {
+	out := map[string]string{}
+	for {
+		key, val, more := z.TagAttr()
+		out[strings.ToLower(string(key))] = string(val)
+		if !more {
+			return out
+		}
+	}
+}
+
+func applyMeta(md *repo.Metadata, a map[string]string) {
+	content := a["content"]
+	// OpenGraph "property", bazı siteler yanlışlıkla "name" kullanıyor.
+	key := strings.ToLower(a["property"])
+	if key == "" {
+		key = strings.ToLower(a["name"])
+	}
+
+	switch key {
+	case "description":
+		md.Description = content
+	case "og:title":
+		md.OGTitle = content
+	case "og:description":
+		md.OGDescription = content
+	case "og:image", "og:image:url", "og:image:secure_url":
+		if md.OGImage == "" {
+			md.OGImage = content
+		}
+	case "og:site_name":
+		md.OGSiteName = content
+	case "og:type":
+		md.OGType = content
+	}
+}
+
+func applyLink(md *repo.Metadata, a map[string]string) {
+	if md.Favicon != "" || a["href"] == "" {
+		return
+	}
+	for _, rel := range strings.Fields(strings.ToLower(a["rel"])) {
+		if rel == "icon" || rel == "apple-touch-icon" {
+			md.Favicon = a["href"]
+			return
+		}
+	}
+}
+
+func finish(md repo.Metadata, base *url.URL) repo.Metadata {
+	md.Title = clean(md.Title, MAX_TITLE_LEN)
+	md.Description = clean(md.Description, MAX_DESCRIPTION_LEN)
+	md.OGTitle = clean(md.OGTitle, MAX_TITLE_LEN)
+	md.OGDescription = clean(md.OGDescription, MAX_DESCRIPTION_LEN)
+	md.OGSiteName = clean(md.OGSiteName, MAX_TITLE_LEN)
+	md.OGType = clean(md.OGType, 50)
+
+	if md.Favicon == "" {
+		md.Favicon = "/favicon.ico"
+	}
+	md.Favicon = absolute(base, md.Favicon)
+	md.OGImage = absolute(base, md.OGImage)
+	return md
+}
+
+// clean boşlukları sadeleştirir ve rune sınırında keser.
+func clean(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// absolute sadece http(s) adresleri kabul eder; javascript:, data: gibi değerler atılır.
+func absolute(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || len(ref) > MAX_URL_LEN {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+func TestParse(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/post")
+
+	tests := []struct {
+		name  string
+		html  string
+		check func(t *testing.T, md repo.Metadata)
+	}{
+		{
+			name: "head fields",
+			html: `<html><head>
+				<title>  Merhaba
+				dünya </title>
+				<meta name="description" content="Açıklama">
+				<meta property="og:title" content="OG başlık">
+				<meta property="og:site_name" content="Site">
+				<meta property="og:image" content="/img/a.png">
+				<meta property="og:image" content="/img/b.png">
+				<link rel="shortcut icon" href="favicon.png">
+			</head><body></body></html>`,
+			check: func(t *testing.T, md repo.Metadata) {
+				if md.Title != "Merhaba dünya" {
+					t.Errorf("title = %q", md.Title)
+				}
+				if md.Description != "Açıklama" || md.OGTitle != "OG başlık" || md.OGSiteName != "Site" {
+					t.Errorf("description/og = %q %q %q", md.Description, md.OGTitle, md.OGSiteName)
+				}
+				if md.OGImage != "https://example.com/img/a.png" {
+					t.Errorf("og image = %q, want the first one resolved against base", md.OGImage)
+				}
+				if md.Favicon != "https://example.com/blog/favicon.png" {
+					t.Errorf("favicon = %q", md.Favicon)
+				}
+			},
+		},
+		{
+			name: "og in name attribute",
+			html: `<head><meta name="og:title" content="Yanlış attribute"></head>`,
+			check: func(t *testing.T, md repo.Metadata) {
+				if md.OGTitle != "Yanlış attribute" {
+					t.Errorf("og title = %q", md.OGTitle)
+				}
+			},
+		},
+		{
+			name: "stops at body",
+			html: `<head><title>Başlık</title></head><body><meta name="description" content="gövde"><title>ikinci</title></body>`,
+			check: func(t *testing.T, md repo.Metadata) {
+				if md.Title != "Başlık" || md.Description != "" {
+					t.Errorf("title = %q, description = %q", md.Title, md.Description)
+				}
+			},
+		},
+		{
+			name: "default favicon",
+			html: `<head><title>x</title></head>`,
+			check: func(t *testing.T, md repo.Metadata) {
+				if md.Favicon != "https://example.com/favicon.ico" {
+					t.Errorf("favicon = %q", md.Favicon)
+				}
+			},
+		},
+		{
+			name: "unsafe schemes dropped",
+			html: `<head><link rel="icon" href="javascript:alert(1)"><meta property="og:image" content="data:image/png;base64,AAAA"></head>`,
+			check: func(t *testing.T, md repo.Metadata) {
+				if md.Favicon != "" || md.OGImage != "" {
+					t.Errorf("favicon = %q, og image = %q", md.Favicon, md.OGImage)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := Parse(strings.NewReader(tt.html), base)
+			tt.check(t, md)
+		})
+	}
+}
+
+func TestParseTruncatesOnRuneBoundary(t *testing.T) {
+	long := strings.Repeat("ğ", MAX_TITLE_LEN)
+	md := Parse(strings.NewReader("<head><title>"+long+"</title></head>"), nil)
+
+	if len(md.Title) > MAX_TITLE_LEN {
+		t.Fatalf("title is %d bytes, max %d", len(md.Title), MAX_TITLE_LEN)
+	}
+	if !utf8.ValidString(md.Title) {
+		t.Fatalf("title is not valid UTF-8: %q", md.Title)
+	}
+}
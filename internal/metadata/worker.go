@@ -0,0 +1,96 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+)
+
+const (
+	WORKER_COUNT = 2
+	QUEUE_SIZE   = 256
+)
+
+type job struct {
+	code   string
+	target string
+}
+
+// Worker yeni linklerin metadata'sını arka planda çeker ve link dokümanına yazar.
+// Kuyruk doluysa iş düşürülür; link API'den elle yenilenebilir.
+type Worker struct {
+	fetcher *Fetcher
+	repo    repo.Repository
+	logger  logger.Logger
+	queue   chan job
+}
+
+func NewWorker(f *Fetcher, r repo.Repository, logger logger.Logger) *Worker {
+	return &Worker{fetcher: f, repo: r, logger: logger, queue: make(chan job, QUEUE_SIZE)}
+}
+
+// Start ctx iptal edilene kadar işçileri çalıştırır.
+func (w *Worker) Start(ctx context.Context) {
+	for i := 0; i < WORKER_COUNT; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-w.queue:
+					if _, err := w.Refresh(ctx, j.code, j.target); err != nil {
+						w.logger.Warn("metadata fetch failed", "code", j.code, "error", err)
+					}
+				}
+			}
+		}()
+	}
+}
+
+func (w *Worker) Enqueue(code, target string) {
+	select {
+	case w.queue <- job{code: code, target: target}:
+	default:
+		w.logger.Warn("metadata queue full, dropping", "code", code)
+	}
+}
+
+// Refresh metadata'yı hemen çeker ve kaydeder. Çekim hatası da Error alanıyla kaydedilir,
+// dönen hata sadece kayıt sırasında oluşanı ya da çekim hatasını bildirir.
+func (w *Worker) Refresh(ctx context.Context, code, target string) (*repo.Metadata, error) {
+	md, fetchErr := w.fetcher.Fetch(ctx, target)
+	if fetchErr != nil {
+		md = repo.Metadata{FetchedAt: time.Now().UTC(), Error: errorCode(fetchErr)}
+	}
+
+	if err := w.repo.SetMetadata(ctx, code, md); err != nil {
+		return nil, err
+	}
+	return &md, fetchErr
+}
+
+// errorCode iç ağ adresi gibi detayları kaydetmemek için hatayı sabit bir koda indirger.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, security.ErrBlockedAddress), errors.Is(err, security.ErrInvalidUrl), errors.Is(err, security.ErrUnsupportedScheme):
+		return "blocked"
+	case errors.Is(err, ErrNotHTML):
+		return "not_html"
+	case errors.Is(err, ErrBadStatus):
+		return "bad_status"
+	case isTimeout(err):
+		return "timeout"
+	default:
+		return "fetch_failed"
+	}
+}
+
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &ne) && ne.Timeout()
+}
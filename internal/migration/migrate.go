@@ -10,12 +10,43 @@ import (
 )
 
 const (
-	UrlsColl     = "urls"
-	SequenceColl = "sequence"
-	SettingsColl = "settings"
-	IdxCodeV1    = "uniq_code_v1"
-	IdxAliasV1   = "uniq_custom_alias_v1"
-	IdxExpireV1  = "ttl_expire_v1"
+	UrlsColl        = "urls"
+	SequenceColl    = "sequence"
+	SettingsColl    = "settings"
+	ReportsColl     = "reports"
+	ClicksColl      = "clicks"
+	OwnersColl      = "owners"
+	UsageColl       = "usage"
+	UsersColl       = "users"
+	WorkspacesColl  = "workspaces"
+	MembershipsColl = "memberships"
+	InvitationsColl = "invitations"
+	TokensColl      = "access_tokens"
+	IdxCodeV1       = "uniq_code_v1"
+	IdxAliasV1      = "uniq_custom_alias_v1"
+	IdxExpireV1     = "ttl_expire_v1"
+
+	IdxReportCodeStatusV1 = "report_code_status_v1"
+	IdxReportStatusV1     = "report_status_created_v1"
+
+	IdxClickCodeVariantV1 = "click_code_variant_v1"
+	IdxClickCodeCreatedV1 = "click_code_created_v1"
+
+	IdxOwnerAPIKeyV1     = "uniq_owner_api_key_v1"
+	IdxUsagePeriodV1     = "usage_period_owner_v1"
+	IdxUrlOwnerAliasV1   = "url_owner_alias_v1"
+	IdxUrlOwnerCreatedV1 = "url_owner_created_v1"
+	IdxUserEmailV1       = "uniq_user_email_v1"
+	IdxUserIdentityV1    = "uniq_user_identity_v1"
+
+	IdxMemberUserV1       = "member_user_v1"
+	IdxMemberWorkspaceV1  = "member_workspace_role_v1"
+	IdxInvitationTokenV1  = "uniq_invitation_token_v1"
+	IdxInvitationWsV1     = "invitation_workspace_v1"
+	IdxInvitationExpireV1 = "ttl_invitation_expire_v1"
+	IdxTokenHashV1        = "uniq_access_token_hash_v1"
+	IdxTokenUserV1        = "access_token_user_v1"
+	IdxTokenPurgeV1       = "ttl_access_token_purge_v1"
 )
 
 type Migrator struct {
@@ -36,7 +67,7 @@ func (m *Migrator) RunAll(ctx context.Context) error {
 	if err := m.ensureValidator(ctx, urlCollection); err != nil {
 		return fmt.Errorf("ensure validator: %w", err)
 	}
-	if err := m.ensureIndexes(ctx, urlCollection); err != nil {
+	if err := m.ensureIndexes(ctx, urlCollection, urlIndexes()); err != nil {
 		return fmt.Errorf("ensure indexes: %w", err)
 	}
 
@@ -54,9 +85,91 @@ func (m *Migrator) RunAll(ctx context.Context) error {
 		return fmt.Errorf("ensure collection settings: %w", err)
 	}
 
+	if err := m.ensureCollection(ctx, ReportsColl); err != nil {
+		return fmt.Errorf("ensure collection reports: %w", err)
+	}
+	if err := m.ensureIndexes(ctx, m.DB.Collection(ReportsColl), reportIndexes()); err != nil {
+		return fmt.Errorf("ensure report indexes: %w", err)
+	}
+
+	if err := m.ensureCollection(ctx, ClicksColl); err != nil {
+		return fmt.Errorf("ensure collection clicks: %w", err)
+	}
+	if err := m.ensureIndexes(ctx, m.DB.Collection(ClicksColl), clickIndexes()); err != nil {
+		return fmt.Errorf("ensure click indexes: %w", err)
+	}
+
+	if err := m.ensureCollection(ctx, OwnersColl); err != nil {
+		return fmt.Errorf("ensure collection owners: %w", err)
+	}
+	if err := m.ensureIndexes(ctx, m.DB.Collection(OwnersColl), ownerIndexes()); err != nil {
+		return fmt.Errorf("ensure owner indexes: %w", err)
+	}
+
+	if err := m.ensureCollection(ctx, UsageColl); err != nil {
+		return fmt.Errorf("ensure collection usage: %w", err)
+	}
+	if err := m.ensureIndexes(ctx, m.DB.Collection(UsageColl), usageIndexes()); err != nil {
+		return fmt.Errorf("ensure usage indexes: %w", err)
+	}
+
+	if err := m.ensureCollection(ctx, UsersColl); err != nil {
+		return fmt.Errorf("ensure collection users: %w", err)
+	}
+	if err := m.ensureIndexes(ctx, m.DB.Collection(UsersColl), userIndexes()); err != nil {
+		return fmt.Errorf("ensure user indexes: %w", err)
+	}
+
+	if err := m.ensureCollection(ctx, WorkspacesColl); err != nil {
+		return fmt.Errorf("ensure collection workspaces: %w", err)
+	}
+	if err := m.ensureCollection(ctx, MembershipsColl); err != nil {
+		return fmt.Errorf("ensure collection memberships: %w", err)
+	}
+	if err := m.ensureIndexes(ctx, m.DB.Collection(MembershipsColl), membershipIndexes()); err != nil {
+		return fmt.Errorf("ensure membership indexes: %w", err)
+	}
+	if err := m.ensureCollection(ctx, InvitationsColl); err != nil {
+		return fmt.Errorf("ensure collection invitations: %w", err)
+	}
+	if err := m.ensureIndexes(ctx, m.DB.Collection(InvitationsColl), invitationIndexes()); err != nil {
+		return fmt.Errorf("ensure invitation indexes: %w", err)
+	}
+
+	if err := m.ensureCollection(ctx, TokensColl); err != nil {
+		return fmt.Errorf("ensure collection access_tokens: %w", err)
+	}
+	if err := m.ensureIndexes(ctx, m.DB.Collection(TokensColl), accessTokenIndexes()); err != nil {
+		return fmt.Errorf("ensure access token indexes: %w", err)
+	}
+
 	return nil
 }
 
+// PreviewSuffixCodes "+" içeren kodları döner. "/abc+" önizleme adresi olduğu için bu linkler
+// yönlendirmez; alias doğrulaması eklenmeden önce açılmış olabilirler, elle yeniden adlandırılmalı.
+func (m *Migrator) PreviewSuffixCodes(ctx context.Context) ([]string, error) {
+	cur, err := m.DB.Collection(UrlsColl).Find(ctx,
+		bson.M{"code": bson.M{"$regex": `\+`}},
+		options.Find().SetProjection(bson.M{"code": 1, "_id": 0}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var codes []string
+	for cur.Next(ctx) {
+		var doc struct {
+			Code string `bson:"code"`
+		}
+		if err := cur.Decode(&doc); err != nil {
+			return nil, err
+		}
+		codes = append(codes, doc.Code)
+	}
+	return codes, cur.Err()
+}
+
 func (m *Migrator) ensureCollection(ctx context.Context, name string) error {
 	names, err := m.DB.ListCollectionNames(ctx, bson.M{"name": name})
 	if err != nil {
@@ -76,13 +189,34 @@ func (m *Migrator) ensureValidator(ctx context.Context, coll *mongo.Collection)
 			"required":             bson.A{"code", "target", "created_at", "disabled"},
 			"additionalProperties": false,
 			"properties": bson.M{
-				"_id":          bson.M{"bsonType": "objectId"},
-				"code":         bson.M{"bsonType": "string"},
-				"target":       bson.M{"bsonType": "string"},
-				"created_at":   bson.M{"bsonType": "date"},
-				"disabled":     bson.M{"bsonType": "bool"},
-				"expires_at":   bson.M{"bsonType": bson.A{"date", "null"}},
-				"custom_alias": bson.M{"bsonType": "string"},
+				"_id":              bson.M{"bsonType": "objectId"},
+				"code":             bson.M{"bsonType": "string"},
+				"target":           bson.M{"bsonType": "string"},
+				"created_at":       bson.M{"bsonType": "date"},
+				"disabled":         bson.M{"bsonType": "bool"},
+				"starts_at":        bson.M{"bsonType": bson.A{"date", "null"}},
+				"expires_at":       bson.M{"bsonType": bson.A{"date", "null"}},
+				"custom_alias":     bson.M{"bsonType": "string"},
+				"owner_id":         bson.M{"bsonType": bson.A{"long", "int"}},
+				"interstitial":     bson.M{"bsonType": "bool"},
+				"flagged":          bson.M{"bsonType": "bool"},
+				"password_hash":    bson.M{"bsonType": "string"},
+				"max_clicks":       bson.M{"bsonType": bson.A{"long", "int"}},
+				"clicks":           bson.M{"bsonType": bson.A{"long", "int"}},
+				"has_options":      bson.M{"bsonType": "bool"},
+				"rules":            bson.M{"bsonType": "array"},
+				"destinations":     bson.M{"bsonType": "array"},
+				"sticky":           bson.M{"bsonType": "bool"},
+				"query_forward":    bson.M{"enum": bson.A{"merge", "override"}},
+				"path_forward":     bson.M{"bsonType": "bool"},
+				"template":         bson.M{"bsonType": "object"},
+				"utm":              bson.M{"bsonType": "object"},
+				"deep_link":        bson.M{"bsonType": "object"},
+				"redirect_type":    bson.M{"bsonType": bson.A{"int", "long"}},
+				"metadata":         bson.M{"bsonType": "object"},
+				"social_card":      bson.M{"bsonType": "object"},
+				"health":           bson.M{"bsonType": "object"},
+				"fallback_targets": bson.M{"bsonType": "array"},
 			},
 		}
 
@@ -110,8 +244,8 @@ func (m *Migrator) ensureValidator(ctx context.Context, coll *mongo.Collection)
 	return coll.Database().RunCommand(ctx, cmd).Err()
 }
 
-func (m *Migrator) ensureIndexes(ctx context.Context, coll *mongo.Collection) error {
-	indexes := []mongo.IndexModel{
+func urlIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
 		{
 			Keys:    bson.D{{Key: "code", Value: 1}},
 			Options: options.Index().SetName(IdxCodeV1).SetUnique(true),
@@ -127,8 +261,134 @@ func (m *Migrator) ensureIndexes(ctx context.Context, coll *mongo.Collection) er
 			Keys:    bson.D{{Key: "expires_at", Value: 1}},
 			Options: options.Index().SetName(IdxExpireV1).SetExpireAfterSeconds(0),
 		},
+		{
+			// Sahip başına alias kotası sayımı için.
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "custom_alias", Value: 1}},
+			Options: options.Index().SetName(IdxUrlOwnerAliasV1).SetSparse(true),
+		},
+		{
+			// Kullanıcının kendi linklerini listelemesi için.
+			Keys:    bson.D{{Key: "owner_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName(IdxUrlOwnerCreatedV1).SetSparse(true),
+		},
+	}
+}
+
+func reportIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}, {Key: "status", Value: 1}, {Key: "reporter_hash", Value: 1}},
+			Options: options.Index().SetName(IdxReportCodeStatusV1),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName(IdxReportStatusV1),
+		},
+	}
+}
+
+func clickIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}, {Key: "variant", Value: 1}},
+			Options: options.Index().SetName(IdxClickCodeVariantV1),
+		},
+		{
+			Keys:    bson.D{{Key: "code", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName(IdxClickCodeCreatedV1),
+		},
+	}
+}
+
+func ownerIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "api_key_hash", Value: 1}},
+			Options: options.Index().
+				SetName(IdxOwnerAPIKeyV1).
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"api_key_hash": bson.M{"$type": "string"}}),
+		},
+	}
+}
+
+func usageIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "period", Value: 1}, {Key: "owner_id", Value: 1}},
+			Options: options.Index().SetName(IdxUsagePeriodV1),
+		},
+	}
+}
+
+func userIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetName(IdxUserEmailV1).SetUnique(true),
+		},
+		{
+			// Bir SSO kimliği tek kullanıcıya bağlanabilir.
+			Keys: bson.D{{Key: "identities.issuer", Value: 1}, {Key: "identities.subject", Value: 1}},
+			Options: options.Index().
+				SetName(IdxUserIdentityV1).
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"identities.subject": bson.M{"$type": "string"}}),
+		},
+	}
+}
+
+func membershipIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			// Kullanıcının workspace listesi için.
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName(IdxMemberUserV1),
+		},
+		{
+			// Üye listesi ve son sahip kontrolü için.
+			Keys:    bson.D{{Key: "workspace_id", Value: 1}, {Key: "role", Value: 1}},
+			Options: options.Index().SetName(IdxMemberWorkspaceV1),
+		},
+	}
+}
+
+func invitationIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetName(IdxInvitationTokenV1).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "workspace_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName(IdxInvitationWsV1),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName(IdxInvitationExpireV1).SetExpireAfterSeconds(0),
+		},
 	}
+}
+
+func accessTokenIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetName(IdxTokenHashV1).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName(IdxTokenUserV1),
+		},
+		{
+			// Süresi dolan ve iptal edilen token'lar bir süre listede görünür, sonra silinir.
+			Keys:    bson.D{{Key: "purge_at", Value: 1}},
+			Options: options.Index().SetName(IdxTokenPurgeV1).SetExpireAfterSeconds(0),
+		},
+	}
+}
 
+func (m *Migrator) ensureIndexes(ctx context.Context, coll *mongo.Collection, indexes []mongo.IndexModel) error {
 	existing := map[string]struct{}{}
 	cur, err := coll.Indexes().List(ctx)
 	if err != nil {
@@ -0,0 +1,192 @@
+package moderation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/config"
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+)
+
+var (
+	ErrInvalidReason  = errors.New("invalid_reason")
+	ErrAlreadyPending = errors.New("already_reported")
+	ErrNotFound       = errors.New("not_found")
+	ErrResolved       = errors.New("already_resolved")
+	ErrSystem         = errors.New("system_error")
+)
+
+const MAX_DETAILS_LENGTH = 500
+
+var reasons = map[string]struct{}{
+	"phishing": {},
+	"malware":  {},
+	"spam":     {},
+	"abuse":    {},
+	"other":    {},
+}
+
+type Service struct {
+	reports repo.ReportRepository
+	urls    repo.Repository
+	short   *short.Service
+	logger  logger.Logger
+}
+
+func NewService(reports repo.ReportRepository, urls repo.Repository, shortSvc *short.Service, logger logger.Logger) *Service {
+	return &Service{reports: reports, urls: urls, short: shortSvc, logger: logger}
+}
+
+// Report herkese açık şikayet kaydı oluşturur. reporter ham IP'dir, sadece hash'i saklanır.
+func (s *Service) Report(ctx context.Context, code, reason, details, reporter string, settings repo.Settings) error {
+	reason = strings.ToLower(strings.TrimSpace(reason))
+	if _, ok := reasons[reason]; !ok {
+		return ErrInvalidReason
+	}
+
+	details = strings.TrimSpace(details)
+	if len(details) > MAX_DETAILS_LENGTH {
+		details = details[:MAX_DETAILS_LENGTH]
+	}
+
+	u, err := s.urls.GetByCode(code)
+	if err != nil {
+		return ErrSystem
+	}
+	if u == nil {
+		return ErrNotFound
+	}
+
+	reporterHash := hashReporter(reporter)
+	exists, err := s.reports.HasPending(ctx, code, reporterHash)
+	if err != nil {
+		return ErrSystem
+	}
+	if exists {
+		return ErrAlreadyPending
+	}
+
+	r := repo.Report{
+		Code:         code,
+		Reason:       reason,
+		Details:      details,
+		ReporterHash: reporterHash,
+		Status:       repo.REPORT_STATUS_PENDING,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.reports.Insert(ctx, r); err != nil {
+		return ErrSystem
+	}
+
+	if u.Disabled {
+		return nil
+	}
+
+	if !u.Flagged {
+		if err := s.short.SetFlagged(ctx, code, true); err != nil {
+			s.logger.Error("flag link failed", "code", code, "error", err)
+		}
+	}
+
+	if settings.ReportThreshold <= 0 {
+		return nil
+	}
+
+	count, err := s.reports.CountPendingReporters(ctx, code)
+	if err != nil {
+		s.logger.Error("count reporters failed", "code", code, "error", err)
+		return nil
+	}
+
+	// Şikayetler moderasyon kuyruğunda kalır, link sadece kapatılır.
+	if count >= int(settings.ReportThreshold) {
+		if err := s.short.Disable(ctx, code); err != nil {
+			s.logger.Error("auto disable failed", "code", code, "error", err)
+			return nil
+		}
+		s.logger.Warn("link auto-disabled by reports", "code", code, "reporters", count)
+	}
+
+	return nil
+}
+
+func (s *Service) List(ctx context.Context, status string, limit, offset int64) ([]repo.Report, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	reports, err := s.reports.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	return reports, nil
+}
+
+// Accept linki kapatır, cache'i temizler ve aynı koda ait bekleyen tüm şikayetleri kabul eder.
+func (s *Service) Accept(ctx context.Context, id string) error {
+	r, err := s.pending(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.short.Disable(ctx, r.Code); err != nil && !errors.Is(err, short.ErrNotFound) {
+		return ErrSystem
+	}
+
+	if err := s.reports.ResolvePendingByCode(ctx, r.Code, repo.REPORT_STATUS_ACCEPTED); err != nil {
+		return ErrSystem
+	}
+	return nil
+}
+
+// Dismiss şikayeti reddeder; linkte bekleyen şikayet kalmadıysa işaret de kaldırılır.
+func (s *Service) Dismiss(ctx context.Context, id string) error {
+	r, err := s.pending(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.reports.SetStatus(ctx, id, repo.REPORT_STATUS_DISMISSED); err != nil {
+		return ErrSystem
+	}
+
+	remaining, err := s.reports.CountPendingReporters(ctx, r.Code)
+	if err != nil {
+		s.logger.Error("count reporters failed", "code", r.Code, "error", err)
+		return nil
+	}
+	if remaining == 0 {
+		if err := s.short.SetFlagged(ctx, r.Code, false); err != nil && !errors.Is(err, short.ErrNotFound) {
+			s.logger.Error("unflag link failed", "code", r.Code, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) pending(ctx context.Context, id string) (*repo.Report, error) {
+	r, err := s.reports.GetByID(ctx, id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, ErrSystem
+	}
+	if r.Status != repo.REPORT_STATUS_PENDING {
+		return nil, ErrResolved
+	}
+	return r, nil
+}
+
+func hashReporter(reporter string) string {
+	sum := sha256.Sum256([]byte(config.Get().SequenceSalt + ":" + reporter))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,394 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+)
+
+var (
+	ErrInvalidState    = errors.New("invalid_state")
+	ErrNotAllowed      = errors.New("sso_not_allowed")
+	ErrEmailUnverified = errors.New("sso_email_unverified")
+	ErrProvider        = errors.New("sso_unavailable")
+)
+
+const (
+	STATE_TTL          = 10 * time.Minute
+	STATE_KEY_PREFIX   = "oidc:state:"
+	HTTP_TIMEOUT       = 10 * time.Second
+	MAX_RESPONSE_BYTES = 1 << 20
+	// METADATA_TTL discovery belgesi ve JWKS en geç bu sürede yenilenir.
+	METADATA_TTL = time.Hour
+
+	DEFAULT_GROUPS_CLAIM = "groups"
+)
+
+var DEFAULT_SCOPES = []string{"openid", "email", "profile"}
+
+type Config struct {
+	// Issuer discovery için kullanılır: Issuer + "/.well-known/openid-configuration".
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// AllowedDomains boş değilse sadece bu alan adlarındaki doğrulanmış e-postalar girebilir.
+	AllowedDomains []string
+	// AllowedGroups boş değilse kullanıcı GroupsClaim'deki gruplardan en az birinde olmalıdır.
+	AllowedGroups []string
+	GroupsClaim   string
+}
+
+// Identity IdP'nin doğruladığı kullanıcı; (Issuer, Subject) kalıcı kimliktir, e-posta değişebilir.
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+type providerMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported"`
+}
+
+// pendingLogin yönlendirme sırasında Redis'te state altında tutulur.
+type pendingLogin struct {
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+// Client authorization code + PKCE akışını yürüten relying party. Discovery ve JWKS ilk kullanımda çekilir,
+// böylece IdP geçici olarak erişilemezken uygulama yine de açılır.
+type Client struct {
+	cfg    Config
+	http   *http.Client
+	cache  cache.Cache
+	logger logger.Logger
+	now    func() time.Time
+
+	mu          sync.Mutex
+	meta        *providerMetadata
+	metaFetched time.Time
+	keys        []publicKey
+	keysFetched time.Time
+}
+
+// NewClient httpClient nil ise varsayılan zaman aşımlı bir istemci kullanılır.
+func NewClient(cfg Config, httpClient *http.Client, c cache.Cache, logger logger.Logger) (*Client, error) {
+	cfg.Issuer = strings.TrimRight(cfg.Issuer, "/")
+	if err := checkEndpoint(cfg.Issuer); err != nil {
+		return nil, fmt.Errorf("oidc issuer: %w", err)
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.New("oidc: client id is required")
+	}
+	if _, err := url.ParseRequestURI(cfg.RedirectURL); err != nil {
+		return nil, fmt.Errorf("oidc redirect url: %w", err)
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = DEFAULT_SCOPES
+	}
+	if !contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = DEFAULT_GROUPS_CLAIM
+	}
+	for i, d := range cfg.AllowedDomains {
+		cfg.AllowedDomains[i] = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: HTTP_TIMEOUT}
+	}
+	return &Client{cfg: cfg, http: httpClient, cache: c, logger: logger, now: time.Now}, nil
+}
+
+// Begin IdP'ye yönlendirilecek URL'yi ve tarayıcıya bağlanacak state'i döner.
+// PKCE verifier ve nonce sadece sunucuda kalır.
+func (c *Client) Begin(ctx context.Context) (string, string, error) {
+	meta, err := c.metadata(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	raw, _ := json.Marshal(pendingLogin{Verifier: verifier, Nonce: nonce})
+	if err := c.cache.SetString(ctx, stateKey(state), string(raw), STATE_TTL); err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientID},
+		"redirect_uri":          {c.cfg.RedirectURL},
+		"scope":                 {strings.Join(c.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + q.Encode(), state, nil
+}
+
+// Finish callback'teki code'u token'a çevirir, ID token'ı doğrular ve alan adı/grup kısıtlarını uygular.
+// State tek kullanımlıktır; aynı callback ikinci kez işlenmez.
+func (c *Client) Finish(ctx context.Context, state, code string) (*Identity, error) {
+	if state == "" || code == "" {
+		return nil, ErrInvalidState
+	}
+	key := stateKey(state)
+	raw, ok, err := c.cache.GetString(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidState
+	}
+	claimed, err := c.cache.Del(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrInvalidState
+	}
+	var pending pendingLogin
+	if err := json.Unmarshal([]byte(raw), &pending); err != nil {
+		return nil, ErrInvalidState
+	}
+
+	idToken, err := c.exchange(ctx, code, pending.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, all, err := c.verifyIDToken(ctx, idToken, pending.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	id := &Identity{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Groups:        stringList(all[c.cfg.GroupsClaim]),
+	}
+	if err := c.authorize(id); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// authorize hesaplar e-postayla eşleştiği için e-posta her durumda doğrulanmış olmalıdır.
+func (c *Client) authorize(id *Identity) error {
+	if id.Email == "" || !id.EmailVerified {
+		return ErrEmailUnverified
+	}
+	if len(c.cfg.AllowedDomains) > 0 {
+		at := strings.LastIndex(id.Email, "@")
+		if at < 0 || !contains(c.cfg.AllowedDomains, id.Email[at+1:]) {
+			return ErrNotAllowed
+		}
+	}
+	if len(c.cfg.AllowedGroups) > 0 {
+		member := false
+		for _, g := range id.Groups {
+			if contains(c.cfg.AllowedGroups, g) {
+				member = true
+				break
+			}
+		}
+		if !member {
+			return ErrNotAllowed
+		}
+	}
+	return nil
+}
+
+func (c *Client) exchange(ctx context.Context, code, verifier string) (string, error) {
+	meta, err := c.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if c.cfg.ClientSecret == "" {
+		// public client: secret yok, PKCE yeterli
+		form.Set("client_id", c.cfg.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		// client_secret_basic: RFC 6749 2.3.1 gereği önce form-urlencode edilir
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MAX_RESPONSE_BYTES)).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: token response: %v", ErrProvider, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// invalid_grant: code süresi dolmuş ya da daha önce kullanılmış
+		if body.Error == "invalid_grant" {
+			return "", ErrInvalidState
+		}
+		return "", fmt.Errorf("%w: token endpoint status %d %s", ErrProvider, resp.StatusCode, body.Error)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: missing id_token", ErrInvalidToken)
+	}
+	return body.IDToken, nil
+}
+
+// metadata discovery belgesini önbellekten ya da IdP'den döner.
+func (c *Client) metadata(ctx context.Context) (*providerMetadata, error) {
+	c.mu.Lock()
+	meta, fetched := c.meta, c.metaFetched
+	c.mu.Unlock()
+	if meta != nil && c.now().Sub(fetched) < METADATA_TTL {
+		return meta, nil
+	}
+
+	var m providerMetadata
+	if err := c.getJSON(ctx, c.cfg.Issuer+"/.well-known/openid-configuration", &m); err != nil {
+		if meta != nil {
+			// IdP geçici olarak erişilemiyor; eski belgeyle devam
+			c.logger.Error("oidc discovery refresh failed", "error", err)
+			return meta, nil
+		}
+		return nil, fmt.Errorf("%w: discovery: %v", ErrProvider, err)
+	}
+	if err := m.validate(c.cfg.Issuer); err != nil {
+		return nil, fmt.Errorf("%w: discovery: %v", ErrProvider, err)
+	}
+
+	c.mu.Lock()
+	c.meta, c.metaFetched = &m, c.now()
+	// anahtarlar da yeniden çekilsin; jwks_uri değişmiş olabilir
+	c.keys, c.keysFetched = nil, time.Time{}
+	c.mu.Unlock()
+	return &m, nil
+}
+
+func (m *providerMetadata) validate(issuer string) error {
+	// OIDC Discovery 4.3: belgedeki issuer yapılandırılanla birebir aynı olmalı.
+	if strings.TrimRight(m.Issuer, "/") != issuer {
+		return fmt.Errorf("issuer mismatch: %q", m.Issuer)
+	}
+	for _, u := range []string{m.AuthorizationEndpoint, m.TokenEndpoint, m.JWKSURI} {
+		if err := checkEndpoint(u); err != nil {
+			return err
+		}
+	}
+	if len(m.CodeChallengeMethods) > 0 && !contains(m.CodeChallengeMethods, "S256") {
+		return errors.New("provider does not support PKCE S256")
+	}
+	return nil
+}
+
+// checkEndpoint https zorunlu; sadece loopback adresler (yerel geliştirme ve sahte IdP) http olabilir.
+func checkEndpoint(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid url %q", raw)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return nil
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q must use https", raw)
+}
+
+// stringList grup claim'i dizi ya da tek string olabilir.
+func stringList(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(raw, &many); err == nil {
+		return many
+	}
+	var one string
+	if err := json.Unmarshal(raw, &one); err == nil && one != "" {
+		return []string{one}
+	}
+	return nil
+}
+
+func stateKey(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return STATE_KEY_PREFIX + hex.EncodeToString(sum[:])
+}
+
+func randomString() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,486 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+)
+
+const (
+	TEST_CLIENT_ID     = "shortener"
+	TEST_CLIENT_SECRET = "s3cret"
+	TEST_REDIRECT_URL  = "https://sho.rt/v1/auth/oidc/callback"
+	TEST_CODE          = "auth-code"
+)
+
+// memCache Client'ın kullandığı string ve Del metodlarını bellekte tutar; diğerleri kullanılmaz.
+type memCache struct {
+	cache.Cache
+	mu sync.Mutex
+	m  map[string]string
+}
+
+func (c *memCache) GetString(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.m[key]
+	return v, ok, nil
+}
+
+func (c *memCache) SetString(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[key] = value
+	return nil
+}
+
+func (c *memCache) Del(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.m[key]
+	delete(c.m, key)
+	return ok, nil
+}
+
+type signingKey struct {
+	kid string
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newRSAKey(t *testing.T, kid string) *signingKey {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &signingKey{kid: kid, rsa: k}
+}
+
+func newECKey(t *testing.T, kid string) *signingKey {
+	t.Helper()
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &signingKey{kid: kid, ec: k}
+}
+
+func (k *signingKey) alg() string {
+	if k.ec != nil {
+		return "ES256"
+	}
+	return "RS256"
+}
+
+func (k *signingKey) jwk() map[string]any {
+	enc := base64.RawURLEncoding.EncodeToString
+	if k.ec != nil {
+		return map[string]any{"kty": "EC", "kid": k.kid, "use": "sig", "alg": "ES256", "crv": "P-256",
+			"x": enc(k.ec.X.FillBytes(make([]byte, 32))), "y": enc(k.ec.Y.FillBytes(make([]byte, 32)))}
+	}
+	return map[string]any{"kty": "RSA", "kid": k.kid, "use": "sig", "alg": "RS256",
+		"n": enc(k.rsa.N.Bytes()), "e": enc(big.NewInt(int64(k.rsa.E)).Bytes())}
+}
+
+func (k *signingKey) sign(t *testing.T, input string) []byte {
+	t.Helper()
+	digest := sha256.Sum256([]byte(input))
+	if k.ec != nil {
+		r, s, err := ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	sig, err := rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+// testIdP discovery, JWKS ve token uç noktalarını sunan sahte bir IdP. Token uç noktası PKCE'yi ve client
+// kimlik bilgilerini kontrol eder; ID token'ı signer ile imzalar, claims ve alg ile bozulabilir.
+type testIdP struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu          sync.Mutex
+	published   []*signingKey
+	signer      *signingKey
+	discovery   map[string]any
+	claims      map[string]any
+	alg         string
+	tamper      bool
+	challenge   string
+	nonce       string
+	tokenForm   url.Values
+	discoveries int
+	jwksFetches int
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	p := &testIdP{t: t}
+	p.signer = newRSAKey(t, "k1")
+	p.published = []*signingKey{p.signer}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.serveDiscovery)
+	mux.HandleFunc("/jwks", p.serveJWKS)
+	mux.HandleFunc("/token", p.serveToken)
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+func (p *testIdP) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.discoveries++
+	doc := map[string]any{
+		"issuer":                           p.srv.URL,
+		"authorization_endpoint":           p.srv.URL + "/authorize",
+		"token_endpoint":                   p.srv.URL + "/token",
+		"jwks_uri":                         p.srv.URL + "/jwks",
+		"code_challenge_methods_supported": []string{"plain", "S256"},
+	}
+	for k, v := range p.discovery {
+		doc[k] = v
+	}
+	json.NewEncoder(w).Encode(doc)
+}
+
+func (p *testIdP) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jwksFetches++
+	keys := []map[string]any{
+		// imza dışı ve tanınmayan anahtarlar atlanmalı
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "AAAA"},
+	}
+	for _, k := range p.published {
+		keys = append(keys, k.jwk())
+	}
+	json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+}
+
+func (p *testIdP) serveToken(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r.ParseForm()
+	p.tokenForm = r.PostForm
+	w.Header().Set("Content-Type", "application/json")
+
+	id, secret, ok := r.BasicAuth()
+	if ok && (id != TEST_CLIENT_ID || secret != TEST_CLIENT_SECRET) || !ok && r.PostForm.Get("client_id") != TEST_CLIENT_ID {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client"}`))
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != TEST_CODE ||
+		r.PostForm.Get("redirect_uri") != TEST_REDIRECT_URL || base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "token_type": "Bearer", "id_token": p.idToken()})
+}
+
+// idToken varsayılan claim'leri p.claims ile ezer; nil değer claim'i siler.
+func (p *testIdP) idToken() string {
+	now := time.Now()
+	claims := map[string]any{
+		"iss":            p.srv.URL,
+		"sub":            "user-1",
+		"aud":            TEST_CLIENT_ID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          p.nonce,
+		"email":          "Ada@Corp.com",
+		"email_verified": true,
+		"name":           "Ada",
+		"groups":         []string{"eng"},
+	}
+	for k, v := range p.claims {
+		if v == nil {
+			delete(claims, k)
+			continue
+		}
+		claims[k] = v
+	}
+
+	alg := p.alg
+	if alg == "" {
+		alg = p.signer.alg()
+	}
+	header, _ := json.Marshal(map[string]any{"alg": alg, "kid": p.signer.kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var sig []byte
+	switch alg {
+	case "none":
+	case "HS256":
+		// alg confusion: public anahtar HMAC secret'ı olarak kullanılır
+		mac := hmac.New(sha256.New, p.signer.rsa.N.Bytes())
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	default:
+		sig = p.signer.sign(p.t, input)
+	}
+	if p.tamper && len(sig) > 0 {
+		sig[len(sig)-1] ^= 1
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// rotate yeni anahtarı yayınlar ve onunla imzalamaya başlar; eski anahtar JWKS'ten çıkarılır.
+func (p *testIdP) rotate(k *signingKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.signer = k
+	p.published = []*signingKey{k}
+}
+
+func (p *testIdP) set(f func(p *testIdP)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f(p)
+}
+
+func (p *testIdP) counts() (discoveries, jwksFetches int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.discoveries, p.jwksFetches
+}
+
+func newTestClient(t *testing.T, p *testIdP, mutate func(cfg *Config)) *Client {
+	t.Helper()
+	cfg := Config{
+		Issuer:       p.srv.URL + "/",
+		ClientID:     TEST_CLIENT_ID,
+		ClientSecret: TEST_CLIENT_SECRET,
+		RedirectURL:  TEST_REDIRECT_URL,
+	}
+	if mutate != nil {
+		mutate(&cfg)
+	}
+	c, err := NewClient(cfg, p.srv.Client(), &memCache{m: map[string]string{}}, logger.GetLogger())
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return c
+}
+
+// login tarayıcının yaptığını yapar: Begin'in URL'sini IdP'ye götürür ve callback'teki state ile code'u döner.
+func login(t *testing.T, p *testIdP, c *Client) (string, string) {
+	t.Helper()
+	authURL, state, err := c.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if got := u.Scheme + "://" + u.Host + u.Path; got != p.srv.URL+"/authorize" {
+		t.Fatalf("authorization endpoint = %q", got)
+	}
+	for k, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             TEST_CLIENT_ID,
+		"redirect_uri":          TEST_REDIRECT_URL,
+		"scope":                 "openid email profile",
+		"state":                 state,
+		"code_challenge_method": "S256",
+	} {
+		if q.Get(k) != want {
+			t.Fatalf("%s = %q, want %q", k, q.Get(k), want)
+		}
+	}
+	if q.Get("nonce") == "" || q.Get("code_challenge") == "" {
+		t.Fatal("nonce or code_challenge missing")
+	}
+	p.set(func(p *testIdP) {
+		p.challenge = q.Get("code_challenge")
+		p.nonce = q.Get("nonce")
+	})
+	return state, TEST_CODE
+}
+
+func TestNewClientRejectsInsecureIssuer(t *testing.T) {
+	for _, issuer := range []string{"http://idp.example.com", "ftp://idp.example.com", "idp.example.com"} {
+		_, err := NewClient(Config{Issuer: issuer, ClientID: TEST_CLIENT_ID, RedirectURL: TEST_REDIRECT_URL}, nil, &memCache{}, logger.GetLogger())
+		if err == nil {
+			t.Errorf("issuer %q accepted", issuer)
+		}
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	p := newTestIdP(t)
+	c := newTestClient(t, p, nil)
+
+	login(t, p, c)
+	login(t, p, c)
+	if discoveries, _ := p.counts(); discoveries != 1 {
+		t.Errorf("discovery fetched %d times, want it cached", discoveries)
+	}
+}
+
+func TestDiscoveryRejectsBadDocument(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  map[string]any
+	}{
+		{"issuer mismatch", map[string]any{"issuer": "https://evil.example.com"}},
+		{"no S256", map[string]any{"code_challenge_methods_supported": []string{"plain"}}},
+		{"http token endpoint", map[string]any{"token_endpoint": "http://idp.example.com/token"}},
+		{"http jwks", map[string]any{"jwks_uri": "http://idp.example.com/jwks"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestIdP(t)
+			p.set(func(p *testIdP) { p.discovery = tt.doc })
+			c := newTestClient(t, p, nil)
+
+			if _, _, err := c.Begin(context.Background()); !errors.Is(err, ErrProvider) {
+				t.Fatalf("err = %v, want %v", err, ErrProvider)
+			}
+		})
+	}
+}
+
+func TestFinish(t *testing.T) {
+	p := newTestIdP(t)
+	c := newTestClient(t, p, func(cfg *Config) {
+		cfg.AllowedDomains = []string{" @Corp.com"}
+		cfg.AllowedGroups = []string{"eng"}
+	})
+
+	state, code := login(t, p, c)
+	id, err := c.Finish(context.Background(), state, code)
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if id.Issuer != p.srv.URL || id.Subject != "user-1" || id.Email != "ada@corp.com" || !id.EmailVerified || id.Name != "Ada" {
+		t.Errorf("identity = %+v", id)
+	}
+	if len(id.Groups) != 1 || id.Groups[0] != "eng" {
+		t.Errorf("groups = %v", id.Groups)
+	}
+
+	// PKCE: IdP'ye giden verifier, yönlendirmedeki challenge'ın kaynağı olmalı (token uç noktası kontrol ediyor).
+	p.set(func(p *testIdP) {
+		if p.tokenForm.Get("code_verifier") == "" || p.tokenForm.Get("client_id") != "" {
+			t.Errorf("token form = %v", p.tokenForm)
+		}
+	})
+}
+
+func TestFinishPublicClient(t *testing.T) {
+	p := newTestIdP(t)
+	c := newTestClient(t, p, func(cfg *Config) { cfg.ClientSecret = "" })
+
+	state, code := login(t, p, c)
+	if _, err := c.Finish(context.Background(), state, code); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	p.set(func(p *testIdP) {
+		if p.tokenForm.Get("client_id") != TEST_CLIENT_ID {
+			t.Errorf("public client must send client_id, form = %v", p.tokenForm)
+		}
+	})
+}
+
+func TestFinishState(t *testing.T) {
+	p := newTestIdP(t)
+	c := newTestClient(t, p, nil)
+	ctx := context.Background()
+
+	if _, err := c.Finish(ctx, "unknown", TEST_CODE); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("unknown state: err = %v", err)
+	}
+
+	state, code := login(t, p, c)
+	if _, err := c.Finish(ctx, state, code); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if _, err := c.Finish(ctx, state, code); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("replayed state: err = %v, want %v", err, ErrInvalidState)
+	}
+}
+
+func TestFinishRejectsWrongVerifier(t *testing.T) {
+	p := newTestIdP(t)
+	c := newTestClient(t, p, nil)
+
+	state, code := login(t, p, c)
+	// başka bir girişin challenge'ı: IdP verifier'ı eşleştiremez ve invalid_grant döner
+	p.set(func(p *testIdP) { p.challenge = "another-challenge" })
+	if _, err := c.Finish(context.Background(), state, code); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidState)
+	}
+}
+
+func TestJWKSRotation(t *testing.T) {
+	p := newTestIdP(t)
+	c := newTestClient(t, p, nil)
+	clock := time.Now()
+	c.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	state, code := login(t, p, c)
+	if _, err := c.Finish(ctx, state, code); err != nil {
+		t.Fatalf("Finish with k1: %v", err)
+	}
+
+	p.rotate(newECKey(t, "k2"))
+
+	// Bilinmeyen kid JWKS'i en fazla JWKS_MIN_REFRESH'te bir yeniden çektirir.
+	state, code = login(t, p, c)
+	if _, err := c.Finish(ctx, state, code); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("unknown kid right after fetch: err = %v, want %v", err, ErrInvalidToken)
+	}
+	_, before := p.counts()
+
+	clock = clock.Add(JWKS_MIN_REFRESH + time.Second)
+	state, code = login(t, p, c)
+	if _, err := c.Finish(ctx, state, code); err != nil {
+		t.Fatalf("Finish with rotated k2: %v", err)
+	}
+	if _, after := p.counts(); after != before+1 {
+		t.Errorf("jwks fetched %d more times, want 1", after-before)
+	}
+
+	// Yeni anahtar cache'ten kullanılır.
+	state, code = login(t, p, c)
+	if _, err := c.Finish(ctx, state, code); err != nil {
+		t.Fatalf("Finish with cached k2: %v", err)
+	}
+	if _, after := p.counts(); after != before+1 {
+		t.Errorf("jwks refetched for a known key")
+	}
+}
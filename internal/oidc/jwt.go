@@ -0,0 +1,347 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid_id_token")
+
+const (
+	// CLOCK_SKEW IdP ile saat farkı toleransı.
+	CLOCK_SKEW = time.Minute
+	// JWKS_MIN_REFRESH bilinmeyen kid geldiğinde anahtarlar en fazla bu sıklıkla yeniden çekilir.
+	JWKS_MIN_REFRESH = time.Minute
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type publicKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// idClaims ID token'dan okunan alanlar; grup claim'i adı yapılandırılabildiği için ayrıca okunur.
+type idClaims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	AuthorizedBy  string   `json:"azp"`
+	Expiry        int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	NotBefore     int64    `json:"nbf"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+}
+
+// audience tek string veya string dizisi olabilir.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// flexBool bazı IdP'ler email_verified'ı "true" string'i olarak gönderir.
+type flexBool bool
+
+func (f *flexBool) UnmarshalJSON(b []byte) error {
+	switch strings.Trim(string(b), `"`) {
+	case "true":
+		*f = true
+	default:
+		*f = false
+	}
+	return nil
+}
+
+// verifyIDToken imzayı JWKS ile, ardından iss/aud/exp/nonce alanlarını doğrular.
+func (c *Client) verifyIDToken(ctx context.Context, raw, nonce string) (*idClaims, map[string]json.RawMessage, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	hash, ok := algHash(header.Alg)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	key, err := c.key(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(key, header.Alg, hash, h.Sum(nil), sig) {
+		return nil, nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims idClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	var all map[string]json.RawMessage
+	if err := decodeSegment(parts[1], &all); err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	meta, err := c.metadata(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := c.now()
+	switch {
+	case claims.Issuer != meta.Issuer:
+		return nil, nil, fmt.Errorf("%w: issuer", ErrInvalidToken)
+	case !contains(claims.Audience, c.cfg.ClientID):
+		return nil, nil, fmt.Errorf("%w: audience", ErrInvalidToken)
+	case len(claims.Audience) > 1 && claims.AuthorizedBy != c.cfg.ClientID:
+		return nil, nil, fmt.Errorf("%w: azp", ErrInvalidToken)
+	case claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(CLOCK_SKEW)):
+		return nil, nil, fmt.Errorf("%w: expired", ErrInvalidToken)
+	case claims.NotBefore != 0 && now.Add(CLOCK_SKEW).Before(time.Unix(claims.NotBefore, 0)):
+		return nil, nil, fmt.Errorf("%w: not yet valid", ErrInvalidToken)
+	case claims.IssuedAt != 0 && now.Add(CLOCK_SKEW).Before(time.Unix(claims.IssuedAt, 0)):
+		return nil, nil, fmt.Errorf("%w: issued in the future", ErrInvalidToken)
+	case claims.Nonce != nonce:
+		return nil, nil, fmt.Errorf("%w: nonce", ErrInvalidToken)
+	case claims.Subject == "":
+		return nil, nil, fmt.Errorf("%w: subject", ErrInvalidToken)
+	}
+	return &claims, all, nil
+}
+
+// key kid'e uyan anahtarı döner; bulunamazsa (IdP anahtar döndürmüş olabilir) JWKS yeniden çekilir.
+func (c *Client) key(ctx context.Context, kid, alg string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	k := findKey(c.keys, kid, alg)
+	age := c.now().Sub(c.keysFetched)
+	c.mu.Unlock()
+	// IdP'nin kaldırdığı anahtarlar da bir süre sonra düşsün diye liste METADATA_TTL'de bir yenilenir.
+	if k != nil && age < METADATA_TTL {
+		return k, nil
+	}
+	if k == nil && age < JWKS_MIN_REFRESH {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+
+	keys, err := c.fetchKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	c.mu.Lock()
+	c.keys, c.keysFetched = keys, c.now()
+	c.mu.Unlock()
+
+	if k := findKey(keys, kid, alg); k != nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+}
+
+func (c *Client) fetchKeys(ctx context.Context) ([]publicKey, error) {
+	meta, err := c.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := c.getJSON(ctx, meta.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	out := make([]publicKey, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// tanımadığımız anahtar tipleri atlanır
+			continue
+		}
+		out = append(out, publicKey{kid: k.Kid, alg: k.Alg, key: pub})
+	}
+	return out, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("bad rsa exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New("unsupported curve")
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("point not on curve")
+		}
+		return pub, nil
+	}
+	return nil, errors.New("unsupported key type")
+}
+
+func findKey(keys []publicKey, kid, alg string) crypto.PublicKey {
+	for _, k := range keys {
+		if (kid == "" || k.kid == kid) && (k.alg == "" || k.alg == alg) && keyMatchesAlg(k.key, alg) {
+			return k.key
+		}
+	}
+	return nil
+}
+
+func keyMatchesAlg(key crypto.PublicKey, alg string) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return strings.HasPrefix(alg, "ES")
+	}
+	return false
+}
+
+// algHash sadece asimetrik algoritmalar; "none" ve HS* kabul edilmez.
+func algHash(alg string) (crypto.Hash, bool) {
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		return crypto.SHA256, true
+	case "RS384", "PS384", "ES384":
+		return crypto.SHA384, true
+	case "RS512", "PS512", "ES512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+func verifySignature(key crypto.PublicKey, alg string, hash crypto.Hash, digest, sig []byte) bool {
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(alg, "PS") {
+			return rsa.VerifyPSS(pub, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest, sig) == nil
+	case *ecdsa.PublicKey:
+		// JWS'te ECDSA imzası sabit uzunlukta r||s'dir.
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(pub, digest, r, s)
+	}
+	return false
+}
+
+func decodeSegment(seg string, dst any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, MAX_RESPONSE_BYTES)).Decode(dst)
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
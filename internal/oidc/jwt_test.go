@@ -0,0 +1,96 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFinishValidatesIDToken(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		claims map[string]any
+		alg    string
+		tamper bool
+		want   error
+	}{
+		{name: "valid"},
+		{name: "bad issuer", claims: map[string]any{"iss": "https://evil.example.com"}, want: ErrInvalidToken},
+		{name: "bad audience", claims: map[string]any{"aud": "someone-else"}, want: ErrInvalidToken},
+		{name: "multiple audiences without azp", claims: map[string]any{"aud": []string{TEST_CLIENT_ID, "other"}}, want: ErrInvalidToken},
+		{name: "multiple audiences bad azp", claims: map[string]any{"aud": []string{TEST_CLIENT_ID, "other"}, "azp": "other"}, want: ErrInvalidToken},
+		{name: "multiple audiences with azp", claims: map[string]any{"aud": []string{TEST_CLIENT_ID, "other"}, "azp": TEST_CLIENT_ID}},
+		{name: "expired", claims: map[string]any{"exp": now.Add(-CLOCK_SKEW - time.Minute).Unix()}, want: ErrInvalidToken},
+		{name: "expired within skew", claims: map[string]any{"exp": now.Add(-CLOCK_SKEW / 2).Unix()}},
+		{name: "no exp", claims: map[string]any{"exp": nil}, want: ErrInvalidToken},
+		{name: "not yet valid", claims: map[string]any{"nbf": now.Add(CLOCK_SKEW + time.Minute).Unix()}, want: ErrInvalidToken},
+		{name: "issued in the future", claims: map[string]any{"iat": now.Add(CLOCK_SKEW + time.Minute).Unix()}, want: ErrInvalidToken},
+		{name: "nonce mismatch", claims: map[string]any{"nonce": "replayed"}, want: ErrInvalidToken},
+		{name: "no nonce", claims: map[string]any{"nonce": nil}, want: ErrInvalidToken},
+		{name: "no subject", claims: map[string]any{"sub": nil}, want: ErrInvalidToken},
+		{name: "alg none", alg: "none", want: ErrInvalidToken},
+		{name: "alg HS256", alg: "HS256", want: ErrInvalidToken},
+		{name: "alg mismatch with key", alg: "ES256", want: ErrInvalidToken},
+		{name: "bad signature", tamper: true, want: ErrInvalidToken},
+		{name: "unverified email", claims: map[string]any{"email_verified": false}, want: ErrEmailUnverified},
+		{name: "email_verified missing", claims: map[string]any{"email_verified": nil}, want: ErrEmailUnverified},
+		{name: "email_verified string", claims: map[string]any{"email_verified": "true"}},
+		{name: "no email", claims: map[string]any{"email": nil}, want: ErrEmailUnverified},
+		{name: "disallowed domain", claims: map[string]any{"email": "ada@corp.com.evil.example"}, want: ErrNotAllowed},
+		{name: "subdomain not allowed", claims: map[string]any{"email": "ada@eu.corp.com"}, want: ErrNotAllowed},
+		{name: "disallowed group", claims: map[string]any{"groups": []string{"sales"}}, want: ErrNotAllowed},
+		{name: "no groups", claims: map[string]any{"groups": nil}, want: ErrNotAllowed},
+		{name: "single group string", claims: map[string]any{"groups": "eng"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestIdP(t)
+			c := newTestClient(t, p, func(cfg *Config) {
+				cfg.AllowedDomains = []string{"corp.com"}
+				cfg.AllowedGroups = []string{"eng"}
+			})
+			state, code := login(t, p, c)
+			p.set(func(p *testIdP) {
+				p.claims, p.alg, p.tamper = tt.claims, tt.alg, tt.tamper
+			})
+
+			id, err := c.Finish(context.Background(), state, code)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Finish: %v", err)
+				}
+				if id.Subject != "user-1" {
+					t.Errorf("subject = %q", id.Subject)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if id != nil {
+				t.Errorf("identity returned with error: %+v", id)
+			}
+		})
+	}
+}
+
+func TestFinishCustomGroupsClaim(t *testing.T) {
+	p := newTestIdP(t)
+	c := newTestClient(t, p, func(cfg *Config) {
+		cfg.GroupsClaim = "roles"
+		cfg.AllowedGroups = []string{"admin"}
+	})
+	state, code := login(t, p, c)
+	p.set(func(p *testIdP) { p.claims = map[string]any{"roles": []string{"admin"}, "groups": nil} })
+
+	id, err := c.Finish(context.Background(), state, code)
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if len(id.Groups) != 1 || id.Groups[0] != "admin" {
+		t.Errorf("groups = %v", id.Groups)
+	}
+}
@@ -0,0 +1,220 @@
+package pat
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+var (
+	ErrInvalidName   = errors.New("invalid_name")
+	ErrInvalidScope  = errors.New("invalid_scope")
+	ErrInvalidExpiry = errors.New("invalid_expiry")
+	ErrTooManyTokens = errors.New("too_many_tokens")
+	ErrNotFound      = errors.New("not_found")
+	// ErrInvalidToken bilinmeyen, iptal edilmiş ya da sahibi silinmiş token.
+	ErrInvalidToken = errors.New("invalid_access_token")
+	ErrExpired      = errors.New("access_token_expired")
+	ErrSystem       = errors.New("system_error")
+)
+
+const (
+	// TOKEN_PREFIX token'ları API anahtarlarından ve admin token'ından ayırır; sızıntı taramalarında da yakalanır.
+	TOKEN_PREFIX      = "pat_"
+	DISPLAY_PREFIX    = 12 // "pat_" + 8 karakter listede gösterilir
+	MAX_NAME_LENGTH   = 100
+	DEFAULT_TTL_DAYS  = 30
+	MAX_TTL_DAYS      = 365
+	MAX_ACTIVE_TOKENS = 50
+	// PURGE_AFTER süresi dolan ya da iptal edilen token'ların listede kalma süresi.
+	PURGE_AFTER = 30 * 24 * time.Hour
+	// LAST_USED_RESOLUTION last_used_at her istekte değil en fazla bu sıklıkla yazılır.
+	LAST_USED_RESOLUTION = time.Minute
+)
+
+// Created oluşturulan token; Token alanı sadece bu yanıtta döner.
+type Created struct {
+	repo.AccessToken
+	Token string `json:"token"`
+}
+
+type Service struct {
+	tokens repo.AccessTokenRepository
+	users  repo.UserRepository
+	logger logger.Logger
+}
+
+func NewService(tokens repo.AccessTokenRepository, users repo.UserRepository, logger logger.Logger) *Service {
+	return &Service{tokens: tokens, users: users, logger: logger}
+}
+
+// Create kullanıcıya yeni bir token üretir. ttlDays 0 ise DEFAULT_TTL_DAYS; süresiz token yoktur.
+func (s *Service) Create(ctx context.Context, userID int64, name string, scopes []policy.Scope, ttlDays int) (*Created, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MAX_NAME_LENGTH {
+		return nil, ErrInvalidName
+	}
+	clean, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	if ttlDays == 0 {
+		ttlDays = DEFAULT_TTL_DAYS
+	}
+	if ttlDays < 0 || ttlDays > MAX_TTL_DAYS {
+		return nil, ErrInvalidExpiry
+	}
+
+	active, err := s.tokens.CountActive(ctx, userID)
+	if err != nil {
+		s.logger.Error("access token count failed", "user_id", userID, "error", err)
+		return nil, ErrSystem
+	}
+	if active >= MAX_ACTIVE_TOKENS {
+		return nil, ErrTooManyTokens
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	id, err := randomToken(12)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	raw := TOKEN_PREFIX + secret
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Duration(ttlDays) * 24 * time.Hour)
+	t := repo.AccessToken{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashToken(raw),
+		Prefix:    raw[:DISPLAY_PREFIX],
+		Scopes:    clean,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+		PurgeAt:   expiresAt.Add(PURGE_AFTER),
+	}
+	if err := s.tokens.Insert(ctx, t); err != nil {
+		s.logger.Error("access token create failed", "user_id", userID, "error", err)
+		return nil, ErrSystem
+	}
+	return &Created{AccessToken: t, Token: raw}, nil
+}
+
+// List kullanıcının token'ları; iptal edilen ve süresi dolanlar PURGE_AFTER boyunca listede kalır.
+func (s *Service) List(ctx context.Context, userID int64) ([]repo.AccessToken, error) {
+	list, err := s.tokens.ListByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("access token list failed", "user_id", userID, "error", err)
+		return nil, ErrSystem
+	}
+	return list, nil
+}
+
+func (s *Service) Revoke(ctx context.Context, userID int64, id string) error {
+	ok, err := s.tokens.Revoke(ctx, userID, id, time.Now().UTC().Add(PURGE_AFTER))
+	if err != nil {
+		s.logger.Error("access token revoke failed", "user_id", userID, "error", err)
+		return ErrSystem
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Authenticate "pat_..." token'ının sahibini ve kapsamlarını döner.
+func (s *Service) Authenticate(ctx context.Context, raw string) (*repo.User, *repo.AccessToken, error) {
+	if !IsToken(raw) {
+		return nil, nil, ErrInvalidToken
+	}
+	t, err := s.tokens.GetByHash(ctx, hashToken(raw))
+	if err != nil {
+		s.logger.Error("access token lookup failed", "error", err)
+		return nil, nil, ErrSystem
+	}
+	if t == nil || t.RevokedAt != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	now := time.Now().UTC()
+	if !now.Before(t.ExpiresAt) {
+		return nil, nil, ErrExpired
+	}
+
+	user, err := s.users.GetByID(ctx, t.UserID)
+	if err != nil {
+		s.logger.Error("user lookup failed", "user_id", t.UserID, "error", err)
+		return nil, nil, ErrSystem
+	}
+	if user == nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= LAST_USED_RESOLUTION {
+		// yazılamazsa istek yine de geçer; sadece son kullanım bilgisi eksik kalır
+		if err := s.tokens.TouchLastUsed(ctx, t.ID, now); err != nil {
+			s.logger.Warn("access token touch failed", "token_id", t.ID, "error", err)
+		}
+		t.LastUsedAt = &now
+	}
+	return user, t, nil
+}
+
+// IsToken değerin erişim token'ı biçiminde olup olmadığı; diğer Bearer token'larını (admin) ayırmak için.
+func IsToken(raw string) bool {
+	return strings.HasPrefix(raw, TOKEN_PREFIX) && len(raw) > DISPLAY_PREFIX
+}
+
+// Scopes token'da kayıtlı kapsamlar; bilinmeyenler atlanır.
+func Scopes(t *repo.AccessToken) []policy.Scope {
+	out := make([]policy.Scope, 0, len(t.Scopes))
+	for _, s := range t.Scopes {
+		if sc := policy.Scope(s); sc.Valid() {
+			out = append(out, sc)
+		}
+	}
+	return out
+}
+
+func normalizeScopes(scopes []policy.Scope) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+	out := make([]string, 0, len(scopes))
+	seen := map[policy.Scope]bool{}
+	for _, s := range scopes {
+		if !s.Valid() {
+			return nil, ErrInvalidScope
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, string(s))
+		}
+	}
+	return out, nil
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
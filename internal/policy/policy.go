@@ -0,0 +1,188 @@
+package policy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+var (
+	ErrForbidden = errors.New("forbidden")
+	// ErrInsufficientScope rol izin veriyor ama erişim token'ının kapsamı yetmiyor.
+	ErrInsufficientScope = errors.New("insufficient_scope")
+	// ErrNotMember workspace yoksa da döner; üye olmayan workspace'in varlığını öğrenemez.
+	ErrNotMember = errors.New("workspace_not_found")
+	ErrSystem    = errors.New("system_error")
+)
+
+type Role string
+
+const (
+	ROLE_OWNER  Role = "owner"
+	ROLE_ADMIN  Role = "admin"
+	ROLE_EDITOR Role = "editor"
+	ROLE_VIEWER Role = "viewer"
+)
+
+type Action string
+
+const (
+	LINKS_READ       Action = "links:read"
+	LINKS_WRITE      Action = "links:write"
+	STATS_READ       Action = "stats:read"
+	USAGE_READ       Action = "usage:read"
+	SETTINGS_READ    Action = "settings:read"
+	SETTINGS_WRITE   Action = "settings:write"
+	MEMBERS_READ     Action = "members:read"
+	MEMBERS_MANAGE   Action = "members:manage"
+	WORKSPACE_MANAGE Action = "workspace:manage"
+)
+
+// grants her rol bir alttakinin bütün yetkilerine sahiptir.
+var grants = map[Role][]Action{
+	ROLE_VIEWER: {LINKS_READ, STATS_READ, SETTINGS_READ, MEMBERS_READ},
+	ROLE_EDITOR: {LINKS_READ, STATS_READ, SETTINGS_READ, MEMBERS_READ, LINKS_WRITE},
+	ROLE_ADMIN:  {LINKS_READ, STATS_READ, SETTINGS_READ, MEMBERS_READ, LINKS_WRITE, USAGE_READ, SETTINGS_WRITE, MEMBERS_MANAGE},
+	ROLE_OWNER:  {LINKS_READ, STATS_READ, SETTINGS_READ, MEMBERS_READ, LINKS_WRITE, USAGE_READ, SETTINGS_WRITE, MEMBERS_MANAGE, WORKSPACE_MANAGE},
+}
+
+// Scope kişisel erişim token'larının kapsamı. Token sahibinin rolünü genişletmez, sadece daraltır.
+type Scope string
+
+const (
+	SCOPE_LINKS_READ  Scope = "links:read"
+	SCOPE_LINKS_WRITE Scope = "links:write"
+	SCOPE_STATS_READ  Scope = "stats:read"
+	// SCOPE_ADMIN rolün izin verdiği her şey: ayarlar, kullanım, üyeler, workspace'ler.
+	SCOPE_ADMIN Scope = "admin"
+)
+
+var scopeGrants = map[Scope][]Action{
+	SCOPE_LINKS_READ:  {LINKS_READ},
+	SCOPE_LINKS_WRITE: {LINKS_WRITE},
+	SCOPE_STATS_READ:  {STATS_READ},
+	SCOPE_ADMIN:       nil,
+}
+
+func (s Scope) Valid() bool {
+	_, ok := scopeGrants[s]
+	return ok
+}
+
+func (s Scope) Allows(a Action) bool {
+	if s == SCOPE_ADMIN {
+		return true
+	}
+	for _, g := range scopeGrants[s] {
+		if g == a {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope kapsamlardan biri istenen kapsam ya da admin mi.
+func HasScope(scopes []Scope, want Scope) bool {
+	for _, s := range scopes {
+		if s == want || s == SCOPE_ADMIN {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Role) Valid() bool {
+	_, ok := grants[r]
+	return ok
+}
+
+func (r Role) Can(a Action) bool {
+	for _, g := range grants[r] {
+		if g == a {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManage actor'ün, rolü current olan bir üyeyi next rolüne getirip getiremeyeceği (next boşsa üyeyi çıkarma).
+// Sahiplik sadece sahipler arasında verilip alınır; admin'ler sahiplere dokunamaz.
+func (r Role) CanManage(current, next Role) bool {
+	if !r.Can(MEMBERS_MANAGE) {
+		return false
+	}
+	if r == ROLE_OWNER {
+		return true
+	}
+	return current != ROLE_OWNER && next != ROLE_OWNER
+}
+
+// Subject isteği yapan ve adına işlem yaptığı sahip. Kişisel alanda (kullanıcının kendi ID'si ya da API
+// anahtarının sahibi) rol her zaman owner'dır; workspace'te üyelik rolüdür.
+type Subject struct {
+	// UserID oturumla gelen isteklerde dolu; API anahtarında nil.
+	UserID *int64
+	// OwnerID linklerin, kotanın ve ayarların bağlı olduğu sahip: kişisel alan ya da workspace ID'si.
+	OwnerID     int64
+	WorkspaceID *int64
+	Role        Role
+	// Scopes erişim token'ıyla gelen isteklerde token'ın kapsamları; oturum ve API anahtarında nil (kısıt yok).
+	Scopes []Scope
+}
+
+func (s Subject) Can(a Action) bool {
+	return s.Role.Can(a) && s.scopeAllows(a)
+}
+
+func (s Subject) scopeAllows(a Action) bool {
+	if s.Scopes == nil {
+		return true
+	}
+	for _, sc := range s.Scopes {
+		if sc.Allows(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// Service workspace üyeliklerinden rol çözer. Yetki kararları sadece bu paketteki tablo ile verilir.
+type Service struct {
+	workspaces repo.WorkspaceRepository
+	logger     logger.Logger
+}
+
+func NewService(workspaces repo.WorkspaceRepository, logger logger.Logger) *Service {
+	return &Service{workspaces: workspaces, logger: logger}
+}
+
+// Personal kullanıcının ya da API anahtarı sahibinin kendi alanı.
+func Personal(userID *int64, ownerID int64) Subject {
+	return Subject{UserID: userID, OwnerID: ownerID, Role: ROLE_OWNER}
+}
+
+// Workspace kullanıcının workspace'teki rolüyle Subject döner; üye değilse ErrNotMember.
+func (s *Service) Workspace(ctx context.Context, userID, workspaceID int64) (Subject, error) {
+	m, err := s.workspaces.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("membership lookup failed", "workspace_id", workspaceID, "user_id", userID, "error", err)
+		return Subject{}, ErrSystem
+	}
+	if m == nil || !Role(m.Role).Valid() {
+		return Subject{}, ErrNotMember
+	}
+	return Subject{UserID: &userID, OwnerID: workspaceID, WorkspaceID: &workspaceID, Role: Role(m.Role)}, nil
+}
+
+// Authorize Subject'in action'ı yapıp yapamayacağı.
+func Authorize(s Subject, a Action) error {
+	if !s.Role.Can(a) {
+		return ErrForbidden
+	}
+	if !s.scopeAllows(a) {
+		return ErrInsufficientScope
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package pow
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+)
+
+var (
+	ErrRequired = errors.New("pow_required")
+	ErrInvalid  = errors.New("invalid_pow")
+	// ErrUnavailable tekrar kontrolü yapılamadığında döner; kullanılmış bulmaca kabul edilmesin diye istek reddedilir.
+	ErrUnavailable = errors.New("pow_unavailable")
+)
+
+const (
+	ALGORITHM     = "sha256"
+	CHALLENGE_TTL = 5 * time.Minute
+	// MAX_DIFFICULTY tarayıcıda makul sürede çözülebilecek üst sınır (bit); 22 bit yavaş cihazlarda birkaç saniye sürer.
+	MAX_DIFFICULTY = 22
+	// DEFAULT_LOAD_THRESHOLD dakikada bu kadar challenge verilince zorluk artmaya başlar.
+	DEFAULT_LOAD_THRESHOLD = 60
+	// DEFAULT_EXTRA_DIFFICULTY PowMaxDifficulty verilmemişse yük altında eklenebilecek en fazla bit.
+	DEFAULT_EXTRA_DIFFICULTY = 6
+	MAX_SOLUTION_LEN         = 64
+
+	// PAYLOAD_PREFIX imzalanan veriyi ayırır; signer ara sayfa ve kilit token'larıyla ortak.
+	PAYLOAD_PREFIX = "pow|"
+
+	LOAD_KEY_PREFIX = "pow:load:"
+	USED_KEY_PREFIX = "pow:used:"
+)
+
+type Challenge struct {
+	Required   bool       `json:"required"`
+	Challenge  string     `json:"challenge,omitempty"`
+	Difficulty int        `json:"difficulty,omitempty"`
+	Algorithm  string     `json:"algorithm,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// Service durumsuz proof-of-work bulmacaları üretir ve doğrular.
+// Challenge formatı: nonce + "." + zorluk + "." + signer token'ı; zorluk imzanın içinde olduğu için değiştirilemez.
+// Çözüm, sha256(challenge + ":" + solution) en az zorluk kadar sıfır bitle başlayan herhangi bir string'dir.
+type Service struct {
+	signer *security.Signer
+	cache  cache.Cache
+	logger logger.Logger
+}
+
+func NewService(signer *security.Signer, c cache.Cache, logger logger.Logger) *Service {
+	return &Service{signer: signer, cache: c, logger: logger}
+}
+
+// Enabled PowDifficulty 0 ise bulmaca istenmez.
+func Enabled(settings repo.Settings) bool {
+	return settings.PowDifficulty > 0
+}
+
+// Issue yeni bir bulmaca üretir; zorluk o dakikada verilen challenge sayısına göre artar.
+func (s *Service) Issue(ctx context.Context, settings repo.Settings) (Challenge, error) {
+	if !Enabled(settings) {
+		return Challenge{Required: false}, nil
+	}
+
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return Challenge{}, err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(b[:])
+
+	difficulty := s.difficulty(ctx, settings)
+	d := strconv.Itoa(difficulty)
+	token := s.signer.Sign(payload(nonce, d), CHALLENGE_TTL)
+	expiresAt := time.Now().Add(CHALLENGE_TTL).UTC()
+
+	return Challenge{
+		Required:   true,
+		Challenge:  nonce + "." + d + "." + token,
+		Difficulty: difficulty,
+		Algorithm:  ALGORITHM,
+		ExpiresAt:  &expiresAt,
+	}, nil
+}
+
+// Verify imzayı, süreyi ve çözümü kontrol eder, sonra challenge'ı Redis'te kullanılmış olarak işaretler.
+// Redis'e ulaşılamazsa tekrar kontrolü yapılamadığı için ErrUnavailable döner.
+func (s *Service) Verify(ctx context.Context, challenge, solution string) error {
+	if challenge == "" || solution == "" {
+		return ErrRequired
+	}
+	if len(solution) > MAX_SOLUTION_LEN {
+		return ErrInvalid
+	}
+
+	parts := strings.SplitN(challenge, ".", 3)
+	if len(parts) != 3 {
+		return ErrInvalid
+	}
+	nonce, d, token := parts[0], parts[1], parts[2]
+	difficulty, err := strconv.Atoi(d)
+	if err != nil || difficulty < 1 || difficulty > MAX_DIFFICULTY {
+		return ErrInvalid
+	}
+	if !s.signer.Verify(token, payload(nonce, d)) {
+		return ErrInvalid
+	}
+	if LeadingZeroBits(sha256.Sum256([]byte(challenge+":"+solution))) < difficulty {
+		return ErrInvalid
+	}
+
+	fresh, err := s.cache.SetIntNX(ctx, USED_KEY_PREFIX+nonce, 1, CHALLENGE_TTL)
+	if err != nil {
+		s.logger.Error("pow: replay check failed", "error", err)
+		return ErrUnavailable
+	}
+	if !fresh {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// difficulty taban zorluk; dakikadaki challenge sayısı eşiği her iki katına çıktığında bir bit artar.
+func (s *Service) difficulty(ctx context.Context, settings repo.Settings) int {
+	base := int(settings.PowDifficulty)
+	ceiling := int(settings.PowMaxDifficulty)
+	if ceiling <= 0 {
+		ceiling = base + DEFAULT_EXTRA_DIFFICULTY
+	}
+	ceiling = min(max(ceiling, base), MAX_DIFFICULTY)
+
+	threshold := int64(settings.PowLoadThreshold)
+	if threshold == 0 {
+		threshold = DEFAULT_LOAD_THRESHOLD
+	}
+	if threshold < 0 {
+		return min(base, ceiling)
+	}
+
+	key := fmt.Sprintf("%s%d", LOAD_KEY_PREFIX, time.Now().Unix()/60)
+	n, err := s.cache.Incr(ctx, key, 2*time.Minute)
+	if err != nil {
+		s.logger.Error("pow: load counter failed", "error", err)
+		return min(base, ceiling)
+	}
+
+	extra := 0
+	for load := n; load > threshold; load /= 2 {
+		extra++
+	}
+	return min(base+extra, ceiling)
+}
+
+func payload(nonce, difficulty string) string {
+	return PAYLOAD_PREFIX + nonce + "|" + difficulty
+}
+
+func LeadingZeroBits(sum [sha256.Size]byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
@@ -0,0 +1,22 @@
+package quota
+
+import "github.com/emrealsandev/Url-Shortener/internal/repo"
+
+const (
+	PLAN_FREE     = "free"
+	PLAN_PRO      = "pro"
+	PLAN_BUSINESS = "business"
+	DEFAULT_PLAN  = PLAN_FREE
+)
+
+// plans plan bazlı limitler; sahibe özel Owner.Quota bunların yerine geçer. 0 → limitsiz.
+var plans = map[string]repo.Quota{
+	PLAN_FREE:     {LinksPerMonth: 100, CustomAliases: 10, TrackedClicksPerMonth: 10_000, MaxBatchSize: 10},
+	PLAN_PRO:      {LinksPerMonth: 5_000, CustomAliases: 500, TrackedClicksPerMonth: 1_000_000, MaxBatchSize: 100},
+	PLAN_BUSINESS: {LinksPerMonth: 100_000, CustomAliases: 0, TrackedClicksPerMonth: 0, MaxBatchSize: 1_000},
+}
+
+func ValidPlan(name string) bool {
+	_, ok := plans[name]
+	return ok
+}
@@ -0,0 +1,382 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+)
+
+var (
+	ErrInvalidPlan   = errors.New("invalid_plan")
+	ErrInvalidPeriod = errors.New("invalid_period")
+)
+
+const (
+	FLUSH_INTERVAL = time.Minute
+	FLUSH_BATCH    = 500
+	// DIRTY_KEY son flush'tan beri sayacı değişen "<owner_id>:<dönem>" kayıtları.
+	DIRTY_KEY = "quota:dirty"
+	// LIMITS_TTL sahibin planı bu kadar süre bellekte tutulur; plan değişikliği en geç bu sürede yayılır.
+	LIMITS_TTL    = time.Minute
+	PERIOD_LAYOUT = "2006-01"
+)
+
+const (
+	FIELD_LINKS   = "links"
+	FIELD_ALIASES = "aliases"
+	FIELD_CLICKS  = "clicks"
+	FIELD_DROPPED = "dropped"
+)
+
+// Report GET /v1/usage cevabı.
+type Report struct {
+	OwnerID int64      `json:"owner_id"`
+	Plan    string     `json:"plan"`
+	Period  string     `json:"period"`
+	ResetAt time.Time  `json:"reset_at"`
+	Limits  repo.Quota `json:"limits"`
+	Usage   repo.Usage `json:"usage"`
+}
+
+type ownerLimits struct {
+	plan    string
+	quota   repo.Quota
+	expires time.Time
+}
+
+// Service sahip bazlı kotaları uygular. Sayaçlar Redis'te tutulur ve FLUSH_INTERVAL'da bir Mongo'ya
+// yazılır; Redis'te sayaç yoksa Mongo'daki son değerden başlatılır.
+// Redis veya Mongo'ya ulaşılamazsa kota uygulanmaz, istekler engellenmez.
+type Service struct {
+	repo   repo.Repository
+	cache  cache.Cache
+	logger logger.Logger
+
+	mu     sync.Mutex
+	limits map[int64]ownerLimits
+}
+
+func NewService(r repo.Repository, c cache.Cache, logger logger.Logger) *Service {
+	return &Service{repo: r, cache: c, logger: logger, limits: map[int64]ownerLimits{}}
+}
+
+func Period(t time.Time) string {
+	return t.UTC().Format(PERIOD_LAYOUT)
+}
+
+// ParsePeriod "YYYY-MM"; boşsa içinde bulunulan ay.
+func ParsePeriod(p string) (string, error) {
+	if p == "" {
+		return Period(time.Now()), nil
+	}
+	t, err := time.Parse(PERIOD_LAYOUT, p)
+	if err != nil {
+		return "", ErrInvalidPeriod
+	}
+	return Period(t), nil
+}
+
+// periodEnd dönemlik kotaların yenilendiği an: bir sonraki ayın ilk günü (UTC).
+func periodEnd(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *Service) ReserveLink(ctx context.Context, ownerID int64, customAlias bool) error {
+	_, q := s.ownerLimits(ctx, ownerID)
+	now := time.Now()
+	p := Period(now)
+
+	n, err := s.incr(ctx, ownerID, p, FIELD_LINKS, 1)
+	if err != nil {
+		s.logger.Error("quota: links counter failed", "owner_id", ownerID, "error", err)
+		return nil
+	}
+	if q.LinksPerMonth > 0 && n > q.LinksPerMonth {
+		s.undo(ctx, ownerID, p, FIELD_LINKS)
+		reset := periodEnd(now)
+		return &short.QuotaExceededError{Quota: short.QUOTA_LINKS_PER_MONTH, Limit: q.LinksPerMonth, Used: n - 1, ResetAt: &reset}
+	}
+
+	if customAlias {
+		a, err := s.incr(ctx, ownerID, p, FIELD_ALIASES, 1)
+		if err != nil {
+			s.logger.Error("quota: alias counter failed", "owner_id", ownerID, "error", err)
+		} else if q.CustomAliases > 0 && a > q.CustomAliases {
+			s.undo(ctx, ownerID, p, FIELD_ALIASES)
+			s.undo(ctx, ownerID, p, FIELD_LINKS)
+			return &short.QuotaExceededError{Quota: short.QUOTA_CUSTOM_ALIASES, Limit: q.CustomAliases, Used: a - 1}
+		}
+	}
+
+	s.markDirty(ctx, ownerID, p)
+	return nil
+}
+
+func (s *Service) ReleaseLink(ctx context.Context, ownerID int64, customAlias bool) {
+	p := Period(time.Now())
+	s.undo(ctx, ownerID, p, FIELD_LINKS)
+	if customAlias {
+		s.undo(ctx, ownerID, p, FIELD_ALIASES)
+	}
+	s.markDirty(ctx, ownerID, p)
+}
+
+func (s *Service) TrackClick(ctx context.Context, ownerID int64) bool {
+	_, q := s.ownerLimits(ctx, ownerID)
+	p := Period(time.Now())
+	defer s.markDirty(ctx, ownerID, p)
+
+	n, err := s.incr(ctx, ownerID, p, FIELD_CLICKS, 1)
+	if err != nil {
+		s.logger.Error("quota: clicks counter failed", "owner_id", ownerID, "error", err)
+		return true
+	}
+	if q.TrackedClicksPerMonth > 0 && n > q.TrackedClicksPerMonth {
+		s.undo(ctx, ownerID, p, FIELD_CLICKS)
+		if _, err := s.incr(ctx, ownerID, p, FIELD_DROPPED, 1); err != nil {
+			s.logger.Error("quota: dropped counter failed", "owner_id", ownerID, "error", err)
+		}
+		return false
+	}
+	return true
+}
+
+func (s *Service) CheckBatch(ctx context.Context, ownerID int64, size int) error {
+	_, q := s.ownerLimits(ctx, ownerID)
+	if q.MaxBatchSize > 0 && int64(size) > q.MaxBatchSize {
+		return &short.QuotaExceededError{Quota: short.QUOTA_MAX_BATCH_SIZE, Limit: q.MaxBatchSize, Used: int64(size)}
+	}
+	return nil
+}
+
+// Usage sahibin içinde bulunulan aydaki kullanımı ve limitleri.
+func (s *Service) Usage(ctx context.Context, ownerID int64) (*Report, error) {
+	plan, q := s.ownerLimits(ctx, ownerID)
+	now := time.Now()
+	p := Period(now)
+
+	u, err := s.current(ctx, ownerID, p)
+	if err != nil {
+		s.logger.Error("quota: usage read failed", "owner_id", ownerID, "error", err)
+		return nil, short.ErrSystem
+	}
+	return &Report{OwnerID: ownerID, Plan: plan, Period: p, ResetAt: periodEnd(now), Limits: q, Usage: u}, nil
+}
+
+// Export faturalama için dönemin bütün sahiplerinin kullanımı; önce bekleyen sayaçlar yazılır.
+func (s *Service) Export(ctx context.Context, period string) ([]repo.Usage, error) {
+	if err := s.Flush(ctx); err != nil {
+		s.logger.Error("quota: flush before export failed", "error", err)
+	}
+	out, err := s.repo.ListUsage(ctx, period)
+	if err != nil {
+		return nil, short.ErrSystem
+	}
+	return out, nil
+}
+
+// SetPlan sahibin planını değiştirir; quota verilirse plan limitlerinin yerine geçer.
+func (s *Service) SetPlan(ctx context.Context, ownerID int64, plan string, quota *repo.Quota) error {
+	if plan == "" {
+		plan = DEFAULT_PLAN
+	}
+	if !ValidPlan(plan) {
+		return ErrInvalidPlan
+	}
+	if quota != nil && (quota.LinksPerMonth < 0 || quota.CustomAliases < 0 || quota.TrackedClicksPerMonth < 0 || quota.MaxBatchSize < 0) {
+		return ErrInvalidPlan
+	}
+	if err := s.repo.SetOwnerPlan(ctx, ownerID, plan, quota); err != nil {
+		s.logger.Error("quota: set plan failed", "owner_id", ownerID, "error", err)
+		return short.ErrSystem
+	}
+
+	s.mu.Lock()
+	delete(s.limits, ownerID)
+	s.mu.Unlock()
+	return nil
+}
+
+// Run ctx iptal edilene kadar sayaçları periyodik olarak Mongo'ya yazar; kapanırken son bir kez yazar.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(FLUSH_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.Flush(flushCtx); err != nil {
+				s.logger.Error("quota: final flush failed", "error", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				s.logger.Error("quota: flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// Flush değişen sayaçları Mongo'ya yazar. Birden fazla instance aynı anda çalışsa da her kayıt
+// set'ten bir kez çekildiği için aynı işi iki kez yapmazlar.
+func (s *Service) Flush(ctx context.Context) error {
+	for {
+		members, err := s.cache.PopFromSet(ctx, DIRTY_KEY, FLUSH_BATCH)
+		if err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		for _, m := range members {
+			ownerID, p, ok := parseDirty(m)
+			if !ok {
+				continue
+			}
+			u, err := s.current(ctx, ownerID, p)
+			if err == nil {
+				err = s.repo.SaveUsage(ctx, u)
+			}
+			if err != nil {
+				// Bir sonraki turda tekrar denensin.
+				_ = s.cache.AddToSet(ctx, DIRTY_KEY, m)
+				return err
+			}
+		}
+	}
+}
+
+// ownerLimits planı bellekte kısa süre tutar; her tıklamada Mongo'ya gitmemek için.
+// Sahip okunamazsa varsayılan plan uygulanır.
+func (s *Service) ownerLimits(ctx context.Context, ownerID int64) (string, repo.Quota) {
+	s.mu.Lock()
+	l, ok := s.limits[ownerID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(l.expires) {
+		return l.plan, l.quota
+	}
+
+	plan, q := DEFAULT_PLAN, plans[DEFAULT_PLAN]
+	owner, err := s.repo.GetOwner(ctx, ownerID)
+	if err != nil {
+		s.logger.Error("quota: owner lookup failed", "owner_id", ownerID, "error", err)
+		return plan, q
+	}
+	if owner != nil {
+		if p, ok := plans[owner.Plan]; ok {
+			plan, q = owner.Plan, p
+		}
+		if owner.Quota != nil {
+			q = *owner.Quota
+		}
+	}
+
+	s.mu.Lock()
+	s.limits[ownerID] = ownerLimits{plan: plan, quota: q, expires: time.Now().Add(LIMITS_TTL)}
+	s.mu.Unlock()
+	return plan, q
+}
+
+// current sahibin dönemdeki bütün sayaçlarını okur.
+func (s *Service) current(ctx context.Context, ownerID int64, p string) (repo.Usage, error) {
+	u := repo.Usage{ID: usageID(ownerID, p), OwnerID: ownerID, Period: p, UpdatedAt: time.Now().UTC()}
+	for field, dst := range map[string]*int64{
+		FIELD_LINKS:   &u.Links,
+		FIELD_ALIASES: &u.CustomAliases,
+		FIELD_CLICKS:  &u.TrackedClicks,
+		FIELD_DROPPED: &u.DroppedClicks,
+	} {
+		n, err := s.incr(ctx, ownerID, p, field, 0)
+		if err != nil {
+			return repo.Usage{}, err
+		}
+		*dst = n
+	}
+	return u, nil
+}
+
+// incr sayacı n kadar artırır; sayaç Redis'te yoksa önce Mongo'dan başlatılır.
+func (s *Service) incr(ctx context.Context, ownerID int64, p, field string, n int64) (int64, error) {
+	key := counterKey(ownerID, p, field)
+	if _, ok, err := s.cache.GetInt(ctx, key); err != nil {
+		return 0, err
+	} else if !ok {
+		seed, err := s.seed(ctx, ownerID, p, field)
+		if err != nil {
+			return 0, err
+		}
+		// Aylık sayaçlar dönem bittikten sonra son flush'a yetecek kadar yaşar; alias sayacı kalıcı.
+		var ttl time.Duration
+		if field != FIELD_ALIASES {
+			if start, err := time.Parse(PERIOD_LAYOUT, p); err == nil {
+				ttl = time.Until(periodEnd(start)) + 7*24*time.Hour
+			}
+		}
+		if _, err := s.cache.SetIntNX(ctx, key, seed, ttl); err != nil {
+			return 0, err
+		}
+	}
+	return s.cache.IncrBy(ctx, key, n)
+}
+
+func (s *Service) undo(ctx context.Context, ownerID int64, p, field string) {
+	if _, err := s.cache.IncrBy(ctx, counterKey(ownerID, p, field), -1); err != nil {
+		s.logger.Error("quota: counter rollback failed", "owner_id", ownerID, "field", field, "error", err)
+	}
+}
+
+func (s *Service) seed(ctx context.Context, ownerID int64, p, field string) (int64, error) {
+	if field == FIELD_ALIASES {
+		return s.repo.CountCustomAliases(ctx, ownerID)
+	}
+	u, err := s.repo.GetUsage(ctx, ownerID, p)
+	if err != nil || u == nil {
+		return 0, err
+	}
+	switch field {
+	case FIELD_LINKS:
+		return u.Links, nil
+	case FIELD_CLICKS:
+		return u.TrackedClicks, nil
+	case FIELD_DROPPED:
+		return u.DroppedClicks, nil
+	}
+	return 0, nil
+}
+
+func (s *Service) markDirty(ctx context.Context, ownerID int64, p string) {
+	if err := s.cache.AddToSet(ctx, DIRTY_KEY, usageID(ownerID, p)); err != nil {
+		s.logger.Error("quota: mark dirty failed", "owner_id", ownerID, "error", err)
+	}
+}
+
+func counterKey(ownerID int64, p, field string) string {
+	if field == FIELD_ALIASES {
+		return fmt.Sprintf("quota:%d:%s", ownerID, field)
+	}
+	return fmt.Sprintf("quota:%d:%s:%s", ownerID, p, field)
+}
+
+func usageID(ownerID int64, p string) string {
+	return strconv.FormatInt(ownerID, 10) + ":" + p
+}
+
+func parseDirty(m string) (int64, string, bool) {
+	id, p, ok := strings.Cut(m, ":")
+	if !ok {
+		return 0, "", false
+	}
+	ownerID, err := strconv.ParseInt(id, 10, 64)
+	return ownerID, p, err == nil
+}
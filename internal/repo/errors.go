@@ -0,0 +1,8 @@
+package repo
+
+import "errors"
+
+var (
+	ErrNotFound  = errors.New("not_found")
+	ErrDuplicate = errors.New("duplicate")
+)
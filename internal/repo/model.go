@@ -3,25 +3,356 @@ package repo
 import (
 	"reflect"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const COLLECTION_URLS = "urls"
 const COLLECTION_SETTINGS = "settings"
 const COLLECTION_SEQUENCE = "sequence"
+const COLLECTION_REPORTS = "reports"
+const COLLECTION_CLICKS = "clicks"
+const COLLECTION_OWNERS = "owners"
+const COLLECTION_USAGE = "usage"
+const COLLECTION_USERS = "users"
+const COLLECTION_WORKSPACES = "workspaces"
+const COLLECTION_MEMBERSHIPS = "memberships"
+const COLLECTION_INVITATIONS = "invitations"
+const COLLECTION_ACCESS_TOKENS = "access_tokens"
+
+const (
+	QUERY_FORWARD_MERGE    = "merge"
+	QUERY_FORWARD_OVERRIDE = "override"
+)
+
+const (
+	TEMPLATE_MISSING_ERROR = "error"
+	TEMPLATE_MISSING_EMPTY = "empty"
+)
+
+const (
+	HEALTH_UNKNOWN  = "unknown"
+	HEALTH_HEALTHY  = "healthy"
+	HEALTH_DEGRADED = "degraded"
+)
+
+const (
+	REPORT_STATUS_PENDING   = "pending"
+	REPORT_STATUS_ACCEPTED  = "accepted"
+	REPORT_STATUS_DISMISSED = "dismissed"
+)
 
 type URL struct {
 	Code        string     `bson:"code" json:"code"`
 	Target      string     `bson:"target" json:"target"`
 	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+	StartsAt    *time.Time `bson:"starts_at,omitempty" json:"starts_at,omitempty"`
 	ExpiresAt   *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
 	Disabled    bool       `bson:"disabled" json:"disabled"`
 	CustomAlias *string    `bson:"custom_alias,omitempty" json:"custom_alias,omitempty"`
 	OwnerID     *int64     `bson:"owner_id,omitempty" json:"owner_id,omitempty"`
+	// nil → Settings'e göre karar verilir, true → her zaman ara sayfa gösterilir.
+	Interstitial *bool `bson:"interstitial,omitempty" json:"interstitial,omitempty"`
+	Flagged      bool  `bson:"flagged,omitempty" json:"flagged,omitempty"`
+	// bcrypt hash; boşsa link şifresizdir.
+	PasswordHash string `bson:"password_hash,omitempty" json:"-"`
+	// MaxClicks doluysa Clicks repo'da atomik olarak artırılır, limit dolunca link 410 döner.
+	MaxClicks *int64 `bson:"max_clicks,omitempty" json:"max_clicks,omitempty"`
+	Clicks    int64  `bson:"clicks,omitempty" json:"clicks,omitempty"`
+	// Rules sırayla denenir, eşleşen ilk kuralın hedefi kullanılır; hiçbiri eşleşmezse Target.
+	Rules []RoutingRule `bson:"rules,omitempty" json:"rules,omitempty"`
+	// Destinations doluysa trafik ağırlıklara göre bölünür (A/B); Target sadece yedek olarak kalır.
+	Destinations []Destination `bson:"destinations,omitempty" json:"destinations,omitempty"`
+	// Sticky aynı ziyaretçi hep aynı varyanta düşer.
+	Sticky bool `bson:"sticky,omitempty" json:"sticky,omitempty"`
+	// QueryForward gelen query parametrelerini hedefe taşır: "merge" (hedefteki kazanır) veya "override".
+	QueryForward string `bson:"query_forward,omitempty" json:"query_forward,omitempty"`
+	// PathForward link bir önek gibi davranır: /abc/docs/page → <target>/docs/page
+	PathForward bool `bson:"path_forward,omitempty" json:"path_forward,omitempty"`
+	// Template doluysa Target "{isim}" yer tutucuları içerir ve yönlendirmede doldurulur.
+	Template *TemplateConfig `bson:"template,omitempty" json:"template,omitempty"`
+	// UTM yönlendirmede hedefe eklenir; sahibin şablonundaki alanları ezer.
+	UTM *UTMParams `bson:"utm,omitempty" json:"utm,omitempty"`
+	// DeepLink mobil ziyaretçilerde önce uygulama açılmaya çalışılır.
+	DeepLink *DeepLink `bson:"deep_link,omitempty" json:"deep_link,omitempty"`
+	// RedirectType 301, 302, 307 veya 308; 0 → Settings.DefaultRedirectType.
+	RedirectType int `bson:"redirect_type,omitempty" json:"redirect_type,omitempty"`
+	// Metadata hedef sayfadan arka planda çekilen başlık, açıklama, OpenGraph ve favicon bilgisi.
+	Metadata *Metadata `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	// SocialCard doluysa sosyal medya botlarına bu OpenGraph değerleriyle bir sayfa gösterilir.
+	SocialCard *SocialCard `bson:"social_card,omitempty" json:"social_card,omitempty"`
+	// Health periyodik erişilebilirlik kontrolü; link degraded olunca FallbackTargets içinden
+	// sağlıklı olan ilk adrese yönlendirilir.
+	Health          *LinkHealth `bson:"health,omitempty" json:"health,omitempty"`
+	FallbackTargets []string    `bson:"fallback_targets,omitempty" json:"fallback_targets,omitempty"`
+	// HasOptions opsiyonlu linkler URL dedupe'una (GetCodeByUrl) dahil edilmez.
+	HasOptions bool `bson:"has_options,omitempty" json:"-"`
+}
+
+// RoutingRule dolu olan tüm koşullar sağlanırsa (AND) eşleşir; liste içindeki değerler OR'lanır.
+type RoutingRule struct {
+	Target    string            `bson:"target" json:"target"`
+	Devices   []string          `bson:"devices,omitempty" json:"devices,omitempty"`
+	OS        []string          `bson:"os,omitempty" json:"os,omitempty"`
+	Languages []string          `bson:"languages,omitempty" json:"languages,omitempty"`
+	Countries []string          `bson:"countries,omitempty" json:"countries,omitempty"`
+	Time      *TimeWindow       `bson:"time,omitempty" json:"time,omitempty"`
+	Query     map[string]string `bson:"query,omitempty" json:"query,omitempty"`
+}
+
+// TemplateConfig PathParams koddan sonraki path segmentlerine sırayla isim verir (/sale/123 → sku=123);
+// diğer değişkenler query'den, o da yoksa Defaults'tan okunur.
+type TemplateConfig struct {
+	PathParams []string          `bson:"path_params,omitempty" json:"path_params,omitempty"`
+	Defaults   map[string]string `bson:"defaults,omitempty" json:"defaults,omitempty"`
+	// OnMissing "error" (varsayılan, 400) veya "empty" (boş string ile doldur).
+	OnMissing string `bson:"on_missing,omitempty" json:"on_missing,omitempty"`
+}
+
+// UTMParams hedefte zaten olan parametreler korunur, sadece eksikler eklenir.
+type UTMParams struct {
+	Source   string `bson:"utm_source,omitempty" json:"utm_source,omitempty"`
+	Medium   string `bson:"utm_medium,omitempty" json:"utm_medium,omitempty"`
+	Campaign string `bson:"utm_campaign,omitempty" json:"utm_campaign,omitempty"`
+	Term     string `bson:"utm_term,omitempty" json:"utm_term,omitempty"`
+	Content  string `bson:"utm_content,omitempty" json:"utm_content,omitempty"`
+}
+
+// DeepLink uygulama açılamazsa önce mağaza adresine, o da yoksa FallbackURL'e (boşsa link hedefine) düşülür.
+type DeepLink struct {
+	// IOSURL özel şema (myapp://item/1) veya universal link (https://...).
+	IOSURL      string `bson:"ios_url,omitempty" json:"ios_url,omitempty"`
+	IOSStoreURL string `bson:"ios_store_url,omitempty" json:"ios_store_url,omitempty"`
+	// AndroidPackage doluysa uygulama intent:// ile açılır; AndroidURL boşsa link hedefi App Link olarak kullanılır.
+	AndroidURL      string `bson:"android_url,omitempty" json:"android_url,omitempty"`
+	AndroidPackage  string `bson:"android_package,omitempty" json:"android_package,omitempty"`
+	AndroidStoreURL string `bson:"android_store_url,omitempty" json:"android_store_url,omitempty"`
+	FallbackURL     string `bson:"fallback_url,omitempty" json:"fallback_url,omitempty"`
+}
+
+// Metadata Error doluysa son çekim başarısız olmuştur; önceki alanlar korunmaz.
+type Metadata struct {
+	Title         string    `bson:"title,omitempty" json:"title,omitempty"`
+	Description   string    `bson:"description,omitempty" json:"description,omitempty"`
+	Favicon       string    `bson:"favicon,omitempty" json:"favicon,omitempty"`
+	OGTitle       string    `bson:"og_title,omitempty" json:"og_title,omitempty"`
+	OGDescription string    `bson:"og_description,omitempty" json:"og_description,omitempty"`
+	OGImage       string    `bson:"og_image,omitempty" json:"og_image,omitempty"`
+	OGSiteName    string    `bson:"og_site_name,omitempty" json:"og_site_name,omitempty"`
+	OGType        string    `bson:"og_type,omitempty" json:"og_type,omitempty"`
+	FetchedAt     time.Time `bson:"fetched_at" json:"fetched_at"`
+	Error         string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+type SocialCard struct {
+	Title       string `bson:"title,omitempty" json:"title,omitempty"`
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+	Image       string `bson:"image,omitempty" json:"image,omitempty"`
+}
+
+// LinkHealth History en yeni kontrol sonda olacak şekilde sınırlı tutulur.
+type LinkHealth struct {
+	Status              string        `bson:"status" json:"status"`
+	ConsecutiveFailures int           `bson:"consecutive_failures" json:"consecutive_failures"`
+	LastCheckedAt       time.Time     `bson:"last_checked_at" json:"last_checked_at"`
+	DegradedSince       *time.Time    `bson:"degraded_since,omitempty" json:"degraded_since,omitempty"`
+	History             []HealthCheck `bson:"history,omitempty" json:"history,omitempty"`
+	// Fallbacks yedek hedeflerin son kontrol sonuçları; ana hedef başarısız olduğunda kontrol edilirler.
+	Fallbacks []FallbackHealth `bson:"fallbacks,omitempty" json:"fallbacks,omitempty"`
+	// ActiveTarget şu an yönlendirilen yedek hedef; boşsa ana hedef kullanılıyor.
+	ActiveTarget   string     `bson:"active_target,omitempty" json:"active_target,omitempty"`
+	Failovers      int        `bson:"failovers,omitempty" json:"failovers"`
+	LastFailoverAt *time.Time `bson:"last_failover_at,omitempty" json:"last_failover_at,omitempty"`
+}
+
+type FallbackHealth struct {
+	Target     string    `bson:"target" json:"target"`
+	OK         bool      `bson:"ok" json:"ok"`
+	CheckedAt  time.Time `bson:"checked_at" json:"checked_at"`
+	StatusCode int       `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+type HealthCheck struct {
+	CheckedAt  time.Time `bson:"checked_at" json:"checked_at"`
+	OK         bool      `bson:"ok" json:"ok"`
+	Method     string    `bson:"method,omitempty" json:"method,omitempty"`
+	StatusCode int       `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	LatencyMs  int64     `bson:"latency_ms" json:"latency_ms"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// Owner sahip bazlı ayarlar; _id URL.OwnerID ile aynıdır.
+type Owner struct {
+	ID  int64      `bson:"_id" json:"id"`
+	UTM *UTMParams `bson:"utm,omitempty" json:"utm,omitempty"`
+	// Plan kota tablosundaki plan adı; boşsa varsayılan plan. Quota doluysa planın yerine geçer.
+	Plan  string `bson:"plan,omitempty" json:"plan,omitempty"`
+	Quota *Quota `bson:"quota,omitempty" json:"quota,omitempty"`
+	// APIKeyHash X-API-Key'in sha256'sı; anahtarın kendisi saklanmaz.
+	APIKeyHash string    `bson:"api_key_hash,omitempty" json:"-"`
+	UpdatedAt  time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// User web arayüzü hesabı. ID aynı zamanda sahip ID'sidir; kullanıcının linkleri URL.OwnerID ile bağlanır.
+type User struct {
+	ID           int64  `bson:"_id" json:"id"`
+	Email        string `bson:"email" json:"email"`
+	PasswordHash string `bson:"password_hash" json:"-"`
+	// SessionVersion şifre değişince artar; oturumlar açıldıkları versiyonu taşır.
+	SessionVersion int64 `bson:"session_version" json:"-"`
+	// Identities SSO ile bağlanmış dış kimlikler; SSO ile açılan hesapların şifresi boştur.
+	Identities []Identity `bson:"identities,omitempty" json:"-"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// Identity OIDC sağlayıcısındaki kalıcı kullanıcı kimliği (iss + sub).
+type Identity struct {
+	Issuer   string    `bson:"issuer" json:"issuer"`
+	Subject  string    `bson:"subject" json:"subject"`
+	LinkedAt time.Time `bson:"linked_at" json:"linked_at"`
+}
+
+// Workspace ekip alanı. ID owners koleksiyonundan ayrılır; linkler (URL.OwnerID), kota, UTM şablonu ve
+// API anahtarı bu sahip ID'sine bağlıdır.
+type Workspace struct {
+	ID        int64     `bson:"_id" json:"id"`
+	Name      string    `bson:"name" json:"name"`
+	CreatedBy int64     `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Membership kullanıcının workspace'teki rolü; _id "<workspace_id>:<user_id>".
+type Membership struct {
+	ID          string    `bson:"_id" json:"-"`
+	WorkspaceID int64     `bson:"workspace_id" json:"workspace_id"`
+	UserID      int64     `bson:"user_id" json:"user_id"`
+	Email       string    `bson:"email" json:"email"`
+	Role        string    `bson:"role" json:"role"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Invitation bekleyen davet. Davet linkindeki token saklanmaz, sadece sha256'sı; süresi dolan davetler TTL index ile silinir.
+type Invitation struct {
+	ID          string    `bson:"_id" json:"id"`
+	TokenHash   string    `bson:"token_hash" json:"-"`
+	WorkspaceID int64     `bson:"workspace_id" json:"workspace_id"`
+	Email       string    `bson:"email" json:"email"`
+	Role        string    `bson:"role" json:"role"`
+	InvitedBy   int64     `bson:"invited_by" json:"invited_by"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// AccessToken kullanıcının kapsamlı kişisel erişim token'ı. Token'ın kendisi saklanmaz, sadece sha256'sı;
+// Prefix listede hangi token olduğunu tanımak için. Süresi dolanlar bir süre listede kalır, sonra TTL index ile silinir.
+type AccessToken struct {
+	ID         string     `bson:"_id" json:"id"`
+	UserID     int64      `bson:"user_id" json:"user_id"`
+	Name       string     `bson:"name" json:"name"`
+	TokenHash  string     `bson:"token_hash" json:"-"`
+	Prefix     string     `bson:"prefix" json:"prefix"`
+	Scopes     []string   `bson:"scopes" json:"scopes"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	ExpiresAt  time.Time  `bson:"expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	// Purge TTL index'inin sildiği zaman: süre dolumundan ya da iptalden bir süre sonra.
+	PurgeAt time.Time `bson:"purge_at" json:"-"`
+}
+
+// Quota plan limitleri; 0 → limitsiz.
+type Quota struct {
+	LinksPerMonth         int64 `bson:"links_per_month" json:"links_per_month"`
+	CustomAliases         int64 `bson:"custom_aliases" json:"custom_aliases"`
+	TrackedClicksPerMonth int64 `bson:"tracked_clicks_per_month" json:"tracked_clicks_per_month"`
+	MaxBatchSize          int64 `bson:"max_batch_size" json:"max_batch_size"`
+}
+
+// Usage sahibin bir aylık kullanımı; _id "<owner_id>:<YYYY-MM>". Sayaçlar Redis'te tutulur, periyodik olarak buraya yazılır.
+type Usage struct {
+	ID      string `bson:"_id" json:"-"`
+	OwnerID int64  `bson:"owner_id" json:"owner_id"`
+	Period  string `bson:"period" json:"period"`
+	Links   int64  `bson:"links" json:"links"`
+	// CustomAliases ay sonundaki toplam alias sayısı (aylık değil).
+	CustomAliases int64 `bson:"custom_aliases" json:"custom_aliases"`
+	TrackedClicks int64 `bson:"tracked_clicks" json:"tracked_clicks"`
+	// DroppedClicks kota dolduktan sonra yönlendirilen ama kaydedilmeyen tıklamalar.
+	DroppedClicks int64     `bson:"dropped_clicks" json:"dropped_clicks"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+type Destination struct {
+	Name   string `bson:"name" json:"name"`
+	Target string `bson:"target" json:"target"`
+	Weight int    `bson:"weight" json:"weight"`
+}
+
+// Click tek bir yönlendirme kaydı.
+type Click struct {
+	Code      string    `bson:"code" json:"code"`
+	Variant   string    `bson:"variant,omitempty" json:"variant,omitempty"`
+	Device    string    `bson:"device,omitempty" json:"device,omitempty"`
+	OS        string    `bson:"os,omitempty" json:"os,omitempty"`
+	Country   string    `bson:"country,omitempty" json:"country,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// TimeWindow verilen saat diliminde gün ve saat aralığı. From > To ise gece yarısını aşar.
+type TimeWindow struct {
+	Timezone string   `bson:"timezone,omitempty" json:"timezone,omitempty"`
+	Days     []string `bson:"days,omitempty" json:"days,omitempty"`
+	From     string   `bson:"from,omitempty" json:"from,omitempty"`
+	To       string   `bson:"to,omitempty" json:"to,omitempty"`
+}
+
+type Report struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Code         string             `bson:"code" json:"code"`
+	Reason       string             `bson:"reason" json:"reason"`
+	Details      string             `bson:"details,omitempty" json:"details,omitempty"`
+	ReporterHash string             `bson:"reporter_hash" json:"-"`
+	Status       string             `bson:"status" json:"status"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	ResolvedAt   *time.Time         `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
 }
 
 type Settings struct {
 	TtlTime      int16 `bson:"ttl_time" json:"ttl_time"`
 	RedisTtlTime int16 `bson:"redis_ttl" json:"redis_ttl"`
+	// Farklı kişilerden bu kadar bekleyen şikayet gelirse link otomatik kapatılır. 0 → kapalı.
+	ReportThreshold int16 `bson:"report_threshold" json:"report_threshold"`
+	// Sahibi olmayan (anonim) veya şikayet almış linklerde yönlendirme öncesi uyarı sayfası.
+	InterstitialAnonymous bool `bson:"interstitial_anonymous" json:"interstitial_anonymous"`
+	InterstitialFlagged   bool `bson:"interstitial_flagged" json:"interstitial_flagged"`
+	// Yayına girmemiş linkler buraya yönlendirilir; boşsa "yakında" sayfası gösterilir.
+	ComingSoonURL string `bson:"coming_soon_url" json:"coming_soon_url"`
+	// Link bazında redirect_type verilmemişse kullanılır; 0 → 302.
+	DefaultRedirectType int16 `bson:"default_redirect_type" json:"default_redirect_type"`
+	// Kalıcı (301/308) ve her istekte aynı sonucu veren linklerde tarayıcı cache süresi (dakika). 0 → 60.
+	RedirectCacheTtl int16 `bson:"redirect_cache_ttl" json:"redirect_cache_ttl"`
+	// Yönlendirmelerde gönderilen Referrer-Policy; boşsa strict-origin-when-cross-origin.
+	ReferrerPolicy string `bson:"referrer_policy" json:"referrer_policy"`
+	// Linkler en fazla bu sıklıkla (dakika) kontrol edilir; 0 → sağlık kontrolü kapalı.
+	HealthCheckInterval int16 `bson:"health_check_interval" json:"health_check_interval"`
+	// Art arda bu kadar başarısız kontrol sonrası link degraded olur; 0 → 3.
+	HealthFailThreshold int16 `bson:"health_fail_threshold" json:"health_fail_threshold"`
+	// Hız limitleri; 0 → varsayılan, negatif → limit kapalı.
+	// API dakikada, yönlendirme saniyede, şikayet saatte istek sayısıdır.
+	RateApiAnonymous     int16 `bson:"rate_api_anonymous" json:"rate_api_anonymous"`
+	RateApiAuthenticated int16 `bson:"rate_api_authenticated" json:"rate_api_authenticated"`
+	RateRedirect         int16 `bson:"rate_redirect" json:"rate_redirect"`
+	RateReport           int16 `bson:"rate_report" json:"rate_report"`
+	// Anonim kısaltmada istenen proof-of-work zorluğu (bit); 0 → kapalı.
+	PowDifficulty int16 `bson:"pow_difficulty" json:"pow_difficulty"`
+	// Yük altında zorluğun çıkabileceği üst sınır; 0 → PowDifficulty + 6.
+	PowMaxDifficulty int16 `bson:"pow_max_difficulty" json:"pow_max_difficulty"`
+	// Dakikada bu kadar challenge verilince zorluk artmaya başlar; 0 → 60, negatif → artmaz.
+	PowLoadThreshold int16 `bson:"pow_load_threshold" json:"pow_load_threshold"`
 }
 
 func (s Settings) IsZero() bool {
@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AccessTokenRepo struct {
+	tokenCollection *mongo.Collection
+}
+
+func NewAccessTokenRepo(db *mongo.Database) *AccessTokenRepo {
+	return &AccessTokenRepo{tokenCollection: db.Collection(repo.COLLECTION_ACCESS_TOKENS)}
+}
+
+func (r *AccessTokenRepo) Insert(ctx context.Context, t repo.AccessToken) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	_, err := r.tokenCollection.InsertOne(ctx, t)
+	if mongo.IsDuplicateKeyError(err) {
+		return repo.ErrDuplicate
+	}
+	return err
+}
+
+func (r *AccessTokenRepo) GetByHash(ctx context.Context, tokenHash string) (*repo.AccessToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var out repo.AccessToken
+	err := r.tokenCollection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *AccessTokenRepo) ListByUser(ctx context.Context, userID int64) ([]repo.AccessToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	cur, err := r.tokenCollection.Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	out := []repo.AccessToken{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *AccessTokenRepo) CountActive(ctx context.Context, userID int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return r.tokenCollection.CountDocuments(ctx, bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now().UTC()},
+	})
+}
+
+func (r *AccessTokenRepo) Revoke(ctx context.Context, userID int64, id string, purgeAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	now := time.Now().UTC()
+	res, err := r.tokenCollection.UpdateOne(ctx,
+		bson.M{"_id": id, "user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now}, "$min": bson.M{"purge_at": purgeAt}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (r *AccessTokenRepo) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	_, err := r.tokenCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$max": bson.M{"last_used_at": at}})
+	return err
+}
@@ -16,6 +16,9 @@ type URLRepo struct {
 	urlCollection      *mongo.Collection
 	seqCollection      *mongo.Collection
 	settingsCollection *mongo.Collection
+	clickCollection    *mongo.Collection
+	ownerCollection    *mongo.Collection
+	usageCollection    *mongo.Collection
 }
 
 func NewURLRepo(db *mongo.Database) *URLRepo {
@@ -23,6 +26,9 @@ func NewURLRepo(db *mongo.Database) *URLRepo {
 		urlCollection:      db.Collection(repo.COLLECTION_URLS),
 		seqCollection:      db.Collection(repo.COLLECTION_SEQUENCE),
 		settingsCollection: db.Collection(repo.COLLECTION_SETTINGS),
+		clickCollection:    db.Collection(repo.COLLECTION_CLICKS),
+		ownerCollection:    db.Collection(repo.COLLECTION_OWNERS),
+		usageCollection:    db.Collection(repo.COLLECTION_USAGE),
 	}
 }
 
@@ -73,7 +79,7 @@ func (r *URLRepo) GetCodeByUrl(url string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 	var out repo.URL
-	err := r.urlCollection.FindOne(ctx, bson.M{"target": url}).Decode(&out)
+	err := r.urlCollection.FindOne(ctx, bson.M{"target": url, "has_options": bson.M{"$ne": true}}).Decode(&out)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return "", nil
 	}
@@ -107,3 +113,289 @@ func (r *URLRepo) GetAllSettings() (*repo.Settings, error) {
 	}
 	return &settings, nil
 }
+
+func (r *URLRepo) SetDisabled(ctx context.Context, code string, disabled bool) error {
+	return r.setField(ctx, code, "disabled", disabled)
+}
+
+func (r *URLRepo) SetFlagged(ctx context.Context, code string, flagged bool) error {
+	return r.setField(ctx, code, "flagged", flagged)
+}
+
+func (r *URLRepo) SetMetadata(ctx context.Context, code string, md repo.Metadata) error {
+	return r.setField(ctx, code, "metadata", md)
+}
+
+func (r *URLRepo) SetHealth(ctx context.Context, code string, h repo.LinkHealth) error {
+	return r.setField(ctx, code, "health", h)
+}
+
+func (r *URLRepo) setField(ctx context.Context, code string, field string, value any) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	res, err := r.urlCollection.UpdateOne(ctx, bson.M{"code": code}, bson.M{"$set": bson.M{field: value}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+func (r *URLRepo) ConsumeClick(ctx context.Context, code string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	// Filtre ve $inc tek dokümanda atomik; eşzamanlı istekler limiti aşamaz.
+	filter := bson.M{
+		"code": code,
+		"$expr": bson.M{"$lt": bson.A{
+			bson.M{"$ifNull": bson.A{"$clicks", 0}},
+			"$max_clicks",
+		}},
+	}
+	res, err := r.urlCollection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"clicks": 1}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *URLRepo) SetSchedule(ctx context.Context, code string, startsAt, expiresAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	set, unset := bson.M{}, bson.M{}
+	if startsAt != nil {
+		set["starts_at"] = *startsAt
+	} else {
+		unset["starts_at"] = ""
+	}
+	if expiresAt != nil {
+		set["expires_at"] = *expiresAt
+	} else {
+		unset["expires_at"] = ""
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	res, err := r.urlCollection.UpdateOne(ctx, bson.M{"code": code}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+func (r *URLRepo) InsertClick(ctx context.Context, click repo.Click) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	_, err := r.clickCollection.InsertOne(ctx, click)
+	return err
+}
+
+func (r *URLRepo) CountClicksByVariant(ctx context.Context, code string) (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"code": code}}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"$ifNull": bson.A{"$variant", ""}}, "count": bson.M{"$sum": 1}}}},
+	}
+	cur, err := r.clickCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := map[string]int64{}
+	for cur.Next(ctx) {
+		var row struct {
+			Variant string `bson:"_id"`
+			Count   int64  `bson:"count"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		out[row.Variant] = row.Count
+	}
+	return out, cur.Err()
+}
+
+func (r *URLRepo) GetOwner(ctx context.Context, ownerID int64) (*repo.Owner, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var out repo.Owner
+	err := r.ownerCollection.FindOne(ctx, bson.M{"_id": ownerID}).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *URLRepo) SetOwnerUTM(ctx context.Context, ownerID int64, utm *repo.UTMParams) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now().UTC()}}
+	if utm != nil {
+		update["$set"].(bson.M)["utm"] = utm
+	} else {
+		update["$unset"] = bson.M{"utm": ""}
+	}
+	_, err := r.ownerCollection.UpdateOne(ctx, bson.M{"_id": ownerID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *URLRepo) ListDueForHealthCheck(ctx context.Context, afterCode string, checkedBefore time.Time, limit int64) ([]repo.URL, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	filter := bson.M{
+		"code":     bson.M{"$gt": afterCode},
+		"disabled": false,
+		// Şablon hedefler gerçek bir adres değil.
+		"template": bson.M{"$exists": false},
+		"$and": bson.A{
+			bson.M{"$or": bson.A{
+				bson.M{"health.last_checked_at": bson.M{"$lt": checkedBefore}},
+				bson.M{"health": bson.M{"$exists": false}},
+			}},
+			bson.M{"$or": bson.A{
+				bson.M{"expires_at": bson.M{"$gt": now}},
+				bson.M{"expires_at": nil},
+			}},
+		},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "code", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"code": 1, "target": 1, "owner_id": 1, "health": 1, "fallback_targets": 1})
+
+	cur, err := r.urlCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := make([]repo.URL, 0)
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *URLRepo) GetOwnerByAPIKey(ctx context.Context, keyHash string) (*repo.Owner, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var out repo.Owner
+	err := r.ownerCollection.FindOne(ctx, bson.M{"api_key_hash": keyHash}).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *URLRepo) SetOwnerAPIKey(ctx context.Context, ownerID int64, keyHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	update := bson.M{"$set": bson.M{"api_key_hash": keyHash, "updated_at": time.Now().UTC()}}
+	_, err := r.ownerCollection.UpdateOne(ctx, bson.M{"_id": ownerID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *URLRepo) SetOwnerPlan(ctx context.Context, ownerID int64, plan string, quota *repo.Quota) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"plan": plan, "updated_at": time.Now().UTC()}}
+	if quota != nil {
+		update["$set"].(bson.M)["quota"] = quota
+	} else {
+		update["$unset"] = bson.M{"quota": ""}
+	}
+	_, err := r.ownerCollection.UpdateOne(ctx, bson.M{"_id": ownerID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *URLRepo) CountCustomAliases(ctx context.Context, ownerID int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return r.urlCollection.CountDocuments(ctx, bson.M{"owner_id": ownerID, "custom_alias": bson.M{"$type": "string"}})
+}
+
+func (r *URLRepo) GetUsage(ctx context.Context, ownerID int64, period string) (*repo.Usage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var out repo.Usage
+	err := r.usageCollection.FindOne(ctx, bson.M{"owner_id": ownerID, "period": period}).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *URLRepo) SaveUsage(ctx context.Context, u repo.Usage) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	_, err := r.usageCollection.ReplaceOne(ctx, bson.M{"_id": u.ID}, u, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *URLRepo) ListUsage(ctx context.Context, period string) ([]repo.Usage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cur, err := r.usageCollection.Find(ctx, bson.M{"period": period}, options.Find().SetSort(bson.D{{Key: "owner_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := make([]repo.Usage, 0)
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *URLRepo) ListByOwner(ctx context.Context, ownerID int64, limit, offset int64) ([]repo.URL, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(limit).
+		SetSkip(offset)
+
+	cur, err := r.urlCollection.Find(ctx, bson.M{"owner_id": ownerID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := make([]repo.URL, 0)
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,126 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReportRepo struct {
+	reportCollection *mongo.Collection
+}
+
+func NewReportRepo(db *mongo.Database) *ReportRepo {
+	return &ReportRepo{reportCollection: db.Collection(repo.COLLECTION_REPORTS)}
+}
+
+func (r *ReportRepo) Insert(ctx context.Context, rep repo.Report) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	_, err := r.reportCollection.InsertOne(ctx, rep)
+	return err
+}
+
+func (r *ReportRepo) GetByID(ctx context.Context, id string) (*repo.Report, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repo.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var out repo.Report
+	err = r.reportCollection.FindOne(ctx, bson.M{"_id": oid}).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, repo.ErrNotFound
+	}
+	return &out, err
+}
+
+func (r *ReportRepo) List(ctx context.Context, status string, limit, offset int64) ([]repo.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(limit).
+		SetSkip(offset)
+
+	cur, err := r.reportCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	out := make([]repo.Report, 0)
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *ReportRepo) HasPending(ctx context.Context, code, reporterHash string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	n, err := r.reportCollection.CountDocuments(ctx, bson.M{
+		"code":          code,
+		"reporter_hash": reporterHash,
+		"status":        repo.REPORT_STATUS_PENDING,
+	}, options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+func (r *ReportRepo) CountPendingReporters(ctx context.Context, code string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	vals, err := r.reportCollection.Distinct(ctx, "reporter_hash", bson.M{
+		"code":   code,
+		"status": repo.REPORT_STATUS_PENDING,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(vals), nil
+}
+
+func (r *ReportRepo) SetStatus(ctx context.Context, id string, status string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return repo.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	res, err := r.reportCollection.UpdateOne(ctx,
+		bson.M{"_id": oid},
+		bson.M{"$set": bson.M{"status": status, "resolved_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ReportRepo) ResolvePendingByCode(ctx context.Context, code string, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	_, err := r.reportCollection.UpdateMany(ctx,
+		bson.M{"code": code, "status": repo.REPORT_STATUS_PENDING},
+		bson.M{"$set": bson.M{"status": status, "resolved_at": time.Now().UTC()}},
+	)
+	return err
+}
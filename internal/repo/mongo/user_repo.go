@@ -0,0 +1,142 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OWNER_ID_ATTEMPTS elle oluşturulmuş sahiplerle çakışan ID'ler atlanır.
+const OWNER_ID_ATTEMPTS = 10
+
+type UserRepo struct {
+	userCollection  *mongo.Collection
+	ownerCollection *mongo.Collection
+	seqCollection   *mongo.Collection
+}
+
+func NewUserRepo(db *mongo.Database) *UserRepo {
+	return &UserRepo{
+		userCollection:  db.Collection(repo.COLLECTION_USERS),
+		ownerCollection: db.Collection(repo.COLLECTION_OWNERS),
+		seqCollection:   db.Collection(repo.COLLECTION_SEQUENCE),
+	}
+}
+
+func (r *UserRepo) Insert(ctx context.Context, u *repo.User) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	id, err := reserveOwnerID(ctx, r.seqCollection, r.ownerCollection)
+	if err != nil {
+		return err
+	}
+	u.ID = id
+
+	_, err = r.userCollection.InsertOne(ctx, u)
+	if mongo.IsDuplicateKeyError(err) {
+		// e-posta kayıtlı; ayrılan sahip kaydı boşa çıktı
+		_, _ = r.ownerCollection.DeleteOne(ctx, bson.M{"_id": id})
+		return repo.ErrDuplicate
+	}
+	return err
+}
+
+// reserveOwnerID "owner" sırasından sıradaki ID'yi alır ve owners'a yazarak sahiplenir.
+// Admin'in elle verdiği sahip ID'leriyle çakışırsa bir sonrakini dener. Kullanıcılar ve workspace'ler aynı sırayı paylaşır.
+func reserveOwnerID(ctx context.Context, seqCollection, ownerCollection *mongo.Collection) (int64, error) {
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	for i := 0; i < OWNER_ID_ATTEMPTS; i++ {
+		var seq struct {
+			Seq int64 `bson:"seq"`
+		}
+		err := seqCollection.FindOneAndUpdate(ctx, bson.M{"_id": "owner"}, bson.M{"$inc": bson.M{"seq": 1}}, opts).Decode(&seq)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = ownerCollection.InsertOne(ctx, bson.M{"_id": seq.Seq, "updated_at": time.Now().UTC()})
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return seq.Seq, nil
+	}
+	return 0, errors.New("no free owner id")
+}
+
+func (r *UserRepo) GetByID(ctx context.Context, id int64) (*repo.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *UserRepo) GetByEmail(ctx context.Context, email string) (*repo.User, error) {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *UserRepo) findOne(ctx context.Context, filter bson.M) (*repo.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var out repo.User
+	err := r.userCollection.FindOne(ctx, filter).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *UserRepo) SetPassword(ctx context.Context, id int64, passwordHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	res, err := r.userCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"password_hash": passwordHash, "updated_at": time.Now().UTC()},
+		"$inc": bson.M{"session_version": 1},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepo) GetByIdentity(ctx context.Context, issuer, subject string) (*repo.User, error) {
+	return r.findOne(ctx, bson.M{"identities": bson.M{"$elemMatch": bson.M{"issuer": issuer, "subject": subject}}})
+}
+
+func (r *UserRepo) AddIdentity(ctx context.Context, id int64, identity repo.Identity) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	// aynı sağlayıcıdan eski bir kimlik varsa (IdP'de hesap yeniden oluşturulmuş) yenisiyle değiştirilir
+	_, err := r.userCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$pull": bson.M{"identities": bson.M{"issuer": identity.Issuer}},
+	})
+	if err != nil {
+		return err
+	}
+	res, err := r.userCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$push": bson.M{"identities": identity},
+		"$set":  bson.M{"updated_at": time.Now().UTC()},
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return repo.ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
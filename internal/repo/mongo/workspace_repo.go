@@ -0,0 +1,230 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WorkspaceRepo struct {
+	workspaceCollection  *mongo.Collection
+	memberCollection     *mongo.Collection
+	invitationCollection *mongo.Collection
+	ownerCollection      *mongo.Collection
+	seqCollection        *mongo.Collection
+}
+
+func NewWorkspaceRepo(db *mongo.Database) *WorkspaceRepo {
+	return &WorkspaceRepo{
+		workspaceCollection:  db.Collection(repo.COLLECTION_WORKSPACES),
+		memberCollection:     db.Collection(repo.COLLECTION_MEMBERSHIPS),
+		invitationCollection: db.Collection(repo.COLLECTION_INVITATIONS),
+		ownerCollection:      db.Collection(repo.COLLECTION_OWNERS),
+		seqCollection:        db.Collection(repo.COLLECTION_SEQUENCE),
+	}
+}
+
+func membershipID(workspaceID, userID int64) string {
+	return fmt.Sprintf("%d:%d", workspaceID, userID)
+}
+
+func (r *WorkspaceRepo) Create(ctx context.Context, w *repo.Workspace, owner repo.Membership) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	id, err := reserveOwnerID(ctx, r.seqCollection, r.ownerCollection)
+	if err != nil {
+		return err
+	}
+	w.ID = id
+	if _, err := r.workspaceCollection.InsertOne(ctx, w); err != nil {
+		return err
+	}
+
+	owner.WorkspaceID = id
+	owner.ID = membershipID(id, owner.UserID)
+	_, err = r.memberCollection.InsertOne(ctx, owner)
+	return err
+}
+
+func (r *WorkspaceRepo) Get(ctx context.Context, id int64) (*repo.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var out repo.Workspace
+	err := r.workspaceCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *WorkspaceRepo) GetMany(ctx context.Context, ids []int64) ([]repo.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	out := []repo.Workspace{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	cur, err := r.workspaceCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *WorkspaceRepo) Rename(ctx context.Context, id int64, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	res, err := r.workspaceCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"name": name, "updated_at": time.Now().UTC()},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WorkspaceRepo) GetMember(ctx context.Context, workspaceID, userID int64) (*repo.Membership, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var out repo.Membership
+	err := r.memberCollection.FindOne(ctx, bson.M{"_id": membershipID(workspaceID, userID)}).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *WorkspaceRepo) ListMembers(ctx context.Context, workspaceID int64) ([]repo.Membership, error) {
+	return r.findMembers(ctx, bson.M{"workspace_id": workspaceID})
+}
+
+func (r *WorkspaceRepo) ListMemberships(ctx context.Context, userID int64) ([]repo.Membership, error) {
+	return r.findMembers(ctx, bson.M{"user_id": userID})
+}
+
+func (r *WorkspaceRepo) findMembers(ctx context.Context, filter bson.M) ([]repo.Membership, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	cur, err := r.memberCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	out := []repo.Membership{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *WorkspaceRepo) PutMember(ctx context.Context, m repo.Membership) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	now := time.Now().UTC()
+	_, err := r.memberCollection.UpdateOne(ctx,
+		bson.M{"_id": membershipID(m.WorkspaceID, m.UserID)},
+		bson.M{
+			"$set": bson.M{"role": m.Role, "email": m.Email, "updated_at": now},
+			"$setOnInsert": bson.M{
+				"workspace_id": m.WorkspaceID,
+				"user_id":      m.UserID,
+				"created_at":   now,
+			},
+		},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *WorkspaceRepo) RemoveMember(ctx context.Context, workspaceID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	res, err := r.memberCollection.DeleteOne(ctx, bson.M{"_id": membershipID(workspaceID, userID)})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WorkspaceRepo) CountMembers(ctx context.Context, workspaceID int64, role string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	filter := bson.M{"workspace_id": workspaceID}
+	if role != "" {
+		filter["role"] = role
+	}
+	return r.memberCollection.CountDocuments(ctx, filter)
+}
+
+func (r *WorkspaceRepo) CreateInvitation(ctx context.Context, inv repo.Invitation) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	_, err := r.invitationCollection.InsertOne(ctx, inv)
+	return err
+}
+
+func (r *WorkspaceRepo) GetInvitationByToken(ctx context.Context, tokenHash string) (*repo.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	var out repo.Invitation
+	// TTL index silmeyi dakikada bir yapar; süresi geçmiş ama henüz silinmemiş davetler de elenir
+	err := r.invitationCollection.FindOne(ctx, bson.M{
+		"token_hash": tokenHash,
+		"expires_at": bson.M{"$gt": time.Now().UTC()},
+	}).Decode(&out)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *WorkspaceRepo) ListInvitations(ctx context.Context, workspaceID int64) ([]repo.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	cur, err := r.invitationCollection.Find(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"expires_at":   bson.M{"$gt": time.Now().UTC()},
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	out := []repo.Invitation{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *WorkspaceRepo) DeleteInvitation(ctx context.Context, workspaceID int64, id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	res, err := r.invitationCollection.DeleteOne(ctx, bson.M{"_id": id, "workspace_id": workspaceID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
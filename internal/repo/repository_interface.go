@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 )
 
 type Repository interface {
@@ -10,4 +11,97 @@ type Repository interface {
 	FindOneAndUpdate(ctx context.Context) (uint64, error)
 	GetCodeByUrl(urlKey string) (string, error)
 	GetAllSettings() (*Settings, error)
+	SetDisabled(ctx context.Context, code string, disabled bool) error
+	SetFlagged(ctx context.Context, code string, flagged bool) error
+	// ConsumeClick limit dolmadıysa tıklamayı atomik olarak sayar ve true döner.
+	ConsumeClick(ctx context.Context, code string) (bool, error)
+	// SetSchedule nil verilen sınırı kaldırır.
+	SetSchedule(ctx context.Context, code string, startsAt, expiresAt *time.Time) error
+	InsertClick(ctx context.Context, click Click) error
+	// CountClicksByVariant varyantsız tıklamalar "" anahtarında döner.
+	CountClicksByVariant(ctx context.Context, code string) (map[string]int64, error)
+	// GetOwner sahip kaydı yoksa nil, nil döner.
+	GetOwner(ctx context.Context, ownerID int64) (*Owner, error)
+	// SetOwnerUTM nil verilirse sahibin UTM şablonunu kaldırır.
+	SetOwnerUTM(ctx context.Context, ownerID int64, utm *UTMParams) error
+	SetMetadata(ctx context.Context, code string, md Metadata) error
+	// ListDueForHealthCheck afterCode'dan sonraki, en son checkedBefore'dan önce kontrol edilmiş
+	// (veya hiç edilmemiş) aktif linkleri kod sırasıyla döner.
+	ListDueForHealthCheck(ctx context.Context, afterCode string, checkedBefore time.Time, limit int64) ([]URL, error)
+	SetHealth(ctx context.Context, code string, h LinkHealth) error
+	// GetOwnerByAPIKey anahtar hash'i ile sahibi bulur; yoksa nil, nil.
+	GetOwnerByAPIKey(ctx context.Context, keyHash string) (*Owner, error)
+	SetOwnerAPIKey(ctx context.Context, ownerID int64, keyHash string) error
+	// SetOwnerPlan quota nil ise sahibe özel limitleri kaldırır.
+	SetOwnerPlan(ctx context.Context, ownerID int64, plan string, quota *Quota) error
+	CountCustomAliases(ctx context.Context, ownerID int64) (int64, error)
+	// GetUsage kayıt yoksa nil, nil döner.
+	GetUsage(ctx context.Context, ownerID int64, period string) (*Usage, error)
+	SaveUsage(ctx context.Context, u Usage) error
+	ListUsage(ctx context.Context, period string) ([]Usage, error)
+	// ListByOwner sahibin linklerini yeniden eskiye döner.
+	ListByOwner(ctx context.Context, ownerID int64, limit, offset int64) ([]URL, error)
+}
+
+type UserRepository interface {
+	// Insert kullanıcıya owners koleksiyonunda yeni bir sahip ID'si ayırır ve u.ID'ye yazar.
+	// E-posta kayıtlıysa ErrDuplicate döner.
+	Insert(ctx context.Context, u *User) error
+	// GetByID / GetByEmail kullanıcı yoksa nil, nil döner.
+	GetByID(ctx context.Context, id int64) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	// SetPassword şifreyi değiştirir ve SessionVersion'ı artırarak eski oturumları geçersiz kılar.
+	SetPassword(ctx context.Context, id int64, passwordHash string) error
+	// GetByIdentity SSO kimliğiyle bağlı kullanıcıyı döner; yoksa nil, nil.
+	GetByIdentity(ctx context.Context, issuer, subject string) (*User, error)
+	// AddIdentity kimliği kullanıcıya bağlar; kimlik başka bir kullanıcıya bağlıysa ErrDuplicate döner.
+	AddIdentity(ctx context.Context, id int64, identity Identity) error
+}
+
+type AccessTokenRepository interface {
+	Insert(ctx context.Context, t AccessToken) error
+	// GetByHash token'ı hash'iyle bulur; yoksa nil, nil. Süre ve iptal kontrolü çağırandadır.
+	GetByHash(ctx context.Context, tokenHash string) (*AccessToken, error)
+	// ListByUser en yeniden eskiye.
+	ListByUser(ctx context.Context, userID int64) ([]AccessToken, error)
+	// CountActive iptal edilmemiş ve süresi dolmamış token sayısı.
+	CountActive(ctx context.Context, userID int64) (int64, error)
+	// Revoke token'ı iptal eder; token yoksa, başkasınınsa ya da zaten iptalse false döner.
+	Revoke(ctx context.Context, userID int64, id string, purgeAt time.Time) (bool, error)
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
+
+type ReportRepository interface {
+	Insert(ctx context.Context, r Report) error
+	GetByID(ctx context.Context, id string) (*Report, error)
+	List(ctx context.Context, status string, limit, offset int64) ([]Report, error)
+	HasPending(ctx context.Context, code, reporterHash string) (bool, error)
+	CountPendingReporters(ctx context.Context, code string) (int, error)
+	SetStatus(ctx context.Context, id string, status string) error
+	ResolvePendingByCode(ctx context.Context, code string, status string) error
+}
+
+type WorkspaceRepository interface {
+	// Create workspace'e owners koleksiyonunda yeni bir sahip ID'si ayırır, w.ID'ye yazar ve kurucuyu üye olarak ekler.
+	Create(ctx context.Context, w *Workspace, owner Membership) error
+	// Get / GetMember kayıt yoksa nil, nil döner.
+	Get(ctx context.Context, id int64) (*Workspace, error)
+	GetMany(ctx context.Context, ids []int64) ([]Workspace, error)
+	Rename(ctx context.Context, id int64, name string) error
+
+	GetMember(ctx context.Context, workspaceID, userID int64) (*Membership, error)
+	ListMembers(ctx context.Context, workspaceID int64) ([]Membership, error)
+	// ListMemberships kullanıcının bütün üyelikleri.
+	ListMemberships(ctx context.Context, userID int64) ([]Membership, error)
+	// PutMember üyeyi ekler ya da rolünü değiştirir.
+	PutMember(ctx context.Context, m Membership) error
+	RemoveMember(ctx context.Context, workspaceID, userID int64) error
+	CountMembers(ctx context.Context, workspaceID int64, role string) (int64, error)
+
+	CreateInvitation(ctx context.Context, inv Invitation) error
+	// GetInvitationByToken süresi dolmuş ya da olmayan davet için nil, nil döner.
+	GetInvitationByToken(ctx context.Context, tokenHash string) (*Invitation, error)
+	ListInvitations(ctx context.Context, workspaceID int64) ([]Invitation, error)
+	// DeleteInvitation silinecek davet yoksa false döner; aynı davetle eşzamanlı kabullerden sadece biri geçer.
+	DeleteInvitation(ctx context.Context, workspaceID int64, id string) (bool, error)
 }
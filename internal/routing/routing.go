@@ -0,0 +1,249 @@
+package routing
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+	"github.com/emrealsandev/Url-Shortener/pkg/useragent"
+)
+
+var ErrInvalidRule = errors.New("invalid_rule")
+
+const MAX_RULES = 20
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Attributes kural eşleştirmede kullanılan istek bilgileri.
+type Attributes struct {
+	Agent     useragent.Info
+	Languages []string
+	Country   string
+	Query     map[string]string
+	Now       time.Time
+}
+
+// Select eşleşen ilk kuralın hedefini döner.
+func Select(rules []repo.RoutingRule, attrs Attributes) (string, bool) {
+	for _, r := range rules {
+		if Match(r, attrs) {
+			return r.Target, true
+		}
+	}
+	return "", false
+}
+
+// NeedsCountry GeoIP sorgusu pahalı olmasa da gereksizse hiç yapılmasın diye.
+func NeedsCountry(rules []repo.RoutingRule) bool {
+	for _, r := range rules {
+		if len(r.Countries) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func Match(r repo.RoutingRule, a Attributes) bool {
+	if len(r.Devices) > 0 && !contains(r.Devices, a.Agent.Device) {
+		return false
+	}
+	if len(r.OS) > 0 && !contains(r.OS, a.Agent.OS) {
+		return false
+	}
+	if len(r.Languages) > 0 && !matchLanguage(r.Languages, a.Languages) {
+		return false
+	}
+	if len(r.Countries) > 0 && (a.Country == "" || !contains(r.Countries, a.Country)) {
+		return false
+	}
+	if r.Time != nil && !matchTime(*r.Time, a.Now) {
+		return false
+	}
+	for k, v := range r.Query {
+		got, ok := a.Query[k]
+		// Boş değer "parametre var" anlamına gelir.
+		if !ok || (v != "" && got != v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate kuralları normalize eder (hedef URL, küçük/büyük harf) ve geçersizse hata döner.
+func Validate(rules []repo.RoutingRule) ([]repo.RoutingRule, error) {
+	if len(rules) > MAX_RULES {
+		return nil, ErrInvalidRule
+	}
+
+	out := make([]repo.RoutingRule, 0, len(rules))
+	for _, r := range rules {
+		target, err := security.NormalizeUrl(r.Target)
+		if err != nil {
+			return nil, ErrInvalidRule
+		}
+		r.Target = target
+		r.Devices = lower(r.Devices)
+		r.OS = lower(r.OS)
+		r.Languages = lower(r.Languages)
+		r.Countries = upper(r.Countries)
+
+		if r.Time != nil {
+			tw := *r.Time
+			if _, err := time.LoadLocation(tw.Timezone); err != nil {
+				return nil, ErrInvalidRule
+			}
+			tw.Days = lower(tw.Days)
+			for _, d := range tw.Days {
+				if _, ok := weekdays[d]; !ok {
+					return nil, ErrInvalidRule
+				}
+			}
+			if _, ok := parseClock(tw.From); tw.From != "" && !ok {
+				return nil, ErrInvalidRule
+			}
+			if _, ok := parseClock(tw.To); tw.To != "" && !ok {
+				return nil, ErrInvalidRule
+			}
+			r.Time = &tw
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// ParseAcceptLanguage tercih sırasına göre (q değerine göre azalan) küçük harfli dil etiketlerini döner.
+func ParseAcceptLanguage(header string) []string {
+	type lang struct {
+		tag string
+		q   float64
+	}
+
+	var langs []lang
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		langs = append(langs, lang{tag: tag, q: q})
+	}
+
+	// stable insertion sort; liste kısa
+	for i := 1; i < len(langs); i++ {
+		for j := i; j > 0 && langs[j].q > langs[j-1].q; j-- {
+			langs[j], langs[j-1] = langs[j-1], langs[j]
+		}
+	}
+
+	out := make([]string, 0, len(langs))
+	for _, l := range langs {
+		out = append(out, l.tag)
+	}
+	return out
+}
+
+// matchLanguage ziyaretçinin en çok tercih ettiği dile bakar; "en" kuralı "en-us" ile de eşleşir.
+func matchLanguage(ruleLangs, visitor []string) bool {
+	if len(visitor) == 0 {
+		return false
+	}
+	top := visitor[0]
+	primary, _, _ := strings.Cut(top, "-")
+	for _, l := range ruleLangs {
+		if l == top || l == primary {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTime(tw repo.TimeWindow, now time.Time) bool {
+	loc, err := time.LoadLocation(tw.Timezone)
+	if err != nil {
+		return false
+	}
+	local := now.In(loc)
+
+	if len(tw.Days) > 0 {
+		ok := false
+		for _, d := range tw.Days {
+			if weekdays[d] == local.Weekday() {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	from, hasFrom := parseClock(tw.From)
+	to, hasTo := parseClock(tw.To)
+	if !hasFrom && !hasTo {
+		return true
+	}
+	if !hasFrom {
+		from = 0
+	}
+	if !hasTo {
+		to = 24 * 60
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// parseClock "HH:MM" → gün içindeki dakika.
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func lower(list []string) []string {
+	for i := range list {
+		list[i] = strings.ToLower(strings.TrimSpace(list[i]))
+	}
+	return list
+}
+
+func upper(list []string) []string {
+	for i := range list {
+		list[i] = strings.ToUpper(strings.TrimSpace(list[i]))
+	}
+	return list
+}
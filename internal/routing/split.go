@@ -0,0 +1,69 @@
+package routing
+
+import (
+	"errors"
+	"hash/fnv"
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+)
+
+var ErrInvalidDestinations = errors.New("invalid_destinations")
+
+const MAX_DESTINATIONS = 10
+
+// Pick ağırlığa göre bir varyant seçer. visitor boş değilse seçim deterministiktir;
+// aynı ziyaretçi ve link için ağırlıklar değişmedikçe hep aynı varyant döner.
+func Pick(dests []repo.Destination, code, visitor string) repo.Destination {
+	total := 0
+	for _, d := range dests {
+		total += d.Weight
+	}
+
+	var n int
+	if visitor != "" {
+		h := fnv.New64a()
+		h.Write([]byte(code))
+		h.Write([]byte{0})
+		h.Write([]byte(visitor))
+		n = int(h.Sum64() % uint64(total))
+	} else {
+		n = rand.IntN(total)
+	}
+
+	for _, d := range dests {
+		if n < d.Weight {
+			return d
+		}
+		n -= d.Weight
+	}
+	return dests[len(dests)-1]
+}
+
+// ValidateDestinations hedefleri normalize eder, isimsiz varyantlara sıra numarası verir.
+func ValidateDestinations(dests []repo.Destination) ([]repo.Destination, error) {
+	if len(dests) > MAX_DESTINATIONS {
+		return nil, ErrInvalidDestinations
+	}
+
+	seen := map[string]struct{}{}
+	out := make([]repo.Destination, 0, len(dests))
+	for i, d := range dests {
+		target, err := security.NormalizeUrl(d.Target)
+		if err != nil || d.Weight <= 0 || d.Weight > 1000 {
+			return nil, ErrInvalidDestinations
+		}
+		d.Target = target
+		if d.Name == "" {
+			d.Name = strconv.Itoa(i + 1)
+		}
+		if _, dup := seen[d.Name]; dup {
+			return nil, ErrInvalidDestinations
+		}
+		seen[d.Name] = struct{}{}
+		out = append(out, d)
+	}
+	return out, nil
+}
@@ -0,0 +1,146 @@
+package security
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+)
+
+var ErrInvalidProxyConfig = errors.New("invalid_proxy_config")
+
+// Gerçek istemci IP'sinin okunabileceği başlıklar.
+const (
+	HEADER_X_FORWARDED_FOR = "X-Forwarded-For"
+	HEADER_X_REAL_IP       = "X-Real-IP"
+	HEADER_FORWARDED       = "Forwarded"
+)
+
+// ClientIPResolver sadece güvenilen proxy'lerden gelen isteklerde başlığa bakar. Başlık sağdan sola okunur;
+// güvenilen proxy'ler atlanır ve ilk güvenilmeyen adres istemci kabul edilir. Soldaki değerleri
+// istemci kendisi yazabileceği için en soldaki adrese güvenilmez.
+type ClientIPResolver struct {
+	trusted []netip.Prefix
+	header  string
+}
+
+// NewClientIPResolver CIDR ya da tek IP listesi alır; header boşsa X-Forwarded-For kullanılır.
+func NewClientIPResolver(trusted []string, header string) (*ClientIPResolver, error) {
+	r := &ClientIPResolver{header: HEADER_X_FORWARDED_FOR}
+	switch {
+	case header == "":
+	case strings.EqualFold(header, HEADER_X_FORWARDED_FOR):
+	case strings.EqualFold(header, HEADER_X_REAL_IP):
+		r.header = HEADER_X_REAL_IP
+	case strings.EqualFold(header, HEADER_FORWARDED):
+		r.header = HEADER_FORWARDED
+	default:
+		return nil, ErrInvalidProxyConfig
+	}
+
+	for _, t := range trusted {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !strings.Contains(t, "/") {
+			addr, err := netip.ParseAddr(t)
+			if err != nil {
+				return nil, ErrInvalidProxyConfig
+			}
+			addr = addr.Unmap()
+			r.trusted = append(r.trusted, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(t)
+		if err != nil {
+			return nil, ErrInvalidProxyConfig
+		}
+		if p.Addr().Is4In6() {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		}
+		r.trusted = append(r.trusted, p.Masked())
+	}
+	return r, nil
+}
+
+// Resolve remote bağlantının karşı ucu, get istek başlığını okur.
+func (r *ClientIPResolver) Resolve(remote string, get func(string) string) string {
+	if r == nil || !r.isTrusted(remote) {
+		return remote
+	}
+	value := get(r.header)
+	if value == "" {
+		return remote
+	}
+
+	var hops []string
+	switch r.header {
+	case HEADER_X_REAL_IP:
+		hops = []string{value}
+	case HEADER_FORWARDED:
+		hops = forwardedFor(value)
+	default:
+		hops = strings.Split(value, ",")
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHop(hops[i])
+		if !ok {
+			// Bozuk veya gizlenmiş ("unknown", "_hidden") bir adımdan sonrasına güvenilemez.
+			break
+		}
+		client = addr.String()
+		if !r.contains(addr) {
+			break
+		}
+	}
+	return client
+}
+
+func (r *ClientIPResolver) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	return err == nil && r.contains(addr.Unmap())
+}
+
+func (r *ClientIPResolver) contains(addr netip.Addr) bool {
+	for _, p := range r.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor RFC 7239 Forwarded başlığındaki for= değerlerini sırasıyla döner.
+func forwardedFor(value string) []string {
+	var out []string
+	for _, element := range strings.Split(value, ",") {
+		for _, pair := range strings.Split(element, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(k, "for") {
+				out = append(out, strings.Trim(v, `"`))
+			}
+		}
+	}
+	return out
+}
+
+// parseHop "1.2.3.4", "1.2.3.4:80", "[2001:db8::1]:80" ve "2001:db8::1" biçimlerini kabul eder.
+func parseHop(hop string) (netip.Addr, bool) {
+	hop = strings.TrimSpace(hop)
+	if strings.HasPrefix(hop, "[") {
+		end := strings.Index(hop, "]")
+		if end < 0 {
+			return netip.Addr{}, false
+		}
+		hop = hop[1:end]
+	} else if strings.Count(hop, ":") == 1 {
+		hop, _, _ = strings.Cut(hop, ":")
+	}
+	addr, err := netip.ParseAddr(hop)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,58 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer kısa ömürlü, payload'a bağlı HMAC token'ları üretir ve doğrular.
+// Token formatı: base64url(exp) + "." + base64url(hmac(payload|exp))
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret string) *Signer {
+	key := []byte(secret)
+	if len(key) == 0 {
+		// Secret yoksa process'e özel rastgele anahtar: restart ve replikalar arası geçersiz olur.
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &Signer{secret: key}
+}
+
+func (s *Signer) Sign(payload string, ttl time.Duration) string {
+	exp := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(exp)) + "." + s.mac(payload, exp)
+}
+
+func (s *Signer) Verify(token, payload string) bool {
+	expPart, macPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	expBytes, err := base64.RawURLEncoding.DecodeString(expPart)
+	if err != nil {
+		return false
+	}
+	exp, err := strconv.ParseInt(string(expBytes), 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return false
+	}
+
+	return hmac.Equal([]byte(macPart), []byte(s.mac(payload, string(expBytes))))
+}
+
+func (s *Signer) mac(payload, exp string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	h.Write([]byte("|"))
+	h.Write([]byte(exp))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
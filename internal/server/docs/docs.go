@@ -40,6 +40,10 @@ const swaggerJSON = `{
     "/v1/shorten": {
       "post": {
         "summary": "Shorten a URL",
+        "parameters": [
+          { "name": "X-PoW-Challenge", "in": "header", "required": false, "schema": { "type": "string" } },
+          { "name": "X-PoW-Solution", "in": "header", "required": false, "schema": { "type": "string" } }
+        ],
         "requestBody": {
           "required": true,
           "content": {
@@ -53,12 +57,545 @@ const swaggerJSON = `{
             "description": "Shortened successfully",
             "content": { "application/json": { "schema": { "$ref": "#/components/schemas/ShortenResponse" } } }
           },
-          "400": { "description": "invalid_url or bad_request" },
+          "400": { "description": "invalid_url, invalid_alias or bad_request" },
+          "401": { "description": "invalid_api_key" },
+          "403": { "description": "pow_required or invalid_pow (anonymous requests while proof-of-work is on)" },
+          "503": { "description": "pow_unavailable (proof-of-work reuse check is unavailable)" },
+          "402": { "description": "Custom alias quota of the plan is used up", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/QuotaError" } } } },
           "409": { "description": "conflict (custom alias already exists)" },
+          "429": { "description": "Monthly link quota is used up (Retry-After until the next month) or rate_limited", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/QuotaError" } } } },
           "500": { "description": "internal" }
         }
       }
     },
+    "/v1/auth/register": {
+      "post": {
+        "summary": "Create an account and sign in",
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Credentials" } } } },
+        "responses": {
+          "201": { "description": "Signed in; sets the sid cookie", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/SessionResponse" } } } },
+          "400": { "description": "invalid_email, invalid_password or bad_request" },
+          "409": { "description": "email_taken" }
+        }
+      }
+    },
+    "/v1/auth/login": {
+      "post": {
+        "summary": "Sign in",
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Credentials" } } } },
+        "responses": {
+          "200": { "description": "Signed in; sets the sid cookie", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/SessionResponse" } } } },
+          "401": { "description": "invalid_credentials" },
+          "429": { "description": "too_many_attempts" }
+        }
+      }
+    },
+    "/v1/auth/logout": {
+      "post": {
+        "summary": "Sign out (X-CSRF-Token required)",
+        "responses": { "204": { "description": "Signed out" }, "403": { "description": "invalid_csrf" } }
+      }
+    },
+    "/v1/auth/me": {
+      "get": {
+        "summary": "Current user and CSRF token",
+        "responses": {
+          "200": { "description": "Signed in", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/SessionResponse" } } } },
+          "401": { "description": "login_required" }
+        }
+      }
+    },
+    "/v1/auth/password/forgot": {
+      "post": {
+        "summary": "Email a password reset link",
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "type": "object", "properties": { "email": { "type": "string", "format": "email" } } } } } },
+        "responses": { "202": { "description": "Always accepted, whether or not the account exists" } }
+      }
+    },
+    "/v1/auth/password/reset": {
+      "post": {
+        "summary": "Set a new password with a reset token; signs out every session",
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "type": "object", "properties": { "token": { "type": "string" }, "password": { "type": "string", "minLength": 8, "maxLength": 72 } } } } } },
+        "responses": { "204": { "description": "Password changed" }, "400": { "description": "invalid_token or invalid_password" } }
+      }
+    },
+    "/v1/auth/providers": {
+      "get": {
+        "summary": "Sign-in methods the web UI should offer",
+        "responses": { "200": { "description": "OK", "content": { "application/json": { "schema": { "type": "object", "properties": { "password": { "type": "boolean" }, "oidc": { "type": "boolean" } } } } } } }
+      }
+    },
+    "/v1/auth/oidc/login": {
+      "get": {
+        "summary": "Start OpenID Connect sign-in (authorization code + PKCE)",
+        "responses": {
+          "302": { "description": "Redirect to the identity provider; sets the oidc_state cookie" },
+          "404": { "description": "sso_disabled" },
+          "502": { "description": "sso_unavailable" }
+        }
+      }
+    },
+    "/v1/auth/oidc/callback": {
+      "get": {
+        "summary": "OpenID Connect redirect URI",
+        "parameters": [
+          { "name": "code", "in": "query", "schema": { "type": "string" } },
+          { "name": "state", "in": "query", "schema": { "type": "string" } },
+          { "name": "error", "in": "query", "schema": { "type": "string" } }
+        ],
+        "responses": { "302": { "description": "To /account with a session cookie, or to /account?sso_error=<code>" } }
+      }
+    },
+    "/v1/me/links": {
+      "get": {
+        "summary": "Links of the signed-in user, access token user or API key owner",
+        "parameters": [
+          { "name": "limit", "in": "query", "required": false, "schema": { "type": "integer", "default": 50, "maximum": 100 } },
+          { "name": "offset", "in": "query", "required": false, "schema": { "type": "integer", "default": 0 } }
+        ],
+        "responses": { "200": { "description": "Newest first" }, "401": { "description": "login_required" } }
+      }
+    },
+    "/v1/me/links/{code}": {
+      "delete": {
+        "summary": "Disable one of your links",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "responses": { "204": { "description": "Disabled" }, "401": { "description": "login_required" }, "404": { "description": "Not found or not yours" } }
+      }
+    },
+    "/v1/me/links/{code}/stats": {
+      "get": {
+        "summary": "Click counts of one of your links",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } },
+          { "name": "X-Workspace-ID", "in": "header", "required": false, "schema": { "type": "integer" }, "description": "Act in this workspace instead of the personal space" }
+        ],
+        "responses": { "200": { "description": "OK" }, "401": { "description": "login_required" }, "403": { "description": "forbidden" }, "404": { "description": "Not found or not yours" } }
+      }
+    },
+    "/v1/me/settings": {
+      "get": {
+        "summary": "Settings of your personal space or the selected workspace",
+        "parameters": [ { "name": "X-Workspace-ID", "in": "header", "required": false, "schema": { "type": "integer" }, "description": "Act in this workspace instead of the personal space" } ],
+        "responses": { "200": { "description": "OK", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/OwnerSettings" } } } }, "401": { "description": "login_required" }, "403": { "description": "forbidden" } }
+      },
+      "put": {
+        "summary": "Replace the settings; utm null removes the template",
+        "parameters": [ { "name": "X-Workspace-ID", "in": "header", "required": false, "schema": { "type": "integer" }, "description": "Act in this workspace instead of the personal space" } ],
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "type": "object", "properties": { "utm": { "$ref": "#/components/schemas/UTMParams" } } } } } },
+        "responses": { "200": { "description": "Updated", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/OwnerSettings" } } } }, "400": { "description": "invalid_utm" }, "401": { "description": "login_required" }, "403": { "description": "forbidden" } }
+      }
+    },
+    "/v1/tokens": {
+      "get": {
+        "summary": "Your personal access tokens (session only)",
+        "responses": { "200": { "description": "Newest first; revoked and expired tokens stay listed for 30 days", "content": { "application/json": { "schema": { "type": "object", "properties": { "tokens": { "type": "array", "items": { "$ref": "#/components/schemas/AccessToken" } } } } } } }, "401": { "description": "login_required" }, "403": { "description": "session_required" } }
+      },
+      "post": {
+        "summary": "Create a personal access token; the token is returned only once",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "object",
+                "required": ["name", "scopes"],
+                "properties": {
+                  "name": { "type": "string", "maxLength": 100 },
+                  "scopes": { "type": "array", "minItems": 1, "items": { "$ref": "#/components/schemas/Scope" } },
+                  "expires_in_days": { "type": "integer", "minimum": 1, "maximum": 365, "default": 30 }
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": { "description": "Created", "content": { "application/json": { "schema": { "allOf": [ { "$ref": "#/components/schemas/AccessToken" }, { "type": "object", "properties": { "token": { "type": "string", "example": "pat_..." } } } ] } } } },
+          "400": { "description": "invalid_name, invalid_scope or invalid_expiry" },
+          "401": { "description": "login_required" },
+          "403": { "description": "session_required" },
+          "409": { "description": "too_many_tokens" }
+        }
+      }
+    },
+    "/v1/tokens/{id}": {
+      "delete": {
+        "summary": "Revoke a personal access token",
+        "parameters": [ { "name": "id", "in": "path", "required": true, "schema": { "type": "string" } } ],
+        "responses": { "204": { "description": "Revoked" }, "401": { "description": "login_required" }, "403": { "description": "session_required" }, "404": { "description": "Unknown, not yours or already revoked" } }
+      }
+    },
+    "/v1/workspaces": {
+      "get": {
+        "summary": "Workspaces you are a member of",
+        "responses": { "200": { "description": "OK", "content": { "application/json": { "schema": { "type": "object", "properties": { "workspaces": { "type": "array", "items": { "$ref": "#/components/schemas/Workspace" } } } } } } }, "401": { "description": "login_required" } }
+      },
+      "post": {
+        "summary": "Create a workspace; you become its owner",
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "type": "object", "properties": { "name": { "type": "string", "maxLength": 100 } } } } } },
+        "responses": { "201": { "description": "Created", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Workspace" } } } }, "400": { "description": "invalid_name" }, "401": { "description": "login_required" } }
+      }
+    },
+    "/v1/workspaces/{id}": {
+      "get": {
+        "summary": "One workspace and your role in it",
+        "parameters": [ { "name": "id", "in": "path", "required": true, "schema": { "type": "integer" } } ],
+        "responses": { "200": { "description": "OK", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Workspace" } } } }, "404": { "description": "workspace_not_found" } }
+      },
+      "patch": {
+        "summary": "Rename a workspace (owner)",
+        "parameters": [ { "name": "id", "in": "path", "required": true, "schema": { "type": "integer" } } ],
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "type": "object", "properties": { "name": { "type": "string", "maxLength": 100 } } } } } },
+        "responses": { "200": { "description": "Renamed" }, "400": { "description": "invalid_name" }, "403": { "description": "forbidden" }, "404": { "description": "workspace_not_found" } }
+      }
+    },
+    "/v1/workspaces/{id}/members": {
+      "get": {
+        "summary": "Members of a workspace",
+        "parameters": [ { "name": "id", "in": "path", "required": true, "schema": { "type": "integer" } } ],
+        "responses": { "200": { "description": "OK", "content": { "application/json": { "schema": { "type": "object", "properties": { "members": { "type": "array", "items": { "$ref": "#/components/schemas/Membership" } } } } } } }, "404": { "description": "workspace_not_found" } }
+      }
+    },
+    "/v1/workspaces/{id}/members/{user_id}": {
+      "put": {
+        "summary": "Change a member's role (owner/admin; only owners can grant or revoke owner)",
+        "parameters": [ { "name": "id", "in": "path", "required": true, "schema": { "type": "integer" } }, { "name": "user_id", "in": "path", "required": true, "schema": { "type": "integer" } } ],
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "type": "object", "properties": { "role": { "$ref": "#/components/schemas/Role" } } } } } },
+        "responses": { "200": { "description": "Updated", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Membership" } } } }, "400": { "description": "invalid_role" }, "403": { "description": "forbidden" }, "404": { "description": "Not a member" }, "409": { "description": "last_owner" } }
+      },
+      "delete": {
+        "summary": "Remove a member (owner/admin), or leave with your own user id",
+        "parameters": [ { "name": "id", "in": "path", "required": true, "schema": { "type": "integer" } }, { "name": "user_id", "in": "path", "required": true, "schema": { "type": "integer" } } ],
+        "responses": { "204": { "description": "Removed" }, "403": { "description": "forbidden" }, "404": { "description": "Not a member" }, "409": { "description": "last_owner" } }
+      }
+    },
+    "/v1/workspaces/{id}/invitations": {
+      "get": {
+        "summary": "Pending invitations (owner/admin)",
+        "parameters": [ { "name": "id", "in": "path", "required": true, "schema": { "type": "integer" } } ],
+        "responses": { "200": { "description": "OK", "content": { "application/json": { "schema": { "type": "object", "properties": { "invitations": { "type": "array", "items": { "$ref": "#/components/schemas/Invitation" } } } } } } }, "403": { "description": "forbidden" } }
+      },
+      "post": {
+        "summary": "Invite someone by email (owner/admin); the link is valid 7 days",
+        "parameters": [ { "name": "id", "in": "path", "required": true, "schema": { "type": "integer" } } ],
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "type": "object", "properties": { "email": { "type": "string", "format": "email" }, "role": { "$ref": "#/components/schemas/Role" } } } } } },
+        "responses": { "201": { "description": "Invited", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Invitation" } } } }, "400": { "description": "invalid_email or invalid_role" }, "403": { "description": "forbidden" }, "409": { "description": "already_member or too_many_invitations" } }
+      }
+    },
+    "/v1/workspaces/{id}/invitations/{invitation_id}": {
+      "delete": {
+        "summary": "Revoke an invitation (owner/admin)",
+        "parameters": [ { "name": "id", "in": "path", "required": true, "schema": { "type": "integer" } }, { "name": "invitation_id", "in": "path", "required": true, "schema": { "type": "string" } } ],
+        "responses": { "204": { "description": "Revoked" }, "403": { "description": "forbidden" }, "404": { "description": "Not found" } }
+      }
+    },
+    "/v1/invitations/accept": {
+      "post": {
+        "summary": "Join a workspace with the token from an invitation email",
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "type": "object", "properties": { "token": { "type": "string" } } } } } },
+        "responses": { "200": { "description": "Joined", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Workspace" } } } }, "400": { "description": "invalid_invitation" }, "401": { "description": "login_required" }, "403": { "description": "invitation_email_mismatch" }, "409": { "description": "already_member" } }
+      }
+    },
+    "/v1/challenge": {
+      "get": {
+        "summary": "Issue a proof-of-work challenge for anonymous shortening",
+        "responses": {
+          "200": { "description": "A new challenge, or required=false when proof-of-work is off", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Challenge" } } } }
+        }
+      }
+    },
+    "/v1/shorten/batch": {
+      "post": {
+        "summary": "Shorten several URLs at once (X-API-Key required)",
+        "parameters": [
+          { "name": "X-API-Key", "in": "header", "required": true, "schema": { "type": "string" } }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "object",
+                "properties": {
+                  "items": { "type": "array", "items": { "$ref": "#/components/schemas/ShortenRequest" } }
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": { "description": "One result per item, in order; failed items carry error instead of code" },
+          "400": { "description": "bad_request" },
+          "401": { "description": "api_key_required or invalid_api_key" },
+          "402": { "description": "More items than the plan's max_batch_size", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/QuotaError" } } } }
+        }
+      }
+    },
+    "/v1/usage": {
+      "get": {
+        "summary": "Current month's usage and limits of the API key's owner, the signed-in user or the selected workspace",
+        "parameters": [
+          { "name": "X-API-Key", "in": "header", "required": false, "schema": { "type": "string" } },
+          { "name": "X-Workspace-ID", "in": "header", "required": false, "schema": { "type": "integer" }, "description": "Act in this workspace instead of the personal space" }
+        ],
+        "responses": {
+          "200": { "description": "Usage report", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/UsageReport" } } } },
+          "401": { "description": "api_key_required or invalid_api_key" },
+          "403": { "description": "forbidden" }
+        }
+      }
+    },
+    "/v1/links/{code}/schedule": {
+      "put": {
+        "summary": "Reschedule a link's activation window",
+        "description": "ADMIN_TOKEN bearer for any link; otherwise the owner's (or X-Workspace-ID workspace's) own links, subject to role and token scope",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "object",
+                "properties": {
+                  "starts_at": { "type": "string", "format": "date-time", "nullable": true },
+                  "expires_at": { "type": "string", "format": "date-time", "nullable": true }
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": { "description": "Rescheduled, cache invalidated" },
+          "400": { "description": "invalid_schedule or bad_request" },
+          "401": { "description": "login_required" },
+          "403": { "description": "forbidden or insufficient_scope" },
+          "404": { "description": "Not found, or someone else's link" }
+        }
+      }
+    },
+    "/v1/links/{code}/stats": {
+      "get": {
+        "summary": "Click counts per A/B variant",
+        "description": "ADMIN_TOKEN bearer for any link; otherwise the owner's (or X-Workspace-ID workspace's) own links, subject to role and token scope",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "responses": {
+          "200": {
+            "description": "Stats",
+            "content": { "application/json": { "example": { "code": "abc123", "total": 120, "variants": { "a": 58, "b": 62 } } } }
+          },
+          "401": { "description": "login_required" },
+          "403": { "description": "forbidden or insufficient_scope" },
+          "404": { "description": "Not found, or someone else's link" }
+        }
+      }
+    },
+    "/v1/links/{code}/health": {
+      "get": {
+        "summary": "Latest target health checks",
+        "description": "ADMIN_TOKEN bearer for any link; otherwise the owner's (or X-Workspace-ID workspace's) own links, subject to role and token scope",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "responses": {
+          "200": { "description": "Health status and the last 20 checks; status is unknown until the first check", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/LinkHealth" } } } },
+          "401": { "description": "login_required" },
+          "403": { "description": "forbidden or insufficient_scope" },
+          "404": { "description": "Not found, or someone else's link" }
+        }
+      }
+    },
+    "/v1/links/{code}/metadata/refresh": {
+      "post": {
+        "summary": "Fetch the target's title, description, OpenGraph tags and favicon again",
+        "description": "ADMIN_TOKEN bearer for any link; otherwise the owner's (or X-Workspace-ID workspace's) own links, subject to role and token scope",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "responses": {
+          "200": { "description": "Stored metadata; a failed fetch is returned with an error code", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Metadata" } } } },
+          "401": { "description": "login_required" },
+          "403": { "description": "forbidden or insufficient_scope" },
+          "404": { "description": "Not found, or a template/password-protected link" }
+        }
+      }
+    },
+    "/v1/links/{code}/preview": {
+      "get": {
+        "summary": "Where a link goes, without counting a click",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "responses": {
+          "200": {
+            "description": "Preview (target, domain and metadata are omitted for password-protected and click-capped links and before starts_at)",
+            "content": { "application/json": { "example": { "code": "abc123", "target": "https://example.com/long", "domain": "example.com", "created_at": "2026-10-01T09:00:00Z", "dynamic": false, "protected": false, "scheduled": false, "capped": false, "safety": "ok", "metadata": { "title": "Example", "favicon": "https://example.com/favicon.ico", "fetched_at": "2026-10-01T09:00:05Z" } } } }
+          },
+          "404": { "description": "Not found, disabled or expired" },
+          "410": { "description": "Click-capped link has been used up" }
+        }
+      }
+    },
+    "/{code}+": {
+      "get": {
+        "summary": "HTML preview page for a link, without counting a click",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "responses": {
+          "200": { "description": "Preview page", "content": { "text/html": {} } },
+          "404": { "description": "Not found, disabled or expired" },
+          "410": { "description": "Click-capped link has been used up" }
+        }
+      }
+    },
+    "/v1/report/{code}": {
+      "post": {
+        "summary": "Report a malicious short link",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": { "$ref": "#/components/schemas/ReportRequest" }
+            }
+          }
+        },
+        "responses": {
+          "202": { "description": "Report queued for moderation" },
+          "400": { "description": "invalid_reason or bad_request" },
+          "404": { "description": "Not found" },
+          "409": { "description": "already_reported (same reporter, pending report)" },
+          "429": { "description": "Too many reports" }
+        }
+      }
+    },
+    "/v1/admin/reports": {
+      "get": {
+        "summary": "List abuse reports (admin)",
+        "parameters": [
+          { "name": "status", "in": "query", "schema": { "type": "string", "enum": ["pending", "accepted", "dismissed"] } },
+          { "name": "limit", "in": "query", "schema": { "type": "integer", "default": 50 } },
+          { "name": "offset", "in": "query", "schema": { "type": "integer", "default": 0 } }
+        ],
+        "responses": {
+          "200": { "description": "Reports, newest first" },
+          "401": { "description": "unauthorized" }
+        }
+      }
+    },
+    "/v1/admin/reports/{id}/accept": {
+      "post": {
+        "summary": "Accept a report, disable the link and purge its cache (admin)",
+        "parameters": [
+          { "name": "id", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "responses": {
+          "204": { "description": "Accepted" },
+          "404": { "description": "Not found" },
+          "409": { "description": "already_resolved" }
+        }
+      }
+    },
+    "/v1/admin/reports/{id}/dismiss": {
+      "post": {
+        "summary": "Dismiss a report (admin)",
+        "parameters": [
+          { "name": "id", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "responses": {
+          "204": { "description": "Dismissed" },
+          "404": { "description": "Not found" },
+          "409": { "description": "already_resolved" }
+        }
+      }
+    },
+    "/v1/admin/owners/{id}/utm": {
+      "put": {
+        "summary": "Set the UTM template applied to all links of an owner (admin)",
+        "parameters": [
+          { "name": "id", "in": "path", "required": true, "schema": { "type": "integer", "format": "int64" } }
+        ],
+        "requestBody": {
+          "required": false,
+          "content": { "application/json": { "schema": { "$ref": "#/components/schemas/UTMParams" } } }
+        },
+        "responses": {
+          "200": { "description": "Updated; an empty body or null removes the template" },
+          "400": { "description": "invalid_utm" }
+        }
+      }
+    },
+    "/v1/admin/owners/{id}/api-key": {
+      "post": {
+        "summary": "Create or rotate the API key of an owner (admin)",
+        "parameters": [
+          { "name": "id", "in": "path", "required": true, "schema": { "type": "integer", "format": "int64" } }
+        ],
+        "responses": {
+          "201": { "description": "The new key; it is shown only once and the previous key stops working" }
+        }
+      }
+    },
+    "/v1/admin/owners/{id}/plan": {
+      "put": {
+        "summary": "Set the plan and optional custom limits of an owner (admin)",
+        "parameters": [
+          { "name": "id", "in": "path", "required": true, "schema": { "type": "integer", "format": "int64" } }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "type": "object",
+                "properties": {
+                  "plan": { "type": "string", "enum": ["free", "pro", "business"] },
+                  "quota": { "$ref": "#/components/schemas/Quota" }
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": { "description": "Updated" },
+          "400": { "description": "invalid_plan" }
+        }
+      }
+    },
+    "/v1/admin/usage/export": {
+      "get": {
+        "summary": "Usage of every owner in a month, for billing (admin)",
+        "parameters": [
+          { "name": "period", "in": "query", "required": false, "schema": { "type": "string", "example": "2026-10" } },
+          { "name": "format", "in": "query", "required": false, "schema": { "type": "string", "enum": ["json", "csv"] } }
+        ],
+        "responses": {
+          "200": { "description": "JSON {period, owners} or a CSV file" },
+          "400": { "description": "invalid_period" }
+        }
+      }
+    },
+    "/{code}/{path}": {
+      "get": {
+        "summary": "Resolve a prefix (path_forward) link and append the extra path to the target",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } },
+          { "name": "path", "in": "path", "required": true, "schema": { "type": "string" }, "description": "Any remaining path segments" }
+        ],
+        "responses": {
+          "302": { "description": "Found, redirects to target + path" },
+          "404": { "description": "Not found or link is not a prefix link" }
+        }
+      }
+    },
     "/{code}": {
       "get": {
         "summary": "Resolve and redirect by code",
@@ -69,24 +606,335 @@ const swaggerJSON = `{
             "required": true,
             "schema": { "type": "string" },
             "description": "Short code generated for the original URL"
+          },
+          {
+            "name": "t",
+            "in": "query",
+            "required": false,
+            "schema": { "type": "string" },
+            "description": "Signed continue token issued by the interstitial page"
           }
         ],
         "responses": {
-          "302": { "description": "Found, redirects to original URL" },
-          "404": { "description": "Not found" },
+          "200": { "description": "Interstitial warning page (anonymous or reported link), app bridge page, or OpenGraph card for preview crawlers", "content": { "text/html": {} } },
+          "301": { "description": "Moved Permanently (link or default redirect_type)" },
+          "302": { "description": "Found, redirects to original URL (default)" },
+          "307": { "description": "Temporary Redirect (link or default redirect_type)" },
+          "308": { "description": "Permanent Redirect (link or default redirect_type)" },
+          "404": { "description": "Not found, or not live yet (coming soon page)" },
+          "410": { "description": "Click-capped link has been used up" },
           "500": { "description": "Internal error (settings retrieval failure)" }
         }
+      },
+      "post": {
+        "summary": "Unlock a password-protected link",
+        "parameters": [
+          { "name": "code", "in": "path", "required": true, "schema": { "type": "string" } }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/x-www-form-urlencoded": {
+              "schema": { "type": "object", "required": ["password"], "properties": { "password": { "type": "string" } } }
+            }
+          }
+        },
+        "responses": {
+          "303": { "description": "Unlocked, sets the link_unlock cookie and redirects back to /{code}" },
+          "401": { "description": "Wrong password (form is re-rendered)" },
+          "404": { "description": "Not found" },
+          "429": { "description": "Too many failed attempts" }
+        }
       }
     }
   },
   "components": {
     "schemas": {
+      "Role": { "type": "string", "enum": ["owner", "admin", "editor", "viewer"] },
+      "Scope": { "type": "string", "enum": ["links:read", "links:write", "stats:read", "admin"] },
+      "AccessToken": {
+        "type": "object",
+        "properties": {
+          "id": { "type": "string" },
+          "user_id": { "type": "integer" },
+          "name": { "type": "string" },
+          "prefix": { "type": "string", "description": "First characters of the token, to recognize it" },
+          "scopes": { "type": "array", "items": { "$ref": "#/components/schemas/Scope" } },
+          "created_at": { "type": "string", "format": "date-time" },
+          "expires_at": { "type": "string", "format": "date-time" },
+          "last_used_at": { "type": "string", "format": "date-time", "nullable": true },
+          "revoked_at": { "type": "string", "format": "date-time", "nullable": true }
+        }
+      },
+      "Workspace": {
+        "type": "object",
+        "properties": {
+          "id": { "type": "integer", "description": "Also the owner id of the workspace's links" },
+          "name": { "type": "string" },
+          "created_by": { "type": "integer" },
+          "created_at": { "type": "string", "format": "date-time" },
+          "updated_at": { "type": "string", "format": "date-time" },
+          "role": { "$ref": "#/components/schemas/Role" }
+        }
+      },
+      "Membership": {
+        "type": "object",
+        "properties": {
+          "workspace_id": { "type": "integer" },
+          "user_id": { "type": "integer" },
+          "email": { "type": "string" },
+          "role": { "$ref": "#/components/schemas/Role" },
+          "created_at": { "type": "string", "format": "date-time" },
+          "updated_at": { "type": "string", "format": "date-time" }
+        }
+      },
+      "Invitation": {
+        "type": "object",
+        "properties": {
+          "id": { "type": "string" },
+          "workspace_id": { "type": "integer" },
+          "email": { "type": "string" },
+          "role": { "$ref": "#/components/schemas/Role" },
+          "invited_by": { "type": "integer" },
+          "created_at": { "type": "string", "format": "date-time" },
+          "expires_at": { "type": "string", "format": "date-time" }
+        }
+      },
+      "OwnerSettings": {
+        "type": "object",
+        "properties": {
+          "owner_id": { "type": "integer" },
+          "utm": { "$ref": "#/components/schemas/UTMParams" }
+        }
+      },
       "ShortenRequest": {
         "type": "object",
         "required": ["url"],
         "properties": {
           "url": { "type": "string", "format": "uri", "example": "https://example.com/long" },
-          "custom_alias": { "type": "string", "nullable": true, "example": "my-custom" }
+          "custom_alias": { "type": "string", "nullable": true, "pattern": "^[^+]*$", "example": "my-custom", "description": "Must not contain \"+\" (reserved for previews); invalid_alias otherwise" },
+          "interstitial": { "type": "boolean", "nullable": true, "description": "Always show a warning page before redirecting" },
+          "password": { "type": "string", "maxLength": 72, "description": "Require this password before redirecting" },
+          "max_clicks": { "type": "integer", "minimum": 1, "description": "Link returns 410 after this many redirects" },
+          "one_time": { "type": "boolean", "description": "Shorthand for max_clicks = 1" },
+          "starts_at": { "type": "string", "format": "date-time", "description": "Link is not live before this time" },
+          "rules": { "type": "array", "maxItems": 20, "items": { "$ref": "#/components/schemas/RoutingRule" } },
+          "destinations": { "type": "array", "maxItems": 10, "items": { "$ref": "#/components/schemas/Destination" } },
+          "sticky": { "type": "boolean", "description": "Keep each visitor on the same destination (visitor_id cookie)" },
+          "query_forward": { "type": "string", "enum": ["merge", "override"], "description": "Forward incoming query parameters to the target" },
+          "path_forward": { "type": "boolean", "description": "Treat the link as a prefix and append the extra path to the target" },
+          "template": { "$ref": "#/components/schemas/TemplateConfig" },
+          "utm": { "$ref": "#/components/schemas/UTMParams" },
+          "deep_link": { "$ref": "#/components/schemas/DeepLink" },
+          "redirect_type": { "type": "integer", "enum": [301, 302, 307, 308], "description": "Defaults to the default_redirect_type setting (302)" },
+          "social_card": { "$ref": "#/components/schemas/SocialCard" },
+          "fallback_targets": { "type": "array", "maxItems": 5, "items": { "type": "string", "format": "uri" }, "description": "While the target is degraded, redirect to the first of these whose latest health check passed. Not allowed together with rules, destinations or template" }
+        }
+      },
+      "LinkHealth": {
+        "type": "object",
+        "properties": {
+          "status": { "type": "string", "enum": ["unknown", "healthy", "degraded"] },
+          "consecutive_failures": { "type": "integer" },
+          "last_checked_at": { "type": "string", "format": "date-time" },
+          "degraded_since": { "type": "string", "format": "date-time" },
+          "active_target": { "type": "string", "format": "uri", "description": "Fallback target redirects currently go to" },
+          "failovers": { "type": "integer", "description": "Number of switches to a fallback target" },
+          "last_failover_at": { "type": "string", "format": "date-time" },
+          "fallbacks": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "target": { "type": "string", "format": "uri" },
+                "ok": { "type": "boolean" },
+                "checked_at": { "type": "string", "format": "date-time" },
+                "status_code": { "type": "integer" },
+                "error": { "type": "string" }
+              }
+            }
+          },
+          "history": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "checked_at": { "type": "string", "format": "date-time" },
+                "ok": { "type": "boolean" },
+                "method": { "type": "string", "enum": ["HEAD", "GET"] },
+                "status_code": { "type": "integer" },
+                "latency_ms": { "type": "integer" },
+                "error": { "type": "string", "enum": ["blocked", "timeout", "connection_failed", "bad_status"] }
+              }
+            }
+          }
+        }
+      },
+      "SocialCard": {
+        "type": "object",
+        "description": "OpenGraph values served to social media and chat preview crawlers instead of the redirect",
+        "properties": {
+          "title": { "type": "string", "maxLength": 200 },
+          "description": { "type": "string", "maxLength": 500 },
+          "image": { "type": "string", "format": "uri" }
+        }
+      },
+      "Metadata": {
+        "type": "object",
+        "properties": {
+          "title": { "type": "string" },
+          "description": { "type": "string" },
+          "favicon": { "type": "string", "format": "uri" },
+          "og_title": { "type": "string" },
+          "og_description": { "type": "string" },
+          "og_image": { "type": "string", "format": "uri" },
+          "og_site_name": { "type": "string" },
+          "og_type": { "type": "string" },
+          "fetched_at": { "type": "string", "format": "date-time" },
+          "error": { "type": "string", "enum": ["blocked", "not_html", "bad_status", "timeout", "fetch_failed"] }
+        }
+      },
+      "DeepLink": {
+        "type": "object",
+        "description": "Open the native app on iOS/Android, falling back to the store or web URL",
+        "properties": {
+          "ios_url": { "type": "string", "example": "myapp://item/1", "description": "Custom scheme (bridge page) or universal link (302)" },
+          "ios_store_url": { "type": "string", "format": "uri" },
+          "android_url": { "type": "string", "example": "myapp://item/1", "description": "Custom scheme or App Link" },
+          "android_package": { "type": "string", "example": "com.example.app", "description": "Open via intent:// with a browser fallback" },
+          "android_store_url": { "type": "string", "format": "uri" },
+          "fallback_url": { "type": "string", "format": "uri", "description": "Used when no store URL is set; defaults to the link target" }
+        }
+      },
+      "UTMParams": {
+        "type": "object",
+        "description": "Added to the target at redirect time; parameters already in the target are kept",
+        "properties": {
+          "utm_source": { "type": "string", "maxLength": 200 },
+          "utm_medium": { "type": "string", "maxLength": 200 },
+          "utm_campaign": { "type": "string", "maxLength": 200 },
+          "utm_term": { "type": "string", "maxLength": 200 },
+          "utm_content": { "type": "string", "maxLength": 200 }
+        }
+      },
+      "TemplateConfig": {
+        "type": "object",
+        "description": "Fill {placeholders} in url from the request path and query",
+        "properties": {
+          "path_params": { "type": "array", "items": { "type": "string" }, "example": ["sku"] },
+          "defaults": { "type": "object", "additionalProperties": { "type": "string" } },
+          "on_missing": { "type": "string", "enum": ["error", "empty"], "default": "error" }
+        }
+      },
+      "Destination": {
+        "type": "object",
+        "required": ["target", "weight"],
+        "properties": {
+          "name": { "type": "string", "example": "a" },
+          "target": { "type": "string", "format": "uri" },
+          "weight": { "type": "integer", "minimum": 1, "maximum": 1000 }
+        }
+      },
+      "RoutingRule": {
+        "type": "object",
+        "required": ["target"],
+        "properties": {
+          "target": { "type": "string", "format": "uri" },
+          "devices": { "type": "array", "items": { "type": "string", "enum": ["desktop", "mobile", "tablet", "bot"] } },
+          "os": { "type": "array", "items": { "type": "string", "enum": ["ios", "android", "windows", "macos", "linux", "other"] } },
+          "languages": { "type": "array", "items": { "type": "string" }, "example": ["tr", "en-us"] },
+          "countries": { "type": "array", "items": { "type": "string" }, "example": ["TR"] },
+          "time": {
+            "type": "object",
+            "properties": {
+              "timezone": { "type": "string", "example": "Europe/Istanbul" },
+              "days": { "type": "array", "items": { "type": "string", "enum": ["mon", "tue", "wed", "thu", "fri", "sat", "sun"] } },
+              "from": { "type": "string", "example": "09:00" },
+              "to": { "type": "string", "example": "18:00" }
+            }
+          },
+          "query": { "type": "object", "additionalProperties": { "type": "string" } }
+        }
+      },
+      "ReportRequest": {
+        "type": "object",
+        "required": ["reason"],
+        "properties": {
+          "reason": { "type": "string", "enum": ["phishing", "malware", "spam", "abuse", "other"] },
+          "details": { "type": "string", "maxLength": 500 }
+        }
+      },
+      "Credentials": {
+        "type": "object",
+        "required": ["email", "password"],
+        "properties": {
+          "email": { "type": "string", "format": "email" },
+          "password": { "type": "string", "minLength": 8, "maxLength": 72 }
+        }
+      },
+      "SessionResponse": {
+        "type": "object",
+        "properties": {
+          "user": {
+            "type": "object",
+            "properties": {
+              "id": { "type": "integer", "format": "int64", "description": "Also the owner id of the user's links" },
+              "email": { "type": "string" },
+              "created_at": { "type": "string", "format": "date-time" },
+              "updated_at": { "type": "string", "format": "date-time" }
+            }
+          },
+          "csrf_token": { "type": "string", "description": "Send as X-CSRF-Token on non-GET requests made with the session cookie" }
+        }
+      },
+      "Challenge": {
+        "type": "object",
+        "properties": {
+          "required": { "type": "boolean" },
+          "challenge": { "type": "string", "description": "Send back as X-PoW-Challenge" },
+          "difficulty": { "type": "integer", "description": "sha256(challenge + \":\" + solution) must start with this many zero bits" },
+          "algorithm": { "type": "string", "example": "sha256" },
+          "expires_at": { "type": "string", "format": "date-time" }
+        }
+      },
+      "Quota": {
+        "type": "object",
+        "description": "0 means unlimited",
+        "properties": {
+          "links_per_month": { "type": "integer" },
+          "custom_aliases": { "type": "integer" },
+          "tracked_clicks_per_month": { "type": "integer" },
+          "max_batch_size": { "type": "integer" }
+        }
+      },
+      "QuotaError": {
+        "type": "object",
+        "properties": {
+          "error": { "type": "string", "example": "quota_exceeded" },
+          "quota": { "type": "string", "enum": ["links_per_month", "custom_aliases", "max_batch_size"] },
+          "limit": { "type": "integer" },
+          "used": { "type": "integer" },
+          "reset_at": { "type": "string", "format": "date-time" }
+        }
+      },
+      "UsageReport": {
+        "type": "object",
+        "properties": {
+          "owner_id": { "type": "integer", "format": "int64" },
+          "plan": { "type": "string" },
+          "period": { "type": "string", "example": "2026-10" },
+          "reset_at": { "type": "string", "format": "date-time" },
+          "limits": { "$ref": "#/components/schemas/Quota" },
+          "usage": {
+            "type": "object",
+            "properties": {
+              "links": { "type": "integer" },
+              "custom_aliases": { "type": "integer" },
+              "tracked_clicks": { "type": "integer" },
+              "dropped_clicks": { "type": "integer" },
+              "updated_at": { "type": "string", "format": "date-time" }
+            }
+          }
         }
       },
       "ShortenResponse": {
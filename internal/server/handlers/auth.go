@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/emrealsandev/Url-Shortener/internal/account"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuthHandler struct{ Svc *account.Service }
+
+type credentialsReq struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type sessionResp struct {
+	User      *repo.User `json:"user"`
+	CSRFToken string     `json:"csrf_token"`
+}
+
+func (h AuthHandler) Register(c *fiber.Ctx) error {
+	var req credentialsReq
+	// Sadece JSON: başka sitedeki bir form kullanıcıyı saldırganın hesabıyla oturum açtıramasın.
+	if !c.Is("json") || c.BodyParser(&req) != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	user, token, sess, err := h.Svc.Register(c.Context(), req.Email, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrInvalidEmail):
+			return c.Status(http.StatusBadRequest).SendString("invalid_email")
+		case errors.Is(err, account.ErrInvalidPassword):
+			return c.Status(http.StatusBadRequest).SendString("invalid_password")
+		case errors.Is(err, account.ErrEmailTaken):
+			return c.Status(http.StatusConflict).SendString("email_taken")
+		default:
+			return c.Status(http.StatusInternalServerError).SendString("internal")
+		}
+	}
+
+	middleware.SetSessionCookie(c, token)
+	return c.Status(http.StatusCreated).JSON(sessionResp{User: user, CSRFToken: sess.CSRFToken})
+}
+
+func (h AuthHandler) Login(c *fiber.Ctx) error {
+	var req credentialsReq
+	if !c.Is("json") || c.BodyParser(&req) != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	user, token, sess, err := h.Svc.Login(c.Context(), req.Email, req.Password, middleware.ClientIP(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrInvalidCredentials):
+			return c.Status(http.StatusUnauthorized).SendString("invalid_credentials")
+		case errors.Is(err, account.ErrTooManyAttempts):
+			return c.Status(http.StatusTooManyRequests).SendString("too_many_attempts")
+		default:
+			return c.Status(http.StatusInternalServerError).SendString("internal")
+		}
+	}
+
+	// varsa eski oturum kapatılır; aynı tarayıcıda iki oturum birikmesin
+	_ = h.Svc.Logout(c.Context(), c.Cookies(account.SESSION_COOKIE))
+	middleware.SetSessionCookie(c, token)
+	return c.JSON(sessionResp{User: user, CSRFToken: sess.CSRFToken})
+}
+
+func (h AuthHandler) Logout(c *fiber.Ctx) error {
+	if err := h.Svc.Logout(c.Context(), c.Cookies(account.SESSION_COOKIE)); err != nil {
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	middleware.ClearSessionCookie(c)
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// Me oturumdaki kullanıcı ve arayüzün değiştiren isteklerde göndereceği CSRF token'ı.
+func (h AuthHandler) Me(c *fiber.Ctx) error {
+	user, sess := middleware.CurrentUser(c)
+	if user == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(sessionResp{User: user, CSRFToken: sess.CSRFToken})
+}
+
+// ForgotPassword her zaman 202 döner; e-postanın kayıtlı olup olmadığı belli olmaz.
+func (h AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	h.Svc.RequestPasswordReset(c.Context(), req.Email)
+	return c.SendStatus(http.StatusAccepted)
+}
+
+func (h AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	if err := h.Svc.ResetPassword(c.Context(), req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, account.ErrInvalidPassword):
+			return c.Status(http.StatusBadRequest).SendString("invalid_password")
+		case errors.Is(err, account.ErrInvalidToken):
+			return c.Status(http.StatusBadRequest).SendString("invalid_token")
+		default:
+			return c.Status(http.StatusInternalServerError).SendString("internal")
+		}
+	}
+	// şifre değişince bu tarayıcıdaki oturum da dahil hepsi geçersiz
+	middleware.ClearSessionCookie(c)
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/emrealsandev/Url-Shortener/internal/pow"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ChallengeHandler struct{ Pow *pow.Service }
+
+// Get anonim kısaltma için yeni bir proof-of-work bulmacası verir; kapalıysa required=false döner.
+func (h ChallengeHandler) Get(c *fiber.Ctx) error {
+	settings, ok := c.Locals(middleware.LOCALS_SETTINGS).(repo.Settings)
+	if !ok {
+		return c.Status(http.StatusInternalServerError).SendString("internal: failed to retrieve settings from context")
+	}
+
+	ch, err := h.Pow.Issue(c.Context(), settings)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(ch)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LinksHandler link yönetim uçları. Admin token'ıyla her link, diğer isteklerde sadece sahibin (X-Workspace-ID
+// verilmişse workspace'in) kendi linkleri yönetilir.
+type LinksHandler struct{ Svc *short.Service }
+
+// authorizeLink admin değilse linkin isteğin sahibine ait olduğunu kontrol eder; başkasının linki için 404.
+// false dönerse yanıt yazılmıştır.
+func (h LinksHandler) authorizeLink(c *fiber.Ctx, code string) (bool, error) {
+	if middleware.IsAdmin(c) {
+		return true, nil
+	}
+	ownerID := middleware.OwnerID(c)
+	if ownerID == nil {
+		return false, c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+	if err := h.Svc.CheckOwned(*ownerID, code); err != nil {
+		if errors.Is(err, short.ErrNotFound) {
+			return false, c.SendStatus(http.StatusNotFound)
+		}
+		return false, c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return true, nil
+}
+
+type scheduleReq struct {
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Schedule yayın penceresini komple değiştirir; gönderilmeyen/null alan sınırı kaldırır.
+func (h LinksHandler) Schedule(c *fiber.Ctx) error {
+	var req scheduleReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	code := c.Params("code")
+	if ok, err := h.authorizeLink(c, code); !ok {
+		return err
+	}
+	if err := h.Svc.Reschedule(c.Context(), code, req.StartsAt, req.ExpiresAt); err != nil {
+		switch {
+		case errors.Is(err, short.ErrInvalidSchedule):
+			return c.Status(http.StatusBadRequest).SendString("invalid_schedule")
+		case errors.Is(err, short.ErrNotFound):
+			return c.SendStatus(http.StatusNotFound)
+		default:
+			return c.Status(http.StatusInternalServerError).SendString("internal")
+		}
+	}
+	return c.JSON(fiber.Map{"code": code, "starts_at": req.StartsAt, "expires_at": req.ExpiresAt})
+}
+
+// Stats toplam ve A/B varyantı bazında tıklama sayıları.
+func (h LinksHandler) Stats(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if ok, err := h.authorizeLink(c, code); !ok {
+		return err
+	}
+	stats, err := h.Svc.Stats(c.Context(), code)
+	if err != nil {
+		if errors.Is(err, short.ErrNotFound) {
+			return c.SendStatus(http.StatusNotFound)
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return c.JSON(stats)
+}
+
+// Health linkin son sağlık kontrolü sonuçlarını döner.
+func (h LinksHandler) Health(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if ok, err := h.authorizeLink(c, code); !ok {
+		return err
+	}
+	health, err := h.Svc.Health(c.Context(), code)
+	if err != nil {
+		if errors.Is(err, short.ErrNotFound) {
+			return c.SendStatus(http.StatusNotFound)
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return c.JSON(health)
+}
+
+// Preview linkin hedefini ve güvenlik durumunu döner; tıklama sayılmaz.
+func (h LinksHandler) Preview(c *fiber.Ctx) error {
+	p, err := h.Svc.Preview(c.Context(), c.Params("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, short.ErrNotFound):
+			return c.SendStatus(http.StatusNotFound)
+		case errors.Is(err, short.ErrGone):
+			return c.SendStatus(http.StatusGone)
+		default:
+			return c.Status(http.StatusInternalServerError).SendString("internal")
+		}
+	}
+	return c.JSON(p)
+}
+
+// RefreshMetadata hedef sayfanın başlık, açıklama, OpenGraph ve favicon bilgisini hemen yeniden çeker.
+func (h LinksHandler) RefreshMetadata(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if ok, err := h.authorizeLink(c, code); !ok {
+		return err
+	}
+	md, err := h.Svc.RefreshMetadata(c.Context(), code)
+	if err != nil {
+		if errors.Is(err, short.ErrNotFound) {
+			return c.SendStatus(http.StatusNotFound)
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return c.JSON(md)
+}
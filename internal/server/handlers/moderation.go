@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/emrealsandev/Url-Shortener/internal/moderation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ModerationHandler struct{ Svc *moderation.Service }
+
+func (h ModerationHandler) List(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	offset := c.QueryInt("offset", 0)
+
+	reports, err := h.Svc.List(c.Context(), c.Query("status"), int64(limit), int64(offset))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return c.JSON(reports)
+}
+
+func (h ModerationHandler) Accept(c *fiber.Ctx) error {
+	return h.respond(c, h.Svc.Accept(c.Context(), c.Params("id")))
+}
+
+func (h ModerationHandler) Dismiss(c *fiber.Ctx) error {
+	return h.respond(c, h.Svc.Dismiss(c.Context(), c.Params("id")))
+}
+
+func (h ModerationHandler) respond(c *fiber.Ctx, err error) error {
+	switch {
+	case err == nil:
+		return c.SendStatus(http.StatusNoContent)
+	case errors.Is(err, moderation.ErrNotFound):
+		return c.SendStatus(http.StatusNotFound)
+	case errors.Is(err, moderation.ErrResolved):
+		return c.Status(http.StatusConflict).SendString("already_resolved")
+	default:
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+}
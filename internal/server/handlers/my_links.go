@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MyLinksHandler oturum açmış kullanıcının (veya API anahtarının sahibinin) kendi linkleri; X-Workspace-ID
+// verilmişse o workspace'in linkleri.
+type MyLinksHandler struct{ Svc *short.Service }
+
+func (h MyLinksHandler) List(c *fiber.Ctx) error {
+	ownerID := middleware.OwnerID(c)
+	if ownerID == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	links, err := h.Svc.OwnerLinks(c.Context(), *ownerID, int64(c.QueryInt("limit", 50)), int64(c.QueryInt("offset", 0)))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"links": links})
+}
+
+// Disable linki kapatır; başkasının linki için de 404 döner.
+func (h MyLinksHandler) Disable(c *fiber.Ctx) error {
+	ownerID := middleware.OwnerID(c)
+	if ownerID == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	if err := h.Svc.DisableOwned(c.Context(), *ownerID, c.Params("code")); err != nil {
+		if errors.Is(err, short.ErrNotFound) {
+			return c.SendStatus(http.StatusNotFound)
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// Stats linkin tıklama sayıları; başkasının linki için 404.
+func (h MyLinksHandler) Stats(c *fiber.Ctx) error {
+	ownerID := middleware.OwnerID(c)
+	if ownerID == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	stats, err := h.Svc.OwnedStats(c.Context(), *ownerID, c.Params("code"))
+	if err != nil {
+		if errors.Is(err, short.ErrNotFound) {
+			return c.SendStatus(http.StatusNotFound)
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(stats)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MySettingsHandler kişisel alanın ya da X-Workspace-ID ile seçilen workspace'in ayarları.
+type MySettingsHandler struct{ Svc *short.Service }
+
+func (h MySettingsHandler) Get(c *fiber.Ctx) error {
+	ownerID := middleware.OwnerID(c)
+	if ownerID == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	settings, err := h.Svc.OwnerSettings(c.Context(), *ownerID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(settings)
+}
+
+// Put ayarları komple değiştirir; "utm": null şablonu kaldırır.
+func (h MySettingsHandler) Put(c *fiber.Ctx) error {
+	ownerID := middleware.OwnerID(c)
+	if ownerID == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	var req struct {
+		UTM *repo.UTMParams `json:"utm"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	if err := h.Svc.SetOwnerUTM(c.Context(), *ownerID, req.UTM); err != nil {
+		if errors.Is(err, short.ErrInvalidUTM) {
+			return c.Status(http.StatusBadRequest).SendString("invalid_utm")
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return h.Get(c)
+}
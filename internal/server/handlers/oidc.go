@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/emrealsandev/Url-Shortener/internal/account"
+	"github.com/emrealsandev/Url-Shortener/internal/oidc"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	OIDC_STATE_COOKIE = "oidc_state"
+	OIDC_COOKIE_PATH  = "/v1/auth/oidc"
+	// OIDC_DONE_PATH akış bitince (başarılı ya da hatalı) tarayıcının döndüğü sayfa.
+	OIDC_DONE_PATH = "/account"
+)
+
+// OIDCHandler SSO girişi. Client nil ise SSO kapalıdır.
+type OIDCHandler struct {
+	Client   *oidc.Client
+	Accounts *account.Service
+}
+
+// Providers arayüz hangi giriş yöntemlerini göstereceğini buradan öğrenir.
+func (h OIDCHandler) Providers(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"password": true, "oidc": h.Client != nil})
+}
+
+// Login tarayıcıyı IdP'ye yönlendirir. State bir cookie'ye de yazılır; callback başka bir tarayıcıda
+// başlatılmış bir akışı (login CSRF) tamamlayamaz.
+func (h OIDCHandler) Login(c *fiber.Ctx) error {
+	if h.Client == nil {
+		return c.Status(http.StatusNotFound).SendString("sso_disabled")
+	}
+
+	authURL, state, err := h.Client.Begin(c.Context())
+	if err != nil {
+		if errors.Is(err, oidc.ErrProvider) {
+			return c.Status(http.StatusBadGateway).SendString("sso_unavailable")
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+
+	setStateCookie(c, state, int(oidc.STATE_TTL.Seconds()))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Redirect(authURL, http.StatusFound)
+}
+
+// Callback IdP dönüşü. Hatalar JSON yerine hesap sayfasına ?sso_error= ile yönlendirilir; kullanıcı buraya
+// tarayıcıyla gelir.
+func (h OIDCHandler) Callback(c *fiber.Ctx) error {
+	if h.Client == nil {
+		return c.Status(http.StatusNotFound).SendString("sso_disabled")
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	cookieState := c.Cookies(OIDC_STATE_COOKIE)
+	setStateCookie(c, "", -1)
+
+	// IdP kullanıcı reddettiğinde ya da hata olduğunda code yerine error döner
+	if c.Query("error") != "" {
+		return ssoFailed(c, "sso_denied")
+	}
+	state := c.Query("state")
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		return ssoFailed(c, "invalid_state")
+	}
+
+	identity, err := h.Client.Finish(c.Context(), state, c.Query("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, oidc.ErrInvalidState):
+			return ssoFailed(c, "invalid_state")
+		case errors.Is(err, oidc.ErrNotAllowed):
+			return ssoFailed(c, "sso_not_allowed")
+		case errors.Is(err, oidc.ErrEmailUnverified):
+			return ssoFailed(c, "sso_email_unverified")
+		case errors.Is(err, oidc.ErrInvalidToken):
+			return ssoFailed(c, "sso_failed")
+		default:
+			return ssoFailed(c, "sso_unavailable")
+		}
+	}
+
+	_, token, _, err := h.Accounts.LoginExternal(c.Context(), account.ExternalIdentity{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+		Email:   identity.Email,
+	})
+	if err != nil {
+		if errors.Is(err, account.ErrInvalidEmail) {
+			return ssoFailed(c, "sso_email_unverified")
+		}
+		if errors.Is(err, account.ErrAccountExists) {
+			return ssoFailed(c, "sso_account_exists")
+		}
+		return ssoFailed(c, "sso_failed")
+	}
+
+	_ = h.Accounts.Logout(c.Context(), c.Cookies(account.SESSION_COOKIE))
+	middleware.SetSessionCookie(c, token)
+	return c.Redirect(OIDC_DONE_PATH, http.StatusFound)
+}
+
+func ssoFailed(c *fiber.Ctx, code string) error {
+	return c.Redirect(OIDC_DONE_PATH+"?sso_error="+url.QueryEscape(code), http.StatusFound)
+}
+
+// setStateCookie SameSite=Lax: IdP'den dönüş üst seviye GET olduğu için cookie gönderilir.
+func setStateCookie(c *fiber.Ctx, state string, maxAge int) {
+	c.Cookie(&fiber.Cookie{
+		Name:     OIDC_STATE_COOKIE,
+		Value:    state,
+		Path:     OIDC_COOKIE_PATH,
+		MaxAge:   maxAge,
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
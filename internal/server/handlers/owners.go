@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// OwnersHandler sahip bazlı ayarlar (admin).
+type OwnersHandler struct{ Svc *short.Service }
+
+// SetUTM sahibin bütün linklerine uygulanacak UTM şablonunu değiştirir; boş gövde veya null şablonu kaldırır.
+func (h OwnersHandler) SetUTM(c *fiber.Ctx) error {
+	ownerID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.SendStatus(http.StatusNotFound)
+	}
+
+	var utm *repo.UTMParams
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&utm); err != nil {
+			return c.Status(http.StatusBadRequest).SendString("bad_request")
+		}
+	}
+
+	if err := h.Svc.SetOwnerUTM(c.Context(), ownerID, utm); err != nil {
+		if errors.Is(err, short.ErrInvalidUTM) {
+			return c.Status(http.StatusBadRequest).SendString("invalid_utm")
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return c.JSON(fiber.Map{"owner_id": ownerID, "utm": utm})
+}
+
+// CreateAPIKey sahibe yeni bir API anahtarı üretir; eski anahtar hemen geçersiz olur. Anahtar bir daha gösterilmez.
+func (h OwnersHandler) CreateAPIKey(c *fiber.Ctx) error {
+	ownerID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.SendStatus(http.StatusNotFound)
+	}
+
+	key, err := h.Svc.CreateOwnerAPIKey(c.Context(), ownerID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"owner_id": ownerID, "api_key": key})
+}
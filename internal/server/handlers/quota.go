@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sendQuotaError aylık kotalar dönem sonunda açıldığı için 429 + Retry-After, plan limitleri 402 döner.
+func sendQuotaError(c *fiber.Ctx, err error) (bool, error) {
+	var qe *short.QuotaExceededError
+	if !errors.As(err, &qe) {
+		return false, nil
+	}
+
+	status := http.StatusPaymentRequired
+	body := fiber.Map{"error": short.ErrQuotaExceeded.Error(), "quota": qe.Quota, "limit": qe.Limit, "used": qe.Used}
+	if qe.ResetAt != nil {
+		status = http.StatusTooManyRequests
+		body["reset_at"] = qe.ResetAt
+		retry := int64(time.Until(*qe.ResetAt)/time.Second) + 1
+		c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(retry, 10))
+	}
+	return true, c.Status(status).JSON(body)
+}
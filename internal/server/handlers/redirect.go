@@ -1,27 +1,309 @@
 package handlers
 
 import (
-	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"errors"
+	"html/template"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+	"github.com/emrealsandev/Url-Shortener/internal/server/views"
 	"github.com/emrealsandev/Url-Shortener/internal/short"
+	"github.com/emrealsandev/Url-Shortener/pkg/useragent"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 )
 
-type RedirectHandler struct{ Svc *short.Service }
+const (
+	INTERSTITIAL_TOKEN_TTL = 5 * time.Minute
+
+	UNLOCK_COOKIE     = "link_unlock"
+	UNLOCK_COOKIE_TTL = 12 * time.Hour
+
+	VISITOR_COOKIE     = "visitor_id"
+	VISITOR_COOKIE_TTL = 365 * 24 * time.Hour
+
+	// PREVIEW_SUFFIX "/abc+" yönlendirmek yerine önizleme sayfasını gösterir.
+	PREVIEW_SUFFIX = "+"
+
+	DEFAULT_REFERRER_POLICY = "strict-origin-when-cross-origin"
+
+	// APP_BRIDGE_TIMEOUT uygulama açılmazsa ara sayfanın yedek adrese geçmeden önce beklediği süre.
+	APP_BRIDGE_TIMEOUT = 1500 * time.Millisecond
+)
+
+type RedirectHandler struct {
+	Svc    *short.Service
+	Signer *security.Signer
+}
+
+type interstitialPage struct {
+	Domain      string
+	Target      string
+	ContinueURL string
+	Anonymous   bool
+	Flagged     bool
+	// Capped ara sayfa hak harcamadığı için limitli linklerde hedef gösterilmez; sadece "Devam et" açar.
+	Capped bool
+}
+
+type comingSoonPage struct {
+	StartsAt time.Time
+}
+
+type appBridgePage struct {
+	AppURL string
+	// AppHref doğrulanmış özel şemalı adres; html/template aksi halde href'te sadece http(s)'e izin verir.
+	AppHref     template.URL
+	FallbackURL string
+	TimeoutMs   int64
+}
+
+// socialCardPage sadece kartın kendi bilgileriyle çizilir; hedef adres bota gösterilmez (limitli linkler dahil).
+type socialCardPage struct {
+	*repo.SocialCard
+	URL string
+}
+
+type previewPage struct {
+	*short.Preview
+	LinkURL string
+}
+
+type passwordPage struct {
+	Action string
+	Error  string
+}
 
 func (h RedirectHandler) Serve(c *fiber.Ctx) error {
 
-	settings, ok := c.Locals("settings").(repo.Settings)
+	settings, ok := c.Locals(middleware.LOCALS_SETTINGS).(repo.Settings)
 	if !ok {
 		return c.Status(http.StatusInternalServerError).SendString("internal: failed to retrieve settings from context")
 	}
 
 	code := c.Params("code")
-	target, err := h.Svc.Resolve(c.Context(), code, settings)
+	if strings.HasSuffix(code, PREVIEW_SUFFIX) && c.Params("*") == "" {
+		return h.preview(c, strings.TrimSuffix(code, PREVIEW_SUFFIX))
+	}
+
+	visitorID := c.Cookies(VISITOR_COOKIE)
+	newVisitor := visitorID == ""
+	if newVisitor {
+		visitorID = utils.UUIDv4()
+	}
+
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	crawler := useragent.IsCrawler(userAgent)
+
+	req := short.Request{
+		Unlocked:  h.Signer.Verify(c.Cookies(UNLOCK_COOKIE), unlockPayload(code)),
+		Confirmed: h.Signer.Verify(c.Query("t"), interstitialPayload(code, middleware.ClientIP(c))),
+		// Önizleme botları tıklama sayılmaz; tek kullanımlık linki de harcamamalı.
+		DryRun:  c.Method() == fiber.MethodHead || crawler,
+		Crawler: crawler,
+
+		IP:             middleware.ClientIP(c),
+		UserAgent:      userAgent,
+		AcceptLanguage: c.Get(fiber.HeaderAcceptLanguage),
+		Query:          c.Queries(),
+		VisitorID:      visitorID,
+		Suffix:         c.Params("*"),
+		RawQuery:       string(c.Request().URI().QueryString()),
+	}
+
+	res, err := h.Svc.Resolve(c.Context(), code, req, settings)
 	if err != nil {
+		switch {
+		case errors.Is(err, short.ErrPasswordRequired):
+			return h.passwordForm(c, code, http.StatusOK, "")
+		case errors.Is(err, short.ErrGone):
+			return c.SendStatus(http.StatusGone)
+		case errors.Is(err, short.ErrNotStarted):
+			return h.comingSoon(c, err, settings)
+		case errors.Is(err, short.ErrTemplateParam):
+			return c.Status(http.StatusBadRequest).SendString("invalid_parameter")
+		default:
+			return c.SendStatus(http.StatusNotFound)
+		}
+	}
+
+	if res.Card != nil {
+		return views.Render(c, http.StatusOK, "social_card.html", socialCardPage{
+			SocialCard: res.Card,
+			URL:        c.BaseURL() + "/" + url.PathEscape(code),
+		})
+	}
+
+	// Hak harcanmadı; tek kullanımlık veya limitli linkin hedefi HEAD/bot isteğiyle sınırsız okunamasın.
+	if req.DryRun && res.Capped {
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return c.SendStatus(http.StatusNoContent)
+	}
+
+	if res.Interstitial {
+		return h.interstitial(c, code, res)
+	}
+
+	// Sticky A/B linklerde ziyaretçi kimliği kalıcı olmalı, yoksa her ziyarette başka varyant düşebilir.
+	if res.Sticky && newVisitor {
+		c.Cookie(&fiber.Cookie{
+			Name:     VISITOR_COOKIE,
+			Value:    visitorID,
+			Path:     "/",
+			MaxAge:   int(VISITOR_COOKIE_TTL.Seconds()),
+			Secure:   c.Protocol() == "https",
+			HTTPOnly: true,
+			SameSite: fiber.CookieSameSiteLaxMode,
+		})
+	}
+
+	if res.App != nil {
+		return views.Render(c, http.StatusOK, "app_bridge.html", appBridgePage{
+			AppURL:      res.App.URL,
+			AppHref:     template.URL(res.App.URL),
+			FallbackURL: res.App.Fallback,
+			TimeoutMs:   APP_BRIDGE_TIMEOUT.Milliseconds(),
+		})
+	}
+
+	setRedirectHeaders(c, res, settings)
+	return c.Redirect(res.Target, res.Status)
+}
+
+// setRedirectHeaders değişebilecek linklerin tarayıcıda cache'lenmesini engeller; kısa linkin kendisi indekslenmez,
+// geçici yönlendirmelerde arama motoru hedefi de takip etmez.
+func setRedirectHeaders(c *fiber.Ctx, res *short.Resolution, settings repo.Settings) {
+	if res.MaxAge > 0 {
+		c.Set(fiber.HeaderCacheControl, "public, max-age="+strconv.Itoa(int(res.MaxAge.Seconds())))
+	} else {
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+	}
+
+	policy := settings.ReferrerPolicy
+	if policy == "" {
+		policy = DEFAULT_REFERRER_POLICY
+	}
+	c.Set(fiber.HeaderReferrerPolicy, policy)
+
+	if res.Status == http.StatusMovedPermanently || res.Status == http.StatusPermanentRedirect {
+		c.Set("X-Robots-Tag", "noindex")
+	} else {
+		c.Set("X-Robots-Tag", "noindex, nofollow")
+	}
+}
+
+// Unlock şifre formunu karşılar; başarılı olursa sadece bu koda ait imzalı çerezi yazıp linke geri yönlendirir.
+func (h RedirectHandler) Unlock(c *fiber.Ctx) error {
+	code := c.Params("code")
+
+	err := h.Svc.Unlock(c.Context(), code, c.FormValue("password"), middleware.ClientIP(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, short.ErrWrongPassword):
+			return h.passwordForm(c, code, http.StatusUnauthorized, "Şifre hatalı.")
+		case errors.Is(err, short.ErrTooManyAttempts):
+			return h.passwordForm(c, code, http.StatusTooManyRequests, "Çok fazla hatalı deneme. Lütfen daha sonra tekrar deneyin.")
+		case errors.Is(err, short.ErrNotFound), errors.Is(err, short.ErrExpired):
+			return c.SendStatus(http.StatusNotFound)
+		default:
+			return c.Status(http.StatusInternalServerError).SendString("internal")
+		}
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     UNLOCK_COOKIE,
+		Value:    h.Signer.Sign(unlockPayload(code), UNLOCK_COOKIE_TTL),
+		Path:     "/" + code,
+		MaxAge:   int(UNLOCK_COOKIE_TTL.Seconds()),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(selfURL(c, nil), http.StatusSeeOther)
+}
+
+// preview tıklama saymadan linkin nereye gittiğini gösterir.
+func (h RedirectHandler) preview(c *fiber.Ctx, code string) error {
+	p, err := h.Svc.Preview(c.Context(), code)
+	if err != nil {
+		switch {
+		case errors.Is(err, short.ErrNotFound):
+			return c.SendStatus(http.StatusNotFound)
+		case errors.Is(err, short.ErrGone):
+			return c.SendStatus(http.StatusGone)
+		default:
+			return c.Status(http.StatusInternalServerError).SendString("internal")
+		}
+	}
+	return views.Render(c, http.StatusOK, "preview.html", previewPage{Preview: p, LinkURL: "/" + url.PathEscape(code)})
+}
+
+func (h RedirectHandler) comingSoon(c *fiber.Ctx, err error, settings repo.Settings) error {
+	if settings.ComingSoonURL != "" {
+		return c.Redirect(settings.ComingSoonURL, http.StatusFound)
+	}
+
+	var notStarted *short.NotStartedError
+	if !errors.As(err, &notStarted) {
 		return c.SendStatus(http.StatusNotFound)
 	}
-	return c.Redirect(target, http.StatusFound)
+	return views.Render(c, http.StatusNotFound, "coming_soon.html", comingSoonPage{StartsAt: notStarted.StartsAt})
+}
+
+func (h RedirectHandler) passwordForm(c *fiber.Ctx, code string, status int, msg string) error {
+	return views.Render(c, status, "password.html", passwordPage{
+		Action: selfURL(c, nil),
+		Error:  msg,
+	})
+}
+
+func (h RedirectHandler) interstitial(c *fiber.Ctx, code string, res *short.Resolution) error {
+	// Token kod ve istemci IP'sine bağlı; paylaşılan link ara sayfayı atlatamaz.
+	token := h.Signer.Sign(interstitialPayload(code, middleware.ClientIP(c)), INTERSTITIAL_TOKEN_TTL)
+
+	page := interstitialPage{
+		ContinueURL: selfURL(c, url.Values{"t": {token}}),
+		Anonymous:   res.Anonymous,
+		Flagged:     res.Flagged,
+		Capped:      res.Capped,
+	}
+	if !res.Capped {
+		page.Target, page.Domain = res.Target, res.Target
+		if u, err := url.Parse(res.Target); err == nil {
+			page.Domain = u.Hostname()
+		}
+	}
+	return views.Render(c, http.StatusOK, "interstitial.html", page)
+}
+
+// selfURL isteğin kod, path eki ve query'sini koruyarak yerel bir URL üretir; set edilen parametreler üzerine yazılır.
+func selfURL(c *fiber.Ctx, set url.Values) string {
+	path := "/" + url.PathEscape(c.Params("code"))
+	if suffix := c.Params("*"); suffix != "" {
+		path += "/" + suffix
+	}
+
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	for k, v := range set {
+		query[k] = v
+	}
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+func interstitialPayload(code, ip string) string {
+	return "interstitial:" + code + ":" + ip
+}
+
+func unlockPayload(code string) string {
+	return "unlock:" + code
 }
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// memRepo yönlendirmenin kullandığı metodları bellekte tutar; diğerleri kullanılmaz.
+type memRepo struct {
+	repo.Repository
+	mu   sync.Mutex
+	urls map[string]*repo.URL
+}
+
+func (r *memRepo) GetByCode(code string) (*repo.URL, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.urls[code]
+	if !ok {
+		return nil, nil
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *memRepo) ConsumeClick(ctx context.Context, code string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.urls[code]
+	if u.MaxClicks != nil && u.Clicks >= *u.MaxClicks {
+		return false, nil
+	}
+	u.Clicks++
+	return true, nil
+}
+
+func (r *memRepo) InsertClick(ctx context.Context, click repo.Click) error { return nil }
+
+// noCache hiçbir şey saklamaz; her istek repo'dan çözülür.
+type noCache struct{ cache.Cache }
+
+func (noCache) GetURLByCode(ctx context.Context, code string) (string, bool, error) {
+	return "", false, nil
+}
+
+func (noCache) SetURLByCode(ctx context.Context, code, target string, ttl time.Duration) error {
+	return nil
+}
+
+func (noCache) SetCodeByURLKey(ctx context.Context, urlKey, code string, ttl time.Duration) error {
+	return nil
+}
+
+func newRedirectApp(t *testing.T, urls ...repo.URL) (*fiber.App, *memRepo) {
+	t.Helper()
+	r := &memRepo{urls: map[string]*repo.URL{}}
+	for i := range urls {
+		r.urls[urls[i].Code] = &urls[i]
+	}
+	h := RedirectHandler{
+		Svc:    short.NewService(r, noCache{}, "http://sho.rt", logger.GetLogger(), nil),
+		Signer: security.NewSigner("test-secret"),
+	}
+
+	app := fiber.New()
+	app.Get("/:code/*", func(c *fiber.Ctx) error {
+		c.Locals(middleware.LOCALS_SETTINGS, repo.Settings{RedisTtlTime: 1})
+		return c.Next()
+	}, h.Serve)
+	return app, r
+}
+
+func get(t *testing.T, app *fiber.App, path string) (*http.Response, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+var continueLink = regexp.MustCompile(`href="(/[^"]*\?t=[^"]*)"`)
+
+func TestCappedInterstitialHidesTarget(t *testing.T) {
+	const target = "https://secret.example.com/burn-after-reading"
+	one, yes := int64(1), true
+	app, r := newRedirectApp(t, repo.URL{Code: "once", Target: target, MaxClicks: &one, Interstitial: &yes, CreatedAt: time.Now()})
+
+	// Ara sayfa tıklama saymaz; kaç kez açılırsa açılsın hedefi göstermemeli.
+	var next string
+	for i := 0; i < 3; i++ {
+		resp, body := get(t, app, "/once")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("view %d: status = %d", i, resp.StatusCode)
+		}
+		if strings.Contains(body, "secret.example.com") {
+			t.Fatalf("view %d: interstitial shows the target of a capped link", i)
+		}
+		m := continueLink.FindStringSubmatch(body)
+		if m == nil {
+			t.Fatalf("view %d: no continue link in %s", i, body)
+		}
+		next = html.UnescapeString(m[1])
+	}
+	if clicks := r.urls["once"].Clicks; clicks != 0 {
+		t.Fatalf("interstitial views used %d clicks", clicks)
+	}
+
+	resp, _ := get(t, app, next)
+	if resp.StatusCode != http.StatusFound || resp.Header.Get(fiber.HeaderLocation) != target {
+		t.Fatalf("continue: status = %d, location = %q", resp.StatusCode, resp.Header.Get(fiber.HeaderLocation))
+	}
+
+	for _, path := range []string{"/once", next} {
+		resp, body := get(t, app, path)
+		if resp.StatusCode != http.StatusGone {
+			t.Errorf("%s after the only click: status = %d", path, resp.StatusCode)
+		}
+		if strings.Contains(body, "secret.example.com") || resp.Header.Get(fiber.HeaderLocation) != "" {
+			t.Errorf("%s after the only click still reveals the target", path)
+		}
+	}
+}
+
+func TestInterstitialShowsTarget(t *testing.T) {
+	yes := true
+	app, _ := newRedirectApp(t, repo.URL{Code: "warn", Target: "https://example.com/page", Interstitial: &yes, CreatedAt: time.Now()})
+
+	resp, body := get(t, app, "/warn")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if !strings.Contains(body, "https://example.com/page") {
+		t.Error("interstitial of an uncapped link does not show the target")
+	}
+}
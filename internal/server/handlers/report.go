@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/emrealsandev/Url-Shortener/internal/moderation"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportHandler struct{ Svc *moderation.Service }
+
+type reportReq struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details,omitempty"`
+}
+
+func (h ReportHandler) Serve(c *fiber.Ctx) error {
+	var req reportReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	settings, ok := c.Locals(middleware.LOCALS_SETTINGS).(repo.Settings)
+	if !ok {
+		return c.Status(http.StatusInternalServerError).SendString("internal: failed to retrieve settings from context")
+	}
+
+	err := h.Svc.Report(c.Context(), c.Params("code"), req.Reason, req.Details, middleware.ClientIP(c), settings)
+	if err != nil {
+		switch {
+		case errors.Is(err, moderation.ErrInvalidReason):
+			return c.Status(http.StatusBadRequest).SendString("invalid_reason")
+		case errors.Is(err, moderation.ErrNotFound):
+			return c.SendStatus(http.StatusNotFound)
+		case errors.Is(err, moderation.ErrAlreadyPending):
+			return c.Status(http.StatusConflict).SendString("already_reported")
+		default:
+			return c.Status(http.StatusInternalServerError).SendString("internal")
+		}
+	}
+	return c.Status(http.StatusAccepted).JSON(fiber.Map{"status": repo.REPORT_STATUS_PENDING})
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"errors"
 	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
 	"github.com/emrealsandev/Url-Shortener/internal/short"
 	"net/http"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,8 +14,50 @@ import (
 type ShortenHandler struct{ Svc *short.Service }
 
 type shortenReq struct {
-	URL         string  `json:"url"`
-	CustomAlias *string `json:"custom_alias,omitempty"`
+	URL          string               `json:"url"`
+	CustomAlias  *string              `json:"custom_alias,omitempty"`
+	Interstitial *bool                `json:"interstitial,omitempty"`
+	Password     string               `json:"password,omitempty"`
+	MaxClicks    *int64               `json:"max_clicks,omitempty"`
+	OneTime      bool                 `json:"one_time,omitempty"`
+	StartsAt     *time.Time           `json:"starts_at,omitempty"`
+	Rules        []repo.RoutingRule   `json:"rules,omitempty"`
+	Destinations []repo.Destination   `json:"destinations,omitempty"`
+	Sticky       bool                 `json:"sticky,omitempty"`
+	QueryForward string               `json:"query_forward,omitempty"`
+	PathForward  bool                 `json:"path_forward,omitempty"`
+	Template     *repo.TemplateConfig `json:"template,omitempty"`
+	UTM          *repo.UTMParams      `json:"utm,omitempty"`
+	DeepLink     *repo.DeepLink       `json:"deep_link,omitempty"`
+	RedirectType int                  `json:"redirect_type,omitempty"`
+	SocialCard   *repo.SocialCard     `json:"social_card,omitempty"`
+	// FallbackTargets hedef bozuk bulunursa sırayla denenecek adresler.
+	FallbackTargets []string `json:"fallback_targets,omitempty"`
+}
+
+func (r shortenReq) options() short.LinkOptions {
+	opts := short.LinkOptions{
+		Interstitial:    r.Interstitial,
+		Password:        r.Password,
+		MaxClicks:       r.MaxClicks,
+		StartsAt:        r.StartsAt,
+		Rules:           r.Rules,
+		Destinations:    r.Destinations,
+		Sticky:          r.Sticky,
+		QueryForward:    r.QueryForward,
+		PathForward:     r.PathForward,
+		Template:        r.Template,
+		UTM:             r.UTM,
+		DeepLink:        r.DeepLink,
+		RedirectType:    r.RedirectType,
+		SocialCard:      r.SocialCard,
+		FallbackTargets: r.FallbackTargets,
+	}
+	if r.OneTime {
+		one := int64(1)
+		opts.MaxClicks = &one
+	}
+	return opts
 }
 
 func (h ShortenHandler) Serve(c *fiber.Ctx) error {
@@ -22,21 +66,109 @@ func (h ShortenHandler) Serve(c *fiber.Ctx) error {
 		return c.Status(http.StatusBadRequest).SendString("bad_request")
 	}
 
-	settings, ok := c.Locals("settings").(repo.Settings)
+	settings, ok := c.Locals(middleware.LOCALS_SETTINGS).(repo.Settings)
 	if !ok {
 		return c.Status(http.StatusInternalServerError).SendString("internal: failed to retrieve settings from context")
 	}
 
-	code, shortURL, err := h.Svc.Shorten(c.Context(), req.URL, req.CustomAlias, settings)
+	opts := req.options()
+	opts.OwnerID = middleware.OwnerID(c)
+
+	code, shortURL, err := h.Svc.Shorten(c.Context(), req.URL, req.CustomAlias, opts, settings)
 	if err != nil {
-		switch {
-		case errors.Is(err, short.ErrInvalidURL):
-			return c.Status(http.StatusBadRequest).SendString("invalid_url")
-		case errors.Is(err, short.ErrConflict):
-			return c.Status(http.StatusConflict).SendString("conflict")
-		default:
-			return c.Status(http.StatusInternalServerError).SendString("internal")
+		if ok, err := sendQuotaError(c, err); ok {
+			return err
 		}
+		status, body := shortenError(err)
+		return c.Status(status).SendString(body)
 	}
 	return c.JSON(fiber.Map{"code": code, "short_url": shortURL})
 }
+
+type batchReq struct {
+	Items []shortenReq `json:"items"`
+}
+
+// batchResult her kalem için ya kod ya da Serve'ün döneceği hata kodu.
+type batchResult struct {
+	Code     string `json:"code,omitempty"`
+	ShortURL string `json:"short_url,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ServeBatch birden çok linki tek istekte kısaltır; sadece API anahtarıyla ve plandaki boyuta kadar.
+// Kalemler birbirinden bağımsızdır, biri hata verse de diğerleri oluşturulur.
+func (h ShortenHandler) ServeBatch(c *fiber.Ctx) error {
+	ownerID := middleware.OwnerID(c)
+	if ownerID == nil {
+		return c.Status(http.StatusUnauthorized).SendString("api_key_required")
+	}
+
+	var req batchReq
+	if err := c.BodyParser(&req); err != nil || len(req.Items) == 0 {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	settings, ok := c.Locals(middleware.LOCALS_SETTINGS).(repo.Settings)
+	if !ok {
+		return c.Status(http.StatusInternalServerError).SendString("internal: failed to retrieve settings from context")
+	}
+
+	if err := h.Svc.CheckBatch(c.Context(), *ownerID, len(req.Items)); err != nil {
+		if ok, err := sendQuotaError(c, err); ok {
+			return err
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+
+	results := make([]batchResult, len(req.Items))
+	for i, item := range req.Items {
+		opts := item.options()
+		opts.OwnerID = ownerID
+
+		code, shortURL, err := h.Svc.Shorten(c.Context(), item.URL, item.CustomAlias, opts, settings)
+		if err != nil {
+			_, results[i].Error = shortenError(err)
+			continue
+		}
+		results[i] = batchResult{Code: code, ShortURL: shortURL}
+	}
+	return c.JSON(fiber.Map{"results": results})
+}
+
+func shortenError(err error) (int, string) {
+	switch {
+	case errors.Is(err, short.ErrInvalidURL):
+		return http.StatusBadRequest, "invalid_url"
+	case errors.Is(err, short.ErrInvalidAlias):
+		return http.StatusBadRequest, "invalid_alias"
+	case errors.Is(err, short.ErrInvalidPassword):
+		return http.StatusBadRequest, "invalid_password"
+	case errors.Is(err, short.ErrInvalidMaxClicks):
+		return http.StatusBadRequest, "invalid_max_clicks"
+	case errors.Is(err, short.ErrInvalidRules):
+		return http.StatusBadRequest, "invalid_rules"
+	case errors.Is(err, short.ErrInvalidDestinations):
+		return http.StatusBadRequest, "invalid_destinations"
+	case errors.Is(err, short.ErrInvalidForward):
+		return http.StatusBadRequest, "invalid_forward"
+	case errors.Is(err, short.ErrInvalidTemplate):
+		return http.StatusBadRequest, "invalid_template"
+	case errors.Is(err, short.ErrInvalidUTM):
+		return http.StatusBadRequest, "invalid_utm"
+	case errors.Is(err, short.ErrInvalidDeepLink):
+		return http.StatusBadRequest, "invalid_deep_link"
+	case errors.Is(err, short.ErrInvalidRedirectType):
+		return http.StatusBadRequest, "invalid_redirect_type"
+	case errors.Is(err, short.ErrInvalidSocialCard):
+		return http.StatusBadRequest, "invalid_social_card"
+	case errors.Is(err, short.ErrInvalidFallback):
+		return http.StatusBadRequest, "invalid_fallback"
+	case errors.Is(err, short.ErrConflict):
+		return http.StatusConflict, "conflict"
+	case errors.Is(err, short.ErrQuotaExceeded):
+		return http.StatusPaymentRequired, "quota_exceeded"
+	default:
+		return http.StatusInternalServerError, "internal"
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/emrealsandev/Url-Shortener/internal/pat"
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TokensHandler kişisel erişim token'ları; route'ta SessionRequired arkasındadır.
+type TokensHandler struct{ Svc *pat.Service }
+
+func (h TokensHandler) List(c *fiber.Ctx) error {
+	user, _ := middleware.CurrentUser(c)
+	if user == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	list, err := h.Svc.List(c.Context(), user.ID)
+	if err != nil {
+		return sendTokenError(c, err)
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"tokens": list})
+}
+
+// Create yeni token üretir; token yanıtta bir kez gösterilir, sonra sadece prefix'i görünür.
+func (h TokensHandler) Create(c *fiber.Ctx) error {
+	user, _ := middleware.CurrentUser(c)
+	if user == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	var req struct {
+		Name          string         `json:"name"`
+		Scopes        []policy.Scope `json:"scopes"`
+		ExpiresInDays int            `json:"expires_in_days"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	created, err := h.Svc.Create(c.Context(), user.ID, req.Name, req.Scopes, req.ExpiresInDays)
+	if err != nil {
+		return sendTokenError(c, err)
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(http.StatusCreated).JSON(created)
+}
+
+func (h TokensHandler) Revoke(c *fiber.Ctx) error {
+	user, _ := middleware.CurrentUser(c)
+	if user == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	if err := h.Svc.Revoke(c.Context(), user.ID, c.Params("id")); err != nil {
+		return sendTokenError(c, err)
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func sendTokenError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, pat.ErrInvalidName),
+		errors.Is(err, pat.ErrInvalidScope),
+		errors.Is(err, pat.ErrInvalidExpiry):
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	case errors.Is(err, pat.ErrNotFound):
+		return c.SendStatus(http.StatusNotFound)
+	case errors.Is(err, pat.ErrTooManyTokens):
+		return c.Status(http.StatusConflict).SendString(err.Error())
+	default:
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+}
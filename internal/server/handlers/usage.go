@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/emrealsandev/Url-Shortener/internal/quota"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UsageHandler struct{ Quota *quota.Service }
+
+// Get API anahtarının sahibinin bu ayki kullanımı ve plan limitleri.
+func (h UsageHandler) Get(c *fiber.Ctx) error {
+	ownerID := middleware.OwnerID(c)
+	if ownerID == nil {
+		return c.Status(http.StatusUnauthorized).SendString("api_key_required")
+	}
+
+	report, err := h.Quota.Usage(c.Context(), *ownerID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return c.JSON(report)
+}
+
+// Export faturalama için bir dönemdeki bütün sahiplerin kullanımı (admin). ?format=csv ile CSV döner.
+func (h UsageHandler) Export(c *fiber.Ctx) error {
+	period, err := quota.ParsePeriod(c.Query("period"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).SendString("invalid_period")
+	}
+
+	rows, err := h.Quota.Export(c.Context(), period)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+
+	if c.Query("format") != "csv" {
+		return c.JSON(fiber.Map{"period": period, "owners": rows})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="usage-`+period+`.csv"`)
+	w := csv.NewWriter(c.Response().BodyWriter())
+	_ = w.Write([]string{"owner_id", "period", "links", "custom_aliases", "tracked_clicks", "dropped_clicks", "updated_at"})
+	for _, u := range rows {
+		_ = w.Write([]string{
+			strconv.FormatInt(u.OwnerID, 10), u.Period,
+			strconv.FormatInt(u.Links, 10), strconv.FormatInt(u.CustomAliases, 10),
+			strconv.FormatInt(u.TrackedClicks, 10), strconv.FormatInt(u.DroppedClicks, 10),
+			u.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// SetPlan sahibin planını ve isteğe bağlı özel limitlerini değiştirir (admin).
+func (h UsageHandler) SetPlan(c *fiber.Ctx) error {
+	ownerID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.SendStatus(http.StatusNotFound)
+	}
+
+	var req struct {
+		Plan  string      `json:"plan"`
+		Quota *repo.Quota `json:"quota,omitempty"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	if req.Plan == "" {
+		req.Plan = quota.DEFAULT_PLAN
+	}
+	if err := h.Quota.SetPlan(c.Context(), ownerID, req.Plan, req.Quota); err != nil {
+		if errors.Is(err, quota.ErrInvalidPlan) {
+			return c.Status(http.StatusBadRequest).SendString("invalid_plan")
+		}
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+	return c.JSON(fiber.Map{"owner_id": ownerID, "plan": req.Plan, "quota": req.Quota})
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
+	"github.com/emrealsandev/Url-Shortener/internal/workspace"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WorkspacesHandler workspace, üye ve davet uçları. Yetki route'taki Authorize middleware'indedir;
+// handler'lar Subject'i middleware.CurrentSubject'ten alır.
+type WorkspacesHandler struct{ Svc *workspace.Service }
+
+type nameReq struct {
+	Name string `json:"name"`
+}
+
+func (h WorkspacesHandler) List(c *fiber.Ctx) error {
+	user := middleware.RequestUser(c)
+	if user == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	list, err := h.Svc.List(c.Context(), user.ID)
+	if err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"workspaces": list})
+}
+
+func (h WorkspacesHandler) Create(c *fiber.Ctx) error {
+	user := middleware.RequestUser(c)
+	if user == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+
+	var req nameReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+	w, err := h.Svc.Create(c.Context(), user, req.Name)
+	if err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.Status(http.StatusCreated).JSON(w)
+}
+
+func (h WorkspacesHandler) Get(c *fiber.Ctx) error {
+	sub, _ := middleware.CurrentSubject(c)
+	w, err := h.Svc.Get(c.Context(), sub)
+	if err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.JSON(w)
+}
+
+func (h WorkspacesHandler) Rename(c *fiber.Ctx) error {
+	var req nameReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+	sub, _ := middleware.CurrentSubject(c)
+	w, err := h.Svc.Rename(c.Context(), sub, req.Name)
+	if err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.JSON(w)
+}
+
+func (h WorkspacesHandler) Members(c *fiber.Ctx) error {
+	sub, _ := middleware.CurrentSubject(c)
+	members, err := h.Svc.Members(c.Context(), sub)
+	if err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.JSON(fiber.Map{"members": members})
+}
+
+func (h WorkspacesHandler) SetRole(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("user_id"), 10, 64)
+	if err != nil {
+		return c.SendStatus(http.StatusNotFound)
+	}
+	var req struct {
+		Role policy.Role `json:"role"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	sub, _ := middleware.CurrentSubject(c)
+	m, err := h.Svc.SetRole(c.Context(), sub, userID, req.Role)
+	if err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.JSON(m)
+}
+
+// RemoveMember üyeyi çıkarır; kullanıcı kendi ID'siyle çağırırsa workspace'ten ayrılır.
+func (h WorkspacesHandler) RemoveMember(c *fiber.Ctx) error {
+	userID, err := strconv.ParseInt(c.Params("user_id"), 10, 64)
+	if err != nil {
+		return c.SendStatus(http.StatusNotFound)
+	}
+
+	sub, _ := middleware.CurrentSubject(c)
+	if err := h.Svc.RemoveMember(c.Context(), sub, userID); err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+func (h WorkspacesHandler) Invitations(c *fiber.Ctx) error {
+	sub, _ := middleware.CurrentSubject(c)
+	list, err := h.Svc.Invitations(c.Context(), sub)
+	if err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.JSON(fiber.Map{"invitations": list})
+}
+
+func (h WorkspacesHandler) Invite(c *fiber.Ctx) error {
+	var req struct {
+		Email string      `json:"email"`
+		Role  policy.Role `json:"role"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	sub, _ := middleware.CurrentSubject(c)
+	inv, err := h.Svc.Invite(c.Context(), sub, req.Email, req.Role)
+	if err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.Status(http.StatusCreated).JSON(inv)
+}
+
+func (h WorkspacesHandler) RevokeInvitation(c *fiber.Ctx) error {
+	sub, _ := middleware.CurrentSubject(c)
+	if err := h.Svc.RevokeInvitation(c.Context(), sub, c.Params("invitation_id")); err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// AcceptInvitation davet linkindeki token'la oturumdaki (ya da erişim token'ının) kullanıcıyı workspace'e ekler.
+func (h WorkspacesHandler) AcceptInvitation(c *fiber.Ctx) error {
+	user := middleware.RequestUser(c)
+	if user == nil {
+		return c.Status(http.StatusUnauthorized).SendString("login_required")
+	}
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).SendString("bad_request")
+	}
+
+	w, err := h.Svc.Accept(c.Context(), user, req.Token)
+	if err != nil {
+		return sendWorkspaceError(c, err)
+	}
+	return c.JSON(w)
+}
+
+func sendWorkspaceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, workspace.ErrInvalidName),
+		errors.Is(err, workspace.ErrInvalidRole),
+		errors.Is(err, workspace.ErrInvalidEmail),
+		errors.Is(err, workspace.ErrInvalidInvitation):
+		return c.Status(http.StatusBadRequest).SendString(err.Error())
+	case errors.Is(err, policy.ErrForbidden), errors.Is(err, workspace.ErrInvitationEmail):
+		return c.Status(http.StatusForbidden).SendString(err.Error())
+	case errors.Is(err, workspace.ErrNotFound):
+		return c.SendStatus(http.StatusNotFound)
+	case errors.Is(err, workspace.ErrLastOwner),
+		errors.Is(err, workspace.ErrAlreadyMember),
+		errors.Is(err, workspace.ErrTooManyInvitations):
+		return c.Status(http.StatusConflict).SendString(err.Error())
+	default:
+		return c.Status(http.StatusInternalServerError).SendString("internal")
+	}
+}
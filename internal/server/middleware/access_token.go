@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/emrealsandev/Url-Shortener/internal/pat"
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const LOCALS_ACCESS_TOKEN = "access_token"
+
+// AccessToken "Authorization: Bearer pat_..." ile gelen kişisel erişim token'ını çözer. Token'ın kullanıcısı
+// oturumdaki gibi sahip olur ama yetkisi token'ın kapsamlarıyla sınırlıdır (Workspace middleware'i Subject'e
+// ekler, Authorize kontrol eder). "pat_" ile başlamayan Bearer değerleri (admin token'ı) olduğu gibi geçer;
+// X-API-Key de varsa token'a bakılmaz.
+func AccessToken(svc *pat.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw, ok := bearerAccessToken(c)
+		if !ok || c.Get(HEADER_API_KEY) != "" {
+			return c.Next()
+		}
+
+		user, token, err := svc.Authenticate(c.Context(), raw)
+		if err != nil {
+			if errors.Is(err, pat.ErrInvalidToken) || errors.Is(err, pat.ErrExpired) {
+				return c.Status(fiber.StatusUnauthorized).SendString(err.Error())
+			}
+			return c.Status(fiber.StatusInternalServerError).SendString("internal")
+		}
+
+		c.Locals(LOCALS_ACCESS_TOKEN, token)
+		c.Locals(LOCALS_USER, user)
+		c.Locals(LOCALS_OWNER_ID, user.ID)
+		return c.Next()
+	}
+}
+
+func bearerAccessToken(c *fiber.Ctx) (string, bool) {
+	got := strings.TrimSpace(strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "))
+	return got, pat.IsToken(got)
+}
+
+// RequireScope rol kavramı olmayan uçlar için (workspace listesi, davet kabulü); erişim token'ıyla gelen
+// isteklerde kapsamı kontrol eder, oturum ve API anahtarını olduğu gibi geçirir.
+func RequireScope(scope policy.Scope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if scopes := TokenScopes(c); scopes != nil && !policy.HasScope(scopes, scope) {
+			return c.Status(fiber.StatusForbidden).SendString(policy.ErrInsufficientScope.Error())
+		}
+		return c.Next()
+	}
+}
+
+// SessionRequired sadece web oturumuyla yapılabilen işlemler; erişim token'ı başka token üretemez.
+func SessionRequired() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if user, _ := CurrentUser(c); user != nil {
+			return c.Next()
+		}
+		if AccessTokenOf(c) != nil {
+			return c.Status(fiber.StatusForbidden).SendString("session_required")
+		}
+		return c.Status(fiber.StatusUnauthorized).SendString("login_required")
+	}
+}
+
+// AccessTokenOf isteğin erişim token'ı; yoksa nil.
+func AccessTokenOf(c *fiber.Ctx) *repo.AccessToken {
+	token, _ := c.Locals(LOCALS_ACCESS_TOKEN).(*repo.AccessToken)
+	return token
+}
+
+// TokenScopes erişim token'ıyla gelen isteklerde kapsamlar; oturum ve API anahtarında nil.
+func TokenScopes(c *fiber.Ctx) []policy.Scope {
+	if token := AccessTokenOf(c); token != nil {
+		return pat.Scopes(token)
+	}
+	return nil
+}
+
+// RequestUser oturumdaki ya da erişim token'ının kullanıcısı; API anahtarı ve anonim isteklerde nil.
+func RequestUser(c *fiber.Ctx) *repo.User {
+	user, _ := c.Locals(LOCALS_USER).(*repo.User)
+	return user
+}
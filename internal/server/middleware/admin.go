@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const LOCALS_ADMIN = "admin"
+
+// AdminAuth "Authorization: Bearer <ADMIN_TOKEN>" bekler. Token tanımlı değilse admin uçları tamamen kapalıdır.
+func AdminAuth(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.Status(fiber.StatusForbidden).SendString("admin_disabled")
+		}
+
+		if !validAdminToken(c, token) {
+			return c.Status(fiber.StatusUnauthorized).SendString("unauthorized")
+		}
+
+		return c.Next()
+	}
+}
+
+// AdminOrAuthorize admin token'ıyla gelen isteği her link için geçirir; diğer istekler Authorize'dan geçer ve
+// handler işlemi Subject'in sahibine ait linklerle sınırlamalıdır (bkz. IsAdmin).
+func AdminOrAuthorize(token string, action policy.Action) fiber.Handler {
+	authorize := Authorize(action)
+	return func(c *fiber.Ctx) error {
+		if token != "" && validAdminToken(c, token) {
+			c.Locals(LOCALS_ADMIN, true)
+			return c.Next()
+		}
+		return authorize(c)
+	}
+}
+
+// IsAdmin istek AdminOrAuthorize'da admin token'ıyla geçtiyse true döner.
+func IsAdmin(c *fiber.Ctx) bool {
+	admin, _ := c.Locals(LOCALS_ADMIN).(bool)
+	return admin
+}
+
+func validAdminToken(c *fiber.Ctx, token string) bool {
+	got := strings.TrimSpace(strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "))
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+)
+
+const LOCALS_CLIENT_IP = "client_ip"
+
+// RealIP istemci IP'sini bir kez çözüp locals'a koyar; limiter, analitik ve loglar ClientIP ile okur.
+// Resolver nil ise proxy başlıklarına hiç güvenilmez.
+func RealIP(resolver *security.ClientIPResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(LOCALS_CLIENT_IP, resolver.Resolve(c.IP(), func(h string) string { return c.Get(h) }))
+		return c.Next()
+	}
+}
+
+// ClientIP RealIP'in çözdüğü adres; middleware çalışmadıysa bağlantının karşı ucu.
+func ClientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals(LOCALS_CLIENT_IP).(string); ok && ip != "" {
+		return ip
+	}
+	return c.IP()
+}
+
+// LogClientIP fiber logger'ındaki ${ip} etiketini çözülmüş istemci IP'siyle değiştirir.
+func LogClientIP(output logger.Buffer, c *fiber.Ctx, _ *logger.Data, _ string) (int, error) {
+	return output.WriteString(ClientIP(c))
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/emrealsandev/Url-Shortener/internal/short"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const HEADER_API_KEY = "X-API-Key"
+
+// OwnerAuth "X-API-Key" varsa sahibi çözüp LOCALS_OWNER_ID'ye koyar. Başlık yoksa istek anonim devam eder,
+// geçersiz anahtar ise reddedilir; yoksa yanlış anahtarla gelen istek sessizce anonim sayılırdı.
+func OwnerAuth(svc *short.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HEADER_API_KEY)
+		if key == "" {
+			return c.Next()
+		}
+
+		ownerID, err := svc.OwnerByAPIKey(c.Context(), key)
+		if err != nil {
+			if errors.Is(err, short.ErrInvalidAPIKey) {
+				return c.Status(fiber.StatusUnauthorized).SendString("invalid_api_key")
+			}
+			return c.Status(fiber.StatusInternalServerError).SendString("internal")
+		}
+
+		c.Locals(LOCALS_OWNER_ID, ownerID)
+		return c.Next()
+	}
+}
+
+// OwnerID OwnerAuth'un çözdüğü sahip; anonim isteklerde nil.
+func OwnerID(c *fiber.Ctx) *int64 {
+	if id, ok := c.Locals(LOCALS_OWNER_ID).(int64); ok {
+		return &id
+	}
+	return nil
+}
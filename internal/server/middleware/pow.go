@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/emrealsandev/Url-Shortener/internal/pow"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HEADER_POW_CHALLENGE = "X-PoW-Challenge"
+	HEADER_POW_SOLUTION  = "X-PoW-Solution"
+)
+
+// ProofOfWork ayarlarda açıksa anonim isteklerden çözülmüş bir bulmaca ister.
+// API anahtarıyla gelen istekler (OwnerAuth) muaftır; Settings ve OwnerAuth'tan sonra çalışmalı.
+func ProofOfWork(svc *pow.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		settings, _ := c.Locals(LOCALS_SETTINGS).(repo.Settings)
+		if !pow.Enabled(settings) || OwnerID(c) != nil {
+			return c.Next()
+		}
+
+		if err := svc.Verify(c.Context(), c.Get(HEADER_POW_CHALLENGE), c.Get(HEADER_POW_SOLUTION)); err != nil {
+			switch {
+			case errors.Is(err, pow.ErrRequired):
+				return c.Status(fiber.StatusForbidden).SendString("pow_required")
+			case errors.Is(err, pow.ErrUnavailable):
+				return c.Status(fiber.StatusServiceUnavailable).SendString("pow_unavailable")
+			}
+			return c.Status(fiber.StatusForbidden).SendString("invalid_pow")
+		}
+		return c.Next()
+	}
+}
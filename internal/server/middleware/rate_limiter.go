@@ -1,41 +1,142 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 )
 
-func APILimiter() fiber.Handler {
-	return limiter.New(limiter.Config{
-		// Dakikada en fazla 20 istek.
-		Max: 20,
+// LOCALS_OWNER_ID kimliği doğrulanmış sahibin id'si (int64); koyan middleware limiter'dan önce çalışmalı.
+const LOCALS_OWNER_ID = "owner_id"
+
+const (
+	DEFAULT_RATE_API_ANONYMOUS     = 20
+	DEFAULT_RATE_API_AUTHENTICATED = 300
+	DEFAULT_RATE_REDIRECT          = 5
+	DEFAULT_RATE_REPORT            = 10
+)
+
+// Identity limiter anahtarı. Authenticated doğrulanmış bir API anahtarı veya sahip; anonimler IP ile sayılır.
+type Identity struct {
+	Key           string
+	Authenticated bool
+}
+
+type IdentifyFunc func(c *fiber.Ctx) Identity
 
-		// Limit penceresinin süresi.
-		Expiration: 1 * time.Minute,
+// Identify sırasıyla sahip, geçerli API anahtarı (admin token) ve IP'ye bakar.
+// Doğrulanmamış bir anahtar IP yerine kullanılmaz; yoksa rastgele anahtarlarla limit aşılabilirdi.
+func Identify(adminToken string) IdentifyFunc {
+	return func(c *fiber.Ctx) Identity {
+		if ownerID, ok := c.Locals(LOCALS_OWNER_ID).(int64); ok {
+			return Identity{Key: "owner:" + strconv.FormatInt(ownerID, 10), Authenticated: true}
+		}
+		got := strings.TrimSpace(strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "))
+		if adminToken != "" && got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(adminToken)) == 1 {
+			return Identity{Key: "key:" + keyHash(got), Authenticated: true}
+		}
+		return Identity{Key: "ip:" + ClientIP(c)}
+	}
+}
+
+// keyHash anahtarı Redis'te düz metin tutmamak için kısaltılmış hash.
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
 
-		// Hangi isteğin kime ait olduğunu belirleyen anahtar.
-		// c.IP() kullanmak, her bir IP adresini ayrı ayrı limitlememizi sağlar.
-		KeyGenerator: func(c *fiber.Ctx) string {
-			return c.IP()
+// ratePolicy bir route grubu için pencere ve ayarlardan okunan limit.
+type ratePolicy struct {
+	name   string
+	window time.Duration
+	limit  func(s repo.Settings, authenticated bool) int64
+}
+
+// APILimiter /v1 altındaki tüm istekler; anonimler dakikada 20, doğrulanmış çağıranlar 300 istek.
+func APILimiter(store cache.Cache, identify IdentifyFunc) fiber.Handler {
+	return rateLimiter(store, identify, ratePolicy{
+		name:   "api",
+		window: time.Minute,
+		limit: func(s repo.Settings, authenticated bool) int64 {
+			if authenticated {
+				return rate(s.RateApiAuthenticated, DEFAULT_RATE_API_AUTHENTICATED)
+			}
+			return rate(s.RateApiAnonymous, DEFAULT_RATE_API_ANONYMOUS)
 		},
-		// LimitExceeded alanına gerek yok. Middleware, limit aşıldığında
-		// otomatik olarak 429 status kodu ve "Too Many Requests" mesajını döner.
 	})
 }
 
-func RedirectLimiter() fiber.Handler {
-	return limiter.New(limiter.Config{
-		// Saniyede en fazla 5 istek (dakikada 300 istek).
-		// Aynı IP'den çok hızlı ve tekrar tekrar tıklanmasını engeller.
-		Max: 5,
-
-		// Burst (ani) trafiği engellemek için pencereyi kısa tutuyoruz.
-		Expiration: 1 * time.Second,
+// RedirectLimiter aynı ziyaretçinin saniyede 5'ten fazla tıklamasını engeller.
+func RedirectLimiter(store cache.Cache, identify IdentifyFunc) fiber.Handler {
+	return rateLimiter(store, identify, ratePolicy{
+		name:   "redirect",
+		window: time.Second,
+		limit: func(s repo.Settings, _ bool) int64 {
+			return rate(s.RateRedirect, DEFAULT_RATE_REDIRECT)
+		},
+	})
+}
 
-		KeyGenerator: func(c *fiber.Ctx) string {
-			return c.IP()
+// ReportLimiter saatte en fazla 10 şikayet, kuyruğu spam ile doldurmasınlar.
+func ReportLimiter(store cache.Cache, identify IdentifyFunc) fiber.Handler {
+	return rateLimiter(store, identify, ratePolicy{
+		name:   "report",
+		window: time.Hour,
+		limit: func(s repo.Settings, _ bool) int64 {
+			return rate(s.RateReport, DEFAULT_RATE_REPORT)
 		},
 	})
 }
+
+func rate(setting int16, def int64) int64 {
+	if setting == 0 {
+		return def
+	}
+	return int64(setting)
+}
+
+// rateLimiter sayaçlar Redis'te tutulduğu için limitler restart'ta sıfırlanmaz ve replikalar arasında paylaşılır.
+func rateLimiter(store cache.Cache, identify IdentifyFunc, p ratePolicy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		settings, _ := c.Locals(LOCALS_SETTINGS).(repo.Settings)
+		id := identify(c)
+		limit := p.limit(settings, id.Authenticated)
+		if limit < 0 {
+			return c.Next()
+		}
+
+		res, err := store.RateLimit(c.Context(), "rl:"+p.name+":"+id.Key, limit, p.window)
+		if err != nil {
+			// Redis'e ulaşılamıyorsa tüm trafiği kesmek yerine limitsiz devam ediyoruz.
+			return c.Next()
+		}
+
+		reset := strconv.FormatInt(ceilSeconds(res.Reset), 10)
+		c.Set("RateLimit-Limit", strconv.FormatInt(limit, 10))
+		c.Set("RateLimit-Remaining", strconv.FormatInt(max(res.Remaining, 0), 10))
+		c.Set("RateLimit-Reset", reset)
+		c.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit, ceilSeconds(p.window)))
+		if !res.Allowed {
+			c.Set(fiber.HeaderRetryAfter, reset)
+			return c.Status(fiber.StatusTooManyRequests).SendString("rate_limited")
+		}
+		return c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	s := int64((d + time.Second - 1) / time.Second)
+	if s < 1 {
+		return 1
+	}
+	return s
+}
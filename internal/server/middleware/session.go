@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/emrealsandev/Url-Shortener/internal/account"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	LOCALS_SESSION = "session"
+	LOCALS_USER    = "user"
+	HEADER_CSRF    = "X-CSRF-Token"
+)
+
+// Session web arayüzünün oturum cookie'sini çözer; geçerliyse kullanıcı aynı zamanda linklerin sahibi olur
+// (LOCALS_OWNER_ID). Cookie tarayıcı tarafından her istekte gönderildiği için GET/HEAD/OPTIONS dışındaki
+// isteklerde X-CSRF-Token başlığı oturumdakiyle eşleşmelidir. X-API-Key ya da erişim token'ıyla gelen istekler
+// cookie'ye bakılmadan OwnerAuth'a / AccessToken'a bırakılır.
+func Session(svc *account.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Cookies(account.SESSION_COOKIE)
+		if _, hasToken := bearerAccessToken(c); token == "" || hasToken || c.Get(HEADER_API_KEY) != "" {
+			return c.Next()
+		}
+
+		user, sess, err := svc.Authenticate(c.Context(), token)
+		if errors.Is(err, account.ErrInvalidSession) {
+			ClearSessionCookie(c)
+			return c.Next()
+		}
+		if err != nil {
+			// Redis/Mongo hatası: oturumsuz devam, cookie silinmez
+			return c.Next()
+		}
+
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		default:
+			if !sess.ValidCSRF(c.Get(HEADER_CSRF)) {
+				return c.Status(fiber.StatusForbidden).SendString("invalid_csrf")
+			}
+		}
+
+		c.Locals(LOCALS_SESSION, sess)
+		c.Locals(LOCALS_USER, user)
+		c.Locals(LOCALS_OWNER_ID, user.ID)
+		return c.Next()
+	}
+}
+
+// CurrentUser Session middleware'inin çözdüğü kullanıcı ve oturum; oturum yoksa nil.
+func CurrentUser(c *fiber.Ctx) (*repo.User, *account.Session) {
+	user, _ := c.Locals(LOCALS_USER).(*repo.User)
+	sess, _ := c.Locals(LOCALS_SESSION).(*account.Session)
+	if user == nil || sess == nil {
+		return nil, nil
+	}
+	return user, sess
+}
+
+func SetSessionCookie(c *fiber.Ctx, token string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     account.SESSION_COOKIE,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(account.SESSION_TTL.Seconds()),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+func ClearSessionCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     account.SESSION_COOKIE,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
@@ -2,11 +2,13 @@ package middleware
 
 import (
 	"github.com/emrealsandev/Url-Shortener/internal/config"
-	"github.com/emrealsandev/Url-Shortener/internal/repo"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// LOCALS_SETTINGS ayarların isteğin context'inde tutulduğu anahtar; middleware ve handler'lar hep bunu okur.
+const LOCALS_SETTINGS = "settings"
+
 func Settings(provider *config.Provider) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		settings, err := provider.Get()
@@ -17,7 +19,7 @@ func Settings(provider *config.Provider) fiber.Handler {
 		}
 
 		// Ayarları isteğin context'ine ("locals") koyuyoruz.
-		c.Locals(repo.COLLECTION_SETTINGS, settings)
+		c.Locals(LOCALS_SETTINGS, settings)
 
 		// Bir sonraki middleware veya handler'a geç.
 		return c.Next()
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	LOCALS_SUBJECT   = "subject"
+	HEADER_WORKSPACE = "X-Workspace-ID"
+)
+
+// Workspace isteğin hangi sahip adına yapıldığını belirler. "X-Workspace-ID" yoksa kullanıcının (ya da API
+// anahtarı sahibinin) kişisel alanıdır; varsa kullanıcı o workspace'in üyesi olmalıdır ve LOCALS_OWNER_ID
+// workspace'e çevrilir. Böylece link listesi, kısaltma, kota ve ayarlar workspace'e göre çalışır.
+// Session ve OwnerAuth'tan sonra, limiter'dan önce çalışmalı.
+func Workspace(svc *policy.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw := c.Get(HEADER_WORKSPACE)
+		ownerID := OwnerID(c)
+		if ownerID == nil {
+			if raw != "" {
+				return c.Status(fiber.StatusUnauthorized).SendString("login_required")
+			}
+			return c.Next()
+		}
+
+		if raw == "" {
+			var userID *int64
+			if user := RequestUser(c); user != nil {
+				userID = &user.ID
+			}
+			sub := policy.Personal(userID, *ownerID)
+			sub.Scopes = TokenScopes(c)
+			c.Locals(LOCALS_SUBJECT, sub)
+			return c.Next()
+		}
+
+		if ok, err := enterWorkspace(c, svc, raw); !ok {
+			return err
+		}
+		return c.Next()
+	}
+}
+
+// WorkspaceParam "/v1/workspaces/:id/..." uçları için; başlık yerine path'teki ID kullanılır. Oturum ya da
+// erişim token'ıyla.
+func WorkspaceParam(svc *policy.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if RequestUser(c) == nil {
+			return c.Status(fiber.StatusUnauthorized).SendString("login_required")
+		}
+		if ok, err := enterWorkspace(c, svc, c.Params("id")); !ok {
+			return err
+		}
+		return c.Next()
+	}
+}
+
+// enterWorkspace Subject'i workspace'e çevirir; false dönerse yanıt yazılmıştır ve zincir durmalıdır.
+func enterWorkspace(c *fiber.Ctx, svc *policy.Service, raw string) (bool, error) {
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return false, c.Status(fiber.StatusNotFound).SendString("workspace_not_found")
+	}
+
+	user := RequestUser(c)
+	if user == nil {
+		// API anahtarı tek bir sahibe bağlıdır; workspace'in kendi anahtarı başlıkla da kullanılabilir.
+		if ownerID := OwnerID(c); ownerID != nil && *ownerID == id {
+			c.Locals(LOCALS_SUBJECT, policy.Personal(nil, id))
+			return true, nil
+		}
+		return false, c.Status(fiber.StatusForbidden).SendString("forbidden")
+	}
+
+	sub, err := svc.Workspace(c.Context(), user.ID, id)
+	if err != nil {
+		if errors.Is(err, policy.ErrNotMember) {
+			return false, c.Status(fiber.StatusNotFound).SendString("workspace_not_found")
+		}
+		return false, c.Status(fiber.StatusInternalServerError).SendString("internal")
+	}
+
+	sub.Scopes = TokenScopes(c)
+	c.Locals(LOCALS_SUBJECT, sub)
+	c.Locals(LOCALS_OWNER_ID, sub.OwnerID)
+	return true, nil
+}
+
+// Authorize Subject'in action yetkisi yoksa isteği reddeder; sahibi olmayan (anonim) isteklere 401. Rol
+// yetmiyorsa "forbidden", erişim token'ının kapsamı yetmiyorsa "insufficient_scope".
+func Authorize(action policy.Action) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sub, ok := CurrentSubject(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).SendString("login_required")
+		}
+		if err := policy.Authorize(sub, action); err != nil {
+			return c.Status(fiber.StatusForbidden).SendString(err.Error())
+		}
+		return c.Next()
+	}
+}
+
+// AuthorizeIfOwned anonim istekleri olduğu gibi geçirir (anonim kısaltma gibi); sahibi olan istekler
+// Authorize ile aynı kontrolden geçer.
+func AuthorizeIfOwned(action policy.Action) fiber.Handler {
+	authorize := Authorize(action)
+	return func(c *fiber.Ctx) error {
+		if _, ok := CurrentSubject(c); !ok {
+			return c.Next()
+		}
+		return authorize(c)
+	}
+}
+
+// CurrentSubject Workspace middleware'inin belirlediği Subject; anonim isteklerde false.
+func CurrentSubject(c *fiber.Ctx) (policy.Subject, bool) {
+	sub, ok := c.Locals(LOCALS_SUBJECT).(policy.Subject)
+	return sub, ok
+}
@@ -1,16 +1,42 @@
 package server
 
 import (
+	"github.com/emrealsandev/Url-Shortener/internal/account"
+	"github.com/emrealsandev/Url-Shortener/internal/cache"
 	"github.com/emrealsandev/Url-Shortener/internal/config"
+	"github.com/emrealsandev/Url-Shortener/internal/moderation"
+	"github.com/emrealsandev/Url-Shortener/internal/oidc"
+	"github.com/emrealsandev/Url-Shortener/internal/pat"
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+	"github.com/emrealsandev/Url-Shortener/internal/pow"
+	"github.com/emrealsandev/Url-Shortener/internal/quota"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
 	"github.com/emrealsandev/Url-Shortener/internal/server/docs"
 	handlers2 "github.com/emrealsandev/Url-Shortener/internal/server/handlers"
 	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
 	"github.com/emrealsandev/Url-Shortener/internal/short"
+	"github.com/emrealsandev/Url-Shortener/internal/workspace"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func registerRoutes(app *fiber.App, svc *short.Service, settingsProvider *config.Provider) {
+type routeDeps struct {
+	svc              *short.Service
+	moderation       *moderation.Service
+	account          *account.Service
+	oidc             *oidc.Client
+	policy           *policy.Service
+	workspaces       *workspace.Service
+	tokens           *pat.Service
+	quota            *quota.Service
+	pow              *pow.Service
+	settingsProvider *config.Provider
+	cache            cache.Cache
+	signer           *security.Signer
+	adminToken       string
+}
+
+func registerRoutes(app *fiber.App, d routeDeps) {
 
 	// Serve static files (frontend)
 	app.Static("/static", "./web/static")
@@ -19,13 +45,25 @@ func registerRoutes(app *fiber.App, svc *short.Service, settingsProvider *config
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendFile("./web/index.html")
 	})
+	app.Get("/account", func(c *fiber.Ctx) error {
+		return c.SendFile("./web/account.html")
+	})
+
+	// limiter anahtarı: sahip, API anahtarı ya da IP
+	identify := middleware.Identify(d.adminToken)
 
 	// api
 	api := app.Group("/v1")
 
+	// Session, AccessToken, OwnerAuth ve Workspace limiter'dan önce; oturumlu, token'lı ve API anahtarlı
+	// istekler sahip (X-Workspace-ID varsa workspace) bazında limitlenir.
 	api.Use(
-		middleware.Settings(settingsProvider),
-		middleware.APILimiter(),
+		middleware.Settings(d.settingsProvider),
+		middleware.Session(d.account),
+		middleware.AccessToken(d.tokens),
+		middleware.OwnerAuth(d.svc),
+		middleware.Workspace(d.policy),
+		middleware.APILimiter(d.cache, identify),
 	)
 
 	// health
@@ -36,11 +74,101 @@ func registerRoutes(app *fiber.App, svc *short.Service, settingsProvider *config
 	api.Get("/docs", docs.SwaggerUI)
 	api.Get("/docs/swagger.json", docs.SwaggerJSON)
 
-	api.Post("/shorten", handlers2.ShortenHandler{Svc: svc}.Serve)
+	// anonim kısaltma için proof-of-work
+	api.Get("/challenge", handlers2.ChallengeHandler{Pow: d.pow}.Get)
+
+	shortenHandler := handlers2.ShortenHandler{Svc: d.svc}
+	// yetki kontrolleri route bazında policy action'larıyla (erişim token'ının kapsamı dahil); anonim kısaltma serbest
+	api.Post("/shorten", middleware.AuthorizeIfOwned(policy.LINKS_WRITE), middleware.ProofOfWork(d.pow), shortenHandler.Serve)
+	api.Post("/shorten/batch", middleware.AuthorizeIfOwned(policy.LINKS_WRITE), shortenHandler.ServeBatch)
+
+	// hesap ve oturum
+	authHandler := handlers2.AuthHandler{Svc: d.account}
+	auth := api.Group("/auth")
+	auth.Post("/register", authHandler.Register)
+	auth.Post("/login", authHandler.Login)
+	auth.Post("/logout", authHandler.Logout)
+	auth.Get("/me", authHandler.Me)
+	auth.Post("/password/forgot", authHandler.ForgotPassword)
+	auth.Post("/password/reset", authHandler.ResetPassword)
+
+	// SSO
+	oidcHandler := handlers2.OIDCHandler{Client: d.oidc, Accounts: d.account}
+	auth.Get("/providers", oidcHandler.Providers)
+	auth.Get("/oidc/login", oidcHandler.Login)
+	auth.Get("/oidc/callback", oidcHandler.Callback)
+
+	myLinksHandler := handlers2.MyLinksHandler{Svc: d.svc}
+	api.Get("/me/links", middleware.Authorize(policy.LINKS_READ), myLinksHandler.List)
+	api.Get("/me/links/:code/stats", middleware.Authorize(policy.STATS_READ), myLinksHandler.Stats)
+	api.Delete("/me/links/:code", middleware.Authorize(policy.LINKS_WRITE), myLinksHandler.Disable)
+	mySettingsHandler := handlers2.MySettingsHandler{Svc: d.svc}
+	api.Get("/me/settings", middleware.Authorize(policy.SETTINGS_READ), mySettingsHandler.Get)
+	api.Put("/me/settings", middleware.Authorize(policy.SETTINGS_WRITE), mySettingsHandler.Put)
+
+	// workspace'ler; :id'li uçlarda Subject path'teki workspace'e göre belirlenir
+	workspacesHandler := handlers2.WorkspacesHandler{Svc: d.workspaces}
+	inWorkspace := middleware.WorkspaceParam(d.policy)
+	accountScope := middleware.RequireScope(policy.SCOPE_ADMIN)
+	api.Get("/workspaces", accountScope, workspacesHandler.List)
+	api.Post("/workspaces", accountScope, workspacesHandler.Create)
+	api.Get("/workspaces/:id", inWorkspace, middleware.Authorize(policy.MEMBERS_READ), workspacesHandler.Get)
+	api.Patch("/workspaces/:id", inWorkspace, middleware.Authorize(policy.WORKSPACE_MANAGE), workspacesHandler.Rename)
+	api.Get("/workspaces/:id/members", inWorkspace, middleware.Authorize(policy.MEMBERS_READ), workspacesHandler.Members)
+	api.Put("/workspaces/:id/members/:user_id", inWorkspace, middleware.Authorize(policy.MEMBERS_MANAGE), workspacesHandler.SetRole)
+	// her üye kendi üyeliğinden ayrılabilir; başkasını çıkarmak için rol kontrolü servistedir
+	api.Delete("/workspaces/:id/members/:user_id", inWorkspace, middleware.Authorize(policy.MEMBERS_READ), workspacesHandler.RemoveMember)
+	api.Get("/workspaces/:id/invitations", inWorkspace, middleware.Authorize(policy.MEMBERS_MANAGE), workspacesHandler.Invitations)
+	api.Post("/workspaces/:id/invitations", inWorkspace, middleware.Authorize(policy.MEMBERS_MANAGE), workspacesHandler.Invite)
+	api.Delete("/workspaces/:id/invitations/:invitation_id", inWorkspace, middleware.Authorize(policy.MEMBERS_MANAGE), workspacesHandler.RevokeInvitation)
+	api.Post("/invitations/accept", accountScope, workspacesHandler.AcceptInvitation)
+
+	// kişisel erişim token'ları; token'lar sadece oturumla yönetilir
+	tokensHandler := handlers2.TokensHandler{Svc: d.tokens}
+	sessionOnly := middleware.SessionRequired()
+	api.Get("/tokens", sessionOnly, tokensHandler.List)
+	api.Post("/tokens", sessionOnly, tokensHandler.Create)
+	api.Delete("/tokens/:id", sessionOnly, tokensHandler.Revoke)
+
+	// kota ve kullanım
+	usageHandler := handlers2.UsageHandler{Quota: d.quota}
+	api.Get("/usage", middleware.AuthorizeIfOwned(policy.USAGE_READ), usageHandler.Get)
+	api.Post("/report/:code", middleware.ReportLimiter(d.cache, identify), handlers2.ReportHandler{Svc: d.moderation}.Serve)
+
+	// link yönetimi; admin token'ı her linke, sahip ve workspace üyeleri rollerine göre kendi linklerine erişir
+	linksHandler := handlers2.LinksHandler{Svc: d.svc}
+	api.Put("/links/:code/schedule", middleware.AdminOrAuthorize(d.adminToken, policy.LINKS_WRITE), linksHandler.Schedule)
+	api.Get("/links/:code/stats", middleware.AdminOrAuthorize(d.adminToken, policy.STATS_READ), linksHandler.Stats)
+	api.Get("/links/:code/health", middleware.AdminOrAuthorize(d.adminToken, policy.LINKS_READ), linksHandler.Health)
+	api.Post("/links/:code/metadata/refresh", middleware.AdminOrAuthorize(d.adminToken, policy.LINKS_WRITE), linksHandler.RefreshMetadata)
+	// önizleme herkese açık, tıklama sayılmaz
+	api.Get("/links/:code/preview", linksHandler.Preview)
+
+	// admin
+	adminAuth := middleware.AdminAuth(d.adminToken)
+	admin := api.Group("/admin", adminAuth)
+
+	moderationHandler := handlers2.ModerationHandler{Svc: d.moderation}
+	admin.Get("/reports", moderationHandler.List)
+	admin.Post("/reports/:id/accept", moderationHandler.Accept)
+	admin.Post("/reports/:id/dismiss", moderationHandler.Dismiss)
+
+	ownersHandler := handlers2.OwnersHandler{Svc: d.svc}
+	admin.Put("/owners/:id/utm", ownersHandler.SetUTM)
+	admin.Post("/owners/:id/api-key", ownersHandler.CreateAPIKey)
+	admin.Put("/owners/:id/plan", usageHandler.SetPlan)
+	admin.Get("/usage/export", usageHandler.Export)
 
 	// v1 altında olmadığı için api grubuna dahil değil.
-	app.Get("/:code",
-		middleware.Settings(settingsProvider),
-		middleware.RedirectLimiter(),
-		handlers2.RedirectHandler{Svc: svc}.Serve)
+	redirectHandler := handlers2.RedirectHandler{Svc: d.svc, Signer: d.signer}
+	// "/:code/*" hem "/abc" hem "/abc/ek/path" ile eşleşir; ek path PathForward linkler için.
+	// "/abc+" önizleme sayfasıdır, handler içinde ayrılır.
+	app.Get("/:code/*",
+		middleware.Settings(d.settingsProvider),
+		middleware.RedirectLimiter(d.cache, identify),
+		redirectHandler.Serve)
+	app.Post("/:code/*",
+		middleware.Settings(d.settingsProvider),
+		middleware.RedirectLimiter(d.cache, identify),
+		redirectHandler.Unlock)
 }
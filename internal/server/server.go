@@ -3,10 +3,24 @@ package server
 import (
 	"context"
 	"fmt"
+	"github.com/emrealsandev/Url-Shortener/internal/account"
 	"github.com/emrealsandev/Url-Shortener/internal/cache"
 	"github.com/emrealsandev/Url-Shortener/internal/config"
+	"github.com/emrealsandev/Url-Shortener/internal/geoip"
+	"github.com/emrealsandev/Url-Shortener/internal/health"
+	"github.com/emrealsandev/Url-Shortener/internal/mail"
+	"github.com/emrealsandev/Url-Shortener/internal/metadata"
+	"github.com/emrealsandev/Url-Shortener/internal/moderation"
+	"github.com/emrealsandev/Url-Shortener/internal/oidc"
+	"github.com/emrealsandev/Url-Shortener/internal/pat"
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+	"github.com/emrealsandev/Url-Shortener/internal/pow"
+	"github.com/emrealsandev/Url-Shortener/internal/quota"
 	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+	"github.com/emrealsandev/Url-Shortener/internal/server/middleware"
 	"github.com/emrealsandev/Url-Shortener/internal/short"
+	"github.com/emrealsandev/Url-Shortener/internal/workspace"
 	"log"
 	"time"
 
@@ -19,16 +33,34 @@ import (
 )
 
 type Options struct {
-	Port    string
-	BaseURL string
-	Repo    repo.Repository
-	Cache   cache.Cache
-	Logger  loggerInterface.Logger
+	Port       string
+	BaseURL    string
+	AdminToken string
+	SecretKey  string
+	Repo       repo.Repository
+	ReportRepo repo.ReportRepository
+	UserRepo   repo.UserRepository
+	// WorkspaceRepo workspace, üyelik ve davetler.
+	WorkspaceRepo repo.WorkspaceRepository
+	// AccessTokenRepo kullanıcıların kişisel erişim token'ları.
+	AccessTokenRepo repo.AccessTokenRepository
+	Cache           cache.Cache
+	GeoIP           geoip.Locator
+	Logger          loggerInterface.Logger
+	// ClientIP güvenilen proxy'lerin arkasında gerçek istemci IP'sini çözer; nil → bağlantı adresi.
+	ClientIP *security.ClientIPResolver
+	// Mailer nil ise e-postalar sadece loglanır.
+	Mailer mail.Sender
+	// OIDC nil ise SSO kapalıdır.
+	OIDC *oidc.Client
 }
 
 type Server struct {
-	app *fiber.App
-	opt Options
+	app      *fiber.App
+	opt      Options
+	metadata *metadata.Worker
+	health   *health.Checker
+	quota    *quota.Service
 }
 
 func New(opt Options) *Server {
@@ -40,23 +72,62 @@ func New(opt Options) *Server {
 
 	// Middlewares
 	app.Use(recover.New())
-	app.Use(logger.New()) // bu fiberin loggeri bizim logical olan farklı!
+	app.Use(middleware.RealIP(opt.ClientIP))
+	app.Use(logger.New(logger.Config{
+		CustomTags: map[string]logger.LogFunc{"ip": middleware.LogClientIP},
+	})) // bu fiberin loggeri bizim logical olan farklı!
 	app.Use(compress.New())
 
 	settingsProvider := config.NewProvider(opt.Repo, opt.Cache, repo.COLLECTION_SETTINGS)
 
-	svc := short.NewService(opt.Repo, opt.Cache, opt.BaseURL, opt.Logger)
+	svc := short.NewService(opt.Repo, opt.Cache, opt.BaseURL, opt.Logger, opt.GeoIP)
+	metadataWorker := metadata.NewWorker(metadata.NewFetcher(), opt.Repo, opt.Logger)
+	svc.SetMetadataQueue(metadataWorker)
+	quotaSvc := quota.NewService(opt.Repo, opt.Cache, opt.Logger)
+	svc.SetQuota(quotaSvc)
+	mailer := opt.Mailer
+	if mailer == nil {
+		mailer = mail.FileSender{Logger: opt.Logger}
+	}
+	healthNotifier := health.MailNotifier{
+		Users:      opt.UserRepo,
+		Workspaces: opt.WorkspaceRepo,
+		Mail:       mailer,
+		BaseURL:    opt.BaseURL,
+		Logger:     opt.Logger,
+	}
+	healthChecker := health.NewChecker(svc, opt.Repo, settingsProvider, opt.Cache, healthNotifier, opt.Logger)
+	moderationSvc := moderation.NewService(opt.ReportRepo, opt.Repo, svc, opt.Logger)
+	signer := security.NewSigner(opt.SecretKey)
 
 	// Routes
-	registerRoutes(app, svc, settingsProvider)
+	registerRoutes(app, routeDeps{
+		svc:              svc,
+		moderation:       moderationSvc,
+		account:          account.NewService(opt.UserRepo, opt.Cache, mailer, opt.BaseURL, opt.Logger),
+		oidc:             opt.OIDC,
+		policy:           policy.NewService(opt.WorkspaceRepo, opt.Logger),
+		workspaces:       workspace.NewService(opt.WorkspaceRepo, opt.UserRepo, mailer, opt.BaseURL, opt.Logger),
+		tokens:           pat.NewService(opt.AccessTokenRepo, opt.UserRepo, opt.Logger),
+		quota:            quotaSvc,
+		pow:              pow.NewService(signer, opt.Cache, opt.Logger),
+		settingsProvider: settingsProvider,
+		cache:            opt.Cache,
+		signer:           signer,
+		adminToken:       opt.AdminToken,
+	})
 
-	return &Server{app: app, opt: opt}
+	return &Server{app: app, opt: opt, metadata: metadataWorker, health: healthChecker, quota: quotaSvc}
 }
 
 func (s *Server) Start(ctx context.Context) error {
 	addr := ":" + s.opt.Port
 	log.Println("listening on", addr)
 
+	s.metadata.Start(ctx)
+	go s.health.Run(ctx)
+	go s.quota.Run(ctx)
+
 	// Fiber listen’i ayrı goroutine’de; context iptaliyle kapanalım
 	errCh := make(chan error, 1)
 	go func() { errCh <- s.app.Listen(addr) }()
@@ -0,0 +1,28 @@
+package views
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed templates/*.html
+var files embed.FS
+
+var templates = template.Must(template.ParseFS(files, "templates/*.html"))
+
+// Render şablonu çalıştırır ve no-store/noindex başlıklarıyla HTML olarak döner.
+// Yarım kalmış bir sayfa gönderilmesin diye önce buffer'a yazılır.
+func Render(c *fiber.Ctx, status int, name string, data any) error {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("internal")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set("X-Robots-Tag", "noindex, nofollow")
+	c.Type("html", "utf-8")
+	return c.Status(status).Send(buf.Bytes())
+}
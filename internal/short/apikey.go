@@ -0,0 +1,52 @@
+package short
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidAPIKey = errors.New("invalid_api_key")
+
+const API_KEY_PREFIX = "usk_"
+
+// HashAPIKey anahtarlar DB'de sha256 olarak tutulur; rastgele ve uzun oldukları için bcrypt'e gerek yok.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateOwnerAPIKey sahibe yeni bir API anahtarı üretir ve eskisini geçersiz kılar. Anahtar sadece burada döner.
+func (s *Service) CreateOwnerAPIKey(ctx context.Context, ownerID int64) (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", ErrSystem
+	}
+	key := API_KEY_PREFIX + base64.RawURLEncoding.EncodeToString(b[:])
+
+	if err := s.repo.SetOwnerAPIKey(ctx, ownerID, HashAPIKey(key)); err != nil {
+		s.logger.Error("set owner api key failed", "owner_id", ownerID, "error", err)
+		return "", ErrSystem
+	}
+	return key, nil
+}
+
+// OwnerByAPIKey anahtarın sahibini döner.
+func (s *Service) OwnerByAPIKey(ctx context.Context, key string) (int64, error) {
+	if !strings.HasPrefix(key, API_KEY_PREFIX) {
+		return 0, ErrInvalidAPIKey
+	}
+	owner, err := s.repo.GetOwnerByAPIKey(ctx, HashAPIKey(key))
+	if err != nil {
+		s.logger.Error("api key lookup failed", "error", err)
+		return 0, ErrSystem
+	}
+	if owner == nil {
+		return 0, ErrInvalidAPIKey
+	}
+	return owner.ID, nil
+}
@@ -0,0 +1,69 @@
+package short
+
+import (
+	"context"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/pkg/useragent"
+)
+
+// recordClick yönlendirmeyi bekletmemek için tıklamayı ayrı goroutine'de yazar.
+func (s *Service) recordClick(code string, res *Resolution, req Request) {
+	agent := useragent.Parse(req.UserAgent)
+	click := repo.Click{
+		Code:      code,
+		Variant:   res.Variant,
+		Device:    agent.Device,
+		OS:        agent.OS,
+		CreatedAt: time.Now().UTC(),
+	}
+	if s.geo != nil {
+		click.Country = s.geo.Country(req.IP)
+	}
+
+	go func() {
+		// Kota dolduysa yönlendirme yapılır ama tıklama kaydedilmez.
+		if !s.trackClick(context.Background(), res.OwnerID) {
+			return
+		}
+		if err := s.repo.InsertClick(context.Background(), click); err != nil {
+			s.logger.Error("record click failed", "code", code, "error", err)
+		}
+	}()
+}
+
+// VariantStats varyant bazında tıklama sayıları.
+type VariantStats struct {
+	Code     string           `json:"code"`
+	Total    int64            `json:"total"`
+	Variants map[string]int64 `json:"variants"`
+}
+
+func (s *Service) Stats(ctx context.Context, code string) (*VariantStats, error) {
+	u, err := s.repo.GetByCode(code)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	if u == nil {
+		return nil, ErrNotFound
+	}
+
+	counts, err := s.repo.CountClicksByVariant(ctx, code)
+	if err != nil {
+		return nil, ErrSystem
+	}
+
+	stats := &VariantStats{Code: code, Variants: map[string]int64{}}
+	// Tanımlı ama hiç tıklanmamış varyantlar da 0 ile görünsün.
+	for _, d := range u.Destinations {
+		stats.Variants[d.Name] = 0
+	}
+	for variant, n := range counts {
+		stats.Total += n
+		if variant != "" {
+			stats.Variants[variant] = n
+		}
+	}
+	return stats, nil
+}
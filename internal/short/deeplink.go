@@ -0,0 +1,148 @@
+package short
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+	"github.com/emrealsandev/Url-Shortener/pkg/useragent"
+)
+
+var ErrInvalidDeepLink = errors.New("invalid_deep_link")
+
+const MAX_APP_URL_LEN = 2048
+
+var (
+	schemeRe  = regexp.MustCompile(`^[a-z][a-z0-9+.\-]*$`)
+	packageRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)+$`)
+
+	// Tarayıcıda kod çalıştırabilen veya bizim ürettiğimiz şemalar uygulama adresi olamaz.
+	blockedSchemes = map[string]bool{
+		"javascript": true, "data": true, "vbscript": true, "file": true, "about": true, "blob": true, "intent": true,
+	}
+)
+
+// AppLaunch mobil ziyaretçi için uygulama açma kararı. Bridge ise düz 302 yeterli değildir,
+// ara sayfa uygulamayı açmayı dener ve süre dolunca Fallback'e gider.
+type AppLaunch struct {
+	URL      string
+	Fallback string
+	Bridge   bool
+}
+
+func validateDeepLink(d *repo.DeepLink) (*repo.DeepLink, error) {
+	if d == nil {
+		return nil, nil
+	}
+	out := repo.DeepLink{AndroidPackage: strings.TrimSpace(d.AndroidPackage)}
+
+	var err error
+	if out.IOSURL, err = validateAppURL(d.IOSURL); err != nil {
+		return nil, err
+	}
+	if out.AndroidURL, err = validateAppURL(d.AndroidURL); err != nil {
+		return nil, err
+	}
+	if out.IOSStoreURL, err = optionalWebURL(d.IOSStoreURL); err != nil {
+		return nil, err
+	}
+	if out.AndroidStoreURL, err = optionalWebURL(d.AndroidStoreURL); err != nil {
+		return nil, err
+	}
+	if out.FallbackURL, err = optionalWebURL(d.FallbackURL); err != nil {
+		return nil, err
+	}
+
+	if out.AndroidPackage != "" && !packageRe.MatchString(out.AndroidPackage) {
+		return nil, ErrInvalidDeepLink
+	}
+	if out.IOSURL == "" && out.AndroidURL == "" && out.AndroidPackage == "" {
+		return nil, ErrInvalidDeepLink
+	}
+	return &out, nil
+}
+
+func optionalWebURL(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	normalized, err := security.NormalizeUrl(raw)
+	if err != nil {
+		return "", ErrInvalidDeepLink
+	}
+	return normalized, nil
+}
+
+// validateAppURL http(s) adreslerini normalize eder, özel şemalarda sadece şema adını kontrol eder.
+func validateAppURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if len(raw) > MAX_APP_URL_LEN {
+		return "", ErrInvalidDeepLink
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return "", ErrInvalidDeepLink
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "http" || scheme == "https" {
+		return optionalWebURL(raw)
+	}
+	if !schemeRe.MatchString(scheme) || blockedSchemes[scheme] {
+		return "", ErrInvalidDeepLink
+	}
+	return raw, nil
+}
+
+// appLaunch ziyaretçinin platformuna göre uygulama açma davranışını seçer; masaüstü ve botlarda nil döner.
+func appLaunch(d *repo.DeepLink, agent useragent.Info, target string) *AppLaunch {
+	if d == nil || agent.Device == useragent.DEVICE_BOT {
+		return nil
+	}
+
+	switch agent.OS {
+	case useragent.OS_IOS:
+		if d.IOSURL == "" {
+			return nil
+		}
+		return &AppLaunch{
+			URL:      d.IOSURL,
+			Fallback: firstNonEmpty(d.IOSStoreURL, firstNonEmpty(d.FallbackURL, target)),
+			Bridge:   !isWebURL(d.IOSURL),
+		}
+	case useragent.OS_ANDROID:
+		fallback := firstNonEmpty(d.AndroidStoreURL, firstNonEmpty(d.FallbackURL, target))
+		if d.AndroidPackage != "" {
+			return &AppLaunch{
+				URL:      intentURL(firstNonEmpty(d.AndroidURL, target), d.AndroidPackage, fallback),
+				Fallback: fallback,
+				Bridge:   true,
+			}
+		}
+		if d.AndroidURL == "" {
+			return nil
+		}
+		return &AppLaunch{URL: d.AndroidURL, Fallback: fallback, Bridge: !isWebURL(d.AndroidURL)}
+	}
+	return nil
+}
+
+// intentURL Chrome'un uygulamayı açtığı, yüklü değilse browser_fallback_url'e gittiği intent:// adresini üretir.
+func intentURL(appURL, pkg, fallback string) string {
+	scheme, rest, _ := strings.Cut(appURL, ":")
+	rest = strings.TrimPrefix(rest, "//")
+	rest, _, _ = strings.Cut(rest, "#")
+
+	return "intent://" + rest + "#Intent;scheme=" + strings.ToLower(scheme) + ";package=" + pkg +
+		";S.browser_fallback_url=" + url.QueryEscape(fallback) + ";end"
+}
+
+func isWebURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
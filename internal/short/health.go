@@ -0,0 +1,140 @@
+package short
+
+import (
+	"context"
+	"errors"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+)
+
+var ErrInvalidFallback = errors.New("invalid_fallback")
+
+const (
+	HEALTH_HISTORY_LIMIT          = 20
+	DEFAULT_HEALTH_FAIL_THRESHOLD = 3
+	MAX_FALLBACK_TARGETS          = 5
+)
+
+// validateFallbacks sırayı koruyarak normalize eder; tekrar edenler ve ana hedefin kendisi atlanır.
+func validateFallbacks(targets []string, primary string) ([]string, error) {
+	if len(targets) > MAX_FALLBACK_TARGETS {
+		return nil, ErrInvalidFallback
+	}
+	var out []string
+	seen := map[string]bool{primary: true}
+	for _, t := range targets {
+		normalized, err := security.NormalizeUrl(t)
+		if err != nil {
+			return nil, ErrInvalidFallback
+		}
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		out = append(out, normalized)
+	}
+	return out, nil
+}
+
+// Health linkin son kontrol sonuçları; hiç kontrol edilmediyse durum "unknown".
+func (s *Service) Health(ctx context.Context, code string) (*repo.LinkHealth, error) {
+	u, err := s.repo.GetByCode(code)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	if u == nil {
+		return nil, ErrNotFound
+	}
+	if u.Health == nil {
+		return &repo.LinkHealth{Status: repo.HEALTH_UNKNOWN}, nil
+	}
+	return u.Health, nil
+}
+
+// RecordHealth kontrol sonucunu geçmişe ekler ve art arda hata sayısına göre durumu günceller.
+// fallbacks nil ise yedeklerin önceki sonuçları korunur. Durum değiştiyse yeni durumu döner;
+// durum veya kullanılan yedek değiştiyse cache silinir ki Resolve hemen yeni hedefe geçsin.
+func (s *Service) RecordHealth(ctx context.Context, u repo.URL, check repo.HealthCheck, fallbacks []repo.FallbackHealth, settings repo.Settings) (string, error) {
+	h := repo.LinkHealth{Status: repo.HEALTH_UNKNOWN}
+	if u.Health != nil {
+		h = *u.Health
+	}
+	prevStatus, prevActive := h.Status, h.ActiveTarget
+
+	h.LastCheckedAt = check.CheckedAt
+	h.History = append(h.History, check)
+	if len(h.History) > HEALTH_HISTORY_LIMIT {
+		h.History = h.History[len(h.History)-HEALTH_HISTORY_LIMIT:]
+	}
+	if fallbacks != nil {
+		h.Fallbacks = fallbacks
+	}
+
+	threshold := DEFAULT_HEALTH_FAIL_THRESHOLD
+	if settings.HealthFailThreshold > 0 {
+		threshold = int(settings.HealthFailThreshold)
+	}
+
+	if check.OK {
+		h.ConsecutiveFailures = 0
+		h.Status = repo.HEALTH_HEALTHY
+		h.DegradedSince = nil
+	} else {
+		h.ConsecutiveFailures++
+		if h.ConsecutiveFailures >= threshold && h.Status != repo.HEALTH_DEGRADED {
+			h.Status = repo.HEALTH_DEGRADED
+			since := check.CheckedAt
+			h.DegradedSince = &since
+		}
+	}
+
+	h.ActiveTarget = ""
+	if h.Status == repo.HEALTH_DEGRADED {
+		h.ActiveTarget = firstHealthy(u.FallbackTargets, h.Fallbacks)
+	}
+	switch {
+	case h.ActiveTarget == prevActive:
+	case h.ActiveTarget != "":
+		h.Failovers++
+		at := check.CheckedAt
+		h.LastFailoverAt = &at
+		s.logger.Warn("link failover", "code", u.Code, "from", firstNonEmpty(prevActive, u.Target), "to", h.ActiveTarget, "failovers", h.Failovers)
+	default:
+		s.logger.Info("link failback", "code", u.Code, "from", prevActive, "to", u.Target)
+	}
+
+	if err := s.repo.SetHealth(ctx, u.Code, h); err != nil {
+		return "", err
+	}
+
+	if h.ActiveTarget != prevActive {
+		s.invalidateCache(ctx, u.Code, u.Target)
+	}
+	// Sadece degraded'a giriş/çıkış bildirilir.
+	if (prevStatus == repo.HEALTH_DEGRADED) != (h.Status == repo.HEALTH_DEGRADED) {
+		return h.Status, nil
+	}
+	return "", nil
+}
+
+// firstHealthy link sırasına göre son kontrolü başarılı olan ilk yedeği döner; hiçbiri yoksa boş.
+// Yedekler de çalışmıyorsa ana hedefe gitmeye devam edilir.
+func firstHealthy(targets []string, results []repo.FallbackHealth) string {
+	for _, t := range targets {
+		for _, r := range results {
+			if r.Target == t && r.OK {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// activeFallback degraded linkte yönlendirilecek yedek hedef.
+func activeFallback(h *repo.LinkHealth) string {
+	if h == nil || h.Status != repo.HEALTH_DEGRADED {
+		return ""
+	}
+	return h.ActiveTarget
+}
@@ -0,0 +1,245 @@
+package short
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+const DEFAULT_REDIRECT_CACHE_TTL = time.Hour
+
+// NotStartedError link henüz yayında değil; handler "yakında" cevabı için başlangıç zamanını kullanır.
+type NotStartedError struct {
+	StartsAt time.Time
+}
+
+func (e *NotStartedError) Error() string { return ErrNotStarted.Error() }
+
+func (e *NotStartedError) Unwrap() error { return ErrNotStarted }
+
+// LinkOptions link oluşturulurken verilebilen opsiyonel ayarlar.
+type LinkOptions struct {
+	// OwnerID API anahtarıyla gelen isteklerde linkin sahibi; sahipli linkler kotaya sayılır ve dedupe edilmez.
+	OwnerID      *int64
+	Interstitial *bool
+	Password     string
+	// MaxClicks bu kadar yönlendirmeden sonra link 410 döner; 1 → tek kullanımlık.
+	MaxClicks *int64
+	// StartsAt bu zamandan önce link çözülmez.
+	StartsAt *time.Time
+	Rules    []repo.RoutingRule
+	// Destinations A/B ağırlıklı hedefler; Sticky ile ziyaretçi bazında sabitlenir.
+	Destinations []repo.Destination
+	Sticky       bool
+	// QueryForward "merge" | "override"; PathForward linki önek olarak kullanır.
+	QueryForward string
+	PathForward  bool
+	// Template doluysa hedef URL "{isim}" yer tutucuları içeren bir şablondur.
+	Template *repo.TemplateConfig
+	// UTM yönlendirmede hedefe eklenecek parametreler.
+	UTM *repo.UTMParams
+	// DeepLink mobilde uygulamayı açma ayarları.
+	DeepLink *repo.DeepLink
+	// RedirectType 301/302/307/308; 0 → ayarlardaki varsayılan.
+	RedirectType int
+	// SocialCard botlara gösterilecek OpenGraph başlık/açıklama/görsel.
+	SocialCard *repo.SocialCard
+	// FallbackTargets hedef sağlık kontrolünde "degraded" olursa sırayla denenecek adresler.
+	FallbackTargets []string
+}
+
+func (o LinkOptions) IsZero() bool {
+	return o.OwnerID == nil && o.Interstitial == nil && o.Password == "" && o.MaxClicks == nil && o.StartsAt == nil &&
+		len(o.Rules) == 0 && len(o.Destinations) == 0 && o.QueryForward == "" && !o.PathForward &&
+		o.Template == nil && o.UTM == nil && o.DeepLink == nil &&
+		o.RedirectType == 0 && o.SocialCard == nil && len(o.FallbackTargets) == 0
+}
+
+// Request yönlendirme isteğine ait, çözümlemeyi etkileyen bilgiler.
+type Request struct {
+	// Unlocked şifreli link için geçerli bir kilit açma çerezi var mı.
+	Unlocked bool
+	// Confirmed ara sayfadaki "devam et" token'ı doğrulandı mı.
+	Confirmed bool
+	// DryRun HEAD ve link önizleme botu isteklerinde tıklama sayılmaz.
+	DryRun bool
+	// Crawler istek bir sosyal medya / mesajlaşma önizleme botundan geliyor.
+	Crawler bool
+
+	// Yönlendirme kuralları için istek bilgileri.
+	IP             string
+	UserAgent      string
+	AcceptLanguage string
+	Query          map[string]string
+	// VisitorID sticky A/B seçimi için çerezden gelen ziyaretçi kimliği.
+	VisitorID string
+
+	// Suffix koddan sonra gelen path (escape edilmiş haliyle), RawQuery ham query string.
+	Suffix   string
+	RawQuery string
+}
+
+// Resolution Resolve sonucunda handler'ın ihtiyaç duyduğu bilgiler.
+type Resolution struct {
+	Target       string
+	Variant      string
+	Sticky       bool
+	Interstitial bool
+	Anonymous    bool
+	Flagged      bool
+	// App doluysa düz yönlendirme yerine uygulamayı açan ara sayfa gösterilir.
+	App *AppLaunch
+	// Status yönlendirme kodu; MaxAge 0 ise tarayıcı cache'lememeli.
+	Status int
+	MaxAge time.Duration
+	// Failover ana hedef bozuk olduğu için yedek hedefe gidiliyor.
+	Failover bool
+	// OwnerID tıklama kotası için linkin sahibi.
+	OwnerID *int64
+	// Card doluysa istek bir önizleme botundan geliyor ve yönlendirme yerine OpenGraph sayfası gösterilmeli.
+	Card *repo.SocialCard
+	// Capped tıklama limitli link; hak harcamayan isteklere (HEAD, bot) hedef gösterilmemeli.
+	Capped bool
+}
+
+// cachedLink "c:<code>" altında JSON olarak tutulur; karar için gereken link bilgilerini taşır.
+type cachedLink struct {
+	Target       string               `json:"target"`
+	Anonymous    bool                 `json:"anonymous,omitempty"`
+	Flagged      bool                 `json:"flagged,omitempty"`
+	Interstitial *bool                `json:"interstitial,omitempty"`
+	Protected    bool                 `json:"protected,omitempty"`
+	StartsAt     *time.Time           `json:"starts_at,omitempty"`
+	ExpiresAt    *time.Time           `json:"expires_at,omitempty"`
+	Rules        []repo.RoutingRule   `json:"rules,omitempty"`
+	Destinations []repo.Destination   `json:"destinations,omitempty"`
+	Sticky       bool                 `json:"sticky,omitempty"`
+	QueryForward string               `json:"query_forward,omitempty"`
+	PathForward  bool                 `json:"path_forward,omitempty"`
+	Template     *repo.TemplateConfig `json:"template,omitempty"`
+	// UTM sahip ve link şablonlarının birleşmiş hali.
+	UTM      *repo.UTMParams `json:"utm,omitempty"`
+	DeepLink *repo.DeepLink  `json:"deep_link,omitempty"`
+	// Capped tıklama limitli linkler zaten cache'e yazılmaz; sadece tarayıcı cache kararı için.
+	Capped       bool             `json:"-"`
+	RedirectType int              `json:"redirect_type,omitempty"`
+	SocialCard   *repo.SocialCard `json:"social_card,omitempty"`
+	// Failover ana hedef bozukken kullanılan yedek hedef. Seçim değişince cache siliniyor.
+	Failover     string `json:"failover,omitempty"`
+	HasFallbacks bool   `json:"has_fallbacks,omitempty"`
+	OwnerID      *int64 `json:"owner_id,omitempty"`
+}
+
+func newCachedLink(u repo.URL) cachedLink {
+	return cachedLink{
+		Target:       u.Target,
+		Anonymous:    u.OwnerID == nil,
+		Flagged:      u.Flagged,
+		Interstitial: u.Interstitial,
+		Protected:    u.PasswordHash != "",
+		StartsAt:     u.StartsAt,
+		ExpiresAt:    u.ExpiresAt,
+		Rules:        u.Rules,
+		Destinations: u.Destinations,
+		Sticky:       u.Sticky,
+		QueryForward: u.QueryForward,
+		PathForward:  u.PathForward,
+		Template:     u.Template,
+		UTM:          u.UTM,
+		DeepLink:     u.DeepLink,
+		Capped:       u.MaxClicks != nil,
+		RedirectType: u.RedirectType,
+		SocialCard:   u.SocialCard,
+		Failover:     activeFallback(u.Health),
+		HasFallbacks: len(u.FallbackTargets) > 0,
+		OwnerID:      u.OwnerID,
+	}
+}
+
+func (l cachedLink) resolve(req Request, settings repo.Settings) (*Resolution, error) {
+	now := time.Now().UTC()
+	if l.StartsAt != nil && now.Before(*l.StartsAt) {
+		return nil, &NotStartedError{StartsAt: *l.StartsAt}
+	}
+	if l.ExpiresAt != nil && l.ExpiresAt.Before(now) {
+		return nil, ErrExpired
+	}
+
+	// Önek veya şablon olmayan linklerde ek path eskisi gibi 404.
+	if req.Suffix != "" && !l.PathForward && l.Template == nil {
+		return nil, ErrNotFound
+	}
+
+	if l.Protected && !req.Unlocked {
+		return nil, ErrPasswordRequired
+	}
+	r := l.resolution(settings)
+	if req.Confirmed {
+		r.Interstitial = false
+	}
+	return r, nil
+}
+
+func (l cachedLink) resolution(settings repo.Settings) *Resolution {
+	r := &Resolution{Target: l.Target, Anonymous: l.Anonymous, Flagged: l.Flagged, OwnerID: l.OwnerID, Capped: l.Capped}
+
+	switch {
+	case l.Flagged && settings.InterstitialFlagged:
+		r.Interstitial = true
+	case l.Interstitial != nil:
+		r.Interstitial = *l.Interstitial
+	default:
+		r.Interstitial = l.Anonymous && settings.InterstitialAnonymous
+	}
+	return r
+}
+
+// dynamicTarget hedef kural, şablon veya A/B ile istek anında seçiliyorsa true döner.
+func (l cachedLink) dynamicTarget() bool {
+	return len(l.Rules) > 0 || len(l.Destinations) > 0 || l.Template != nil
+}
+
+// redirectStatus link, yoksa ayarlardaki kodu kullanır; geçersiz değerlerde 302'ye düşer.
+func (l cachedLink) redirectStatus(settings repo.Settings) int {
+	for _, status := range []int{l.RedirectType, int(settings.DefaultRedirectType)} {
+		if ValidRedirectType(status) {
+			return status
+		}
+	}
+	return http.StatusFound
+}
+
+// browserMaxAge sadece kalıcı ve her istekte aynı cevabı veren linkler tarayıcıda cache'lenebilir;
+// kural, A/B, şifre, limit, bot kartı gibi ziyaretçiye veya zamana göre değişen durumlarda 0 döner.
+func (l cachedLink) browserMaxAge(res *Resolution, settings repo.Settings) time.Duration {
+	if res.Status != http.StatusMovedPermanently && res.Status != http.StatusPermanentRedirect {
+		return 0
+	}
+	if len(l.Rules) > 0 || len(l.Destinations) > 0 || l.DeepLink != nil || l.Protected || l.Capped ||
+		l.SocialCard != nil || l.HasFallbacks || res.Interstitial || res.App != nil {
+		return 0
+	}
+
+	maxAge := DEFAULT_REDIRECT_CACHE_TTL
+	if settings.RedirectCacheTtl > 0 {
+		maxAge = time.Duration(settings.RedirectCacheTtl) * time.Minute
+	}
+	if l.ExpiresAt != nil {
+		if left := time.Until(*l.ExpiresAt); left < maxAge {
+			maxAge = left
+		}
+	}
+	if maxAge < time.Second {
+		return 0
+	}
+	return maxAge
+}
+
+func ValidRedirectType(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
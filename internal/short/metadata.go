@@ -0,0 +1,53 @@
+package short
+
+import (
+	"context"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+// MetadataQueue hedef sayfa bilgisini çeken arka plan işçisi (metadata.Worker).
+type MetadataQueue interface {
+	Enqueue(code, target string)
+	Refresh(ctx context.Context, code, target string) (*repo.Metadata, error)
+}
+
+// SetMetadataQueue verilmezse metadata çekilmez.
+func (s *Service) SetMetadataQueue(q MetadataQueue) {
+	s.metadata = q
+}
+
+// wantsMetadata şablon hedefler gerçek bir sayfa değil, şifreli linklerin başlığı da önizlemede sızmamalı.
+func wantsMetadata(u repo.URL) bool {
+	return u.Template == nil && u.PasswordHash == ""
+}
+
+func (s *Service) enqueueMetadata(u repo.URL) {
+	if s.metadata != nil && wantsMetadata(u) {
+		s.metadata.Enqueue(u.Code, u.Target)
+	}
+}
+
+// RefreshMetadata metadata'yı hemen yeniden çeker. Çekim başarısız olsa da sonuç (Error alanıyla) kaydedilip döner.
+func (s *Service) RefreshMetadata(ctx context.Context, code string) (*repo.Metadata, error) {
+	if s.metadata == nil {
+		return nil, ErrSystem
+	}
+	u, err := s.repo.GetByCode(code)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	if u == nil {
+		return nil, ErrNotFound
+	}
+	if !wantsMetadata(*u) {
+		return nil, ErrNotFound
+	}
+
+	md, err := s.metadata.Refresh(ctx, code, u.Target)
+	if md == nil {
+		s.logger.Error("metadata refresh failed", "code", code, "error", err)
+		return nil, ErrSystem
+	}
+	return md, nil
+}
@@ -0,0 +1,116 @@
+package short
+
+import (
+	"context"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+const MAX_OWNED_LINKS_PAGE = 100
+
+// OwnedLink sahibin link listesinde görünen alanlar; şifre, kurallar vb. dönmez.
+type OwnedLink struct {
+	Code      string     `json:"code"`
+	ShortURL  string     `json:"short_url"`
+	Target    string     `json:"target"`
+	Title     string     `json:"title,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Disabled  bool       `json:"disabled"`
+	Protected bool       `json:"protected"`
+	Clicks    int64      `json:"clicks,omitempty"`
+	MaxClicks *int64     `json:"max_clicks,omitempty"`
+}
+
+// OwnerLinks sahibin linklerini yeniden eskiye döner.
+func (s *Service) OwnerLinks(ctx context.Context, ownerID int64, limit, offset int64) ([]OwnedLink, error) {
+	if limit <= 0 || limit > MAX_OWNED_LINKS_PAGE {
+		limit = MAX_OWNED_LINKS_PAGE
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	urls, err := s.repo.ListByOwner(ctx, ownerID, limit, offset)
+	if err != nil {
+		s.logger.Error("list owner links failed", "owner_id", ownerID, "error", err)
+		return nil, ErrSystem
+	}
+
+	out := make([]OwnedLink, 0, len(urls))
+	for _, u := range urls {
+		l := OwnedLink{
+			Code:      u.Code,
+			ShortURL:  s.baseURL + "/" + u.Code,
+			Target:    u.Target,
+			CreatedAt: u.CreatedAt,
+			StartsAt:  u.StartsAt,
+			ExpiresAt: u.ExpiresAt,
+			Disabled:  u.Disabled,
+			Protected: u.PasswordHash != "",
+			Clicks:    u.Clicks,
+			MaxClicks: u.MaxClicks,
+		}
+		if u.Metadata != nil {
+			l.Title = u.Metadata.Title
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+// DisableOwned sahibin kendi linkini kapatır. Tekrar açma sadece admin'dedir; moderasyonla kapatılmış
+// linkler sahibi tarafından açılamamalı.
+func (s *Service) DisableOwned(ctx context.Context, ownerID int64, code string) error {
+	if _, err := s.owned(ownerID, code); err != nil {
+		return err
+	}
+	return s.Disable(ctx, code)
+}
+
+// OwnedStats sahibin linkinin tıklama sayıları.
+func (s *Service) OwnedStats(ctx context.Context, ownerID int64, code string) (*VariantStats, error) {
+	if _, err := s.owned(ownerID, code); err != nil {
+		return nil, err
+	}
+	return s.Stats(ctx, code)
+}
+
+// OwnerSettings sahibin (kişisel alan ya da workspace) kendi değiştirebildiği ayarlar.
+type OwnerSettings struct {
+	OwnerID int64           `json:"owner_id"`
+	UTM     *repo.UTMParams `json:"utm"`
+}
+
+func (s *Service) OwnerSettings(ctx context.Context, ownerID int64) (*OwnerSettings, error) {
+	owner, err := s.repo.GetOwner(ctx, ownerID)
+	if err != nil {
+		s.logger.Error("owner lookup failed", "owner_id", ownerID, "error", err)
+		return nil, ErrSystem
+	}
+	out := &OwnerSettings{OwnerID: ownerID}
+	if owner != nil {
+		out.UTM = owner.UTM
+	}
+	return out, nil
+}
+
+// CheckOwned link sahibine ait değilse (ya da yoksa) ErrNotFound döner.
+func (s *Service) CheckOwned(ownerID int64, code string) error {
+	_, err := s.owned(ownerID, code)
+	return err
+}
+
+// owned başkasının linki için de ErrNotFound döner; linkin varlığı belli olmaz.
+func (s *Service) owned(ownerID int64, code string) (*repo.URL, error) {
+	u, err := s.repo.GetByCode(code)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	if u == nil || u.OwnerID == nil || *u.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	return u, nil
+}
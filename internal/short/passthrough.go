@@ -0,0 +1,58 @@
+package short
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+// reservedParams bizim kullandığımız, hedefe taşınmaması gereken query parametreleri.
+var reservedParams = []string{"t", UTM_FLAG}
+
+// passthrough gelen path ve query'i link ayarlarına göre hedefe ekler.
+func passthrough(target string, l cachedLink, req Request) (string, error) {
+	if (!l.PathForward || req.Suffix == "") && (l.QueryForward == "" || req.RawQuery == "") {
+		return target, nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", ErrSystem
+	}
+
+	if l.PathForward && req.Suffix != "" {
+		suffix, err := url.PathUnescape(req.Suffix)
+		if err != nil {
+			return "", ErrNotFound
+		}
+		// Önce kökten temizliyoruz ki ".." segmentleri hedef path'in dışına çıkamasın.
+		trailing := strings.HasSuffix(suffix, "/")
+		u = u.JoinPath(path.Clean("/" + suffix))
+		if trailing && !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+	}
+
+	if l.QueryForward != "" && req.RawQuery != "" {
+		incoming, err := url.ParseQuery(req.RawQuery)
+		if err == nil {
+			for _, p := range reservedParams {
+				incoming.Del(p)
+			}
+			u.RawQuery = mergeQuery(u.Query(), incoming, l.QueryForward == repo.QUERY_FORWARD_OVERRIDE).Encode()
+		}
+	}
+	return u.String(), nil
+}
+
+func mergeQuery(base, incoming url.Values, override bool) url.Values {
+	for k, v := range incoming {
+		if _, exists := base[k]; exists && !override {
+			continue
+		}
+		base[k] = v
+	}
+	return base
+}
@@ -0,0 +1,69 @@
+package short
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidPassword = errors.New("invalid_password")
+	ErrWrongPassword   = errors.New("wrong_password")
+	ErrTooManyAttempts = errors.New("too_many_attempts")
+)
+
+const (
+	MAX_PASSWORD_LENGTH = 72 // bcrypt sınırı
+
+	UNLOCK_WINDOW         = 15 * time.Minute
+	UNLOCK_MAX_PER_CLIENT = 5
+	UNLOCK_MAX_PER_CODE   = 50
+)
+
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	if len(password) > MAX_PASSWORD_LENGTH {
+		return "", ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", ErrSystem
+	}
+	return string(hash), nil
+}
+
+// Unlock şifreyi doğrular. Deneme sayısı hem istemci hem kod bazında Redis'te sayılır;
+// dağıtık denemeler de kod limitine takılır.
+func (s *Service) Unlock(ctx context.Context, code, password, client string) error {
+	u, err := s.active(code)
+	if err != nil {
+		return err
+	}
+	if u.PasswordHash == "" {
+		return nil
+	}
+
+	perClient, err := s.cache.Incr(ctx, "pw:"+code+":"+client, UNLOCK_WINDOW)
+	if err != nil {
+		s.logger.Error("unlock throttle failed", "code", code, "error", err)
+		return ErrSystem
+	}
+	perCode, err := s.cache.Incr(ctx, "pw:"+code, UNLOCK_WINDOW)
+	if err != nil {
+		s.logger.Error("unlock throttle failed", "code", code, "error", err)
+		return ErrSystem
+	}
+	if perClient > UNLOCK_MAX_PER_CLIENT || perCode > UNLOCK_MAX_PER_CODE {
+		return ErrTooManyAttempts
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		return ErrWrongPassword
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package short
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+const (
+	SAFETY_OK         = "ok"
+	SAFETY_UNVERIFIED = "unverified"
+	SAFETY_FLAGGED    = "flagged"
+)
+
+// Preview linke tıklamadan önce gösterilen bilgiler. Şifreli, tıklama limitli ve henüz yayına girmemiş linklerde
+// hedef gizlenir.
+type Preview struct {
+	Code      string     `json:"code"`
+	Target    string     `json:"target,omitempty"`
+	Domain    string     `json:"domain,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Dynamic hedef ziyaretçiye göre değişebilir (kural, A/B, şablon, deep link); Target varsayılan hedeftir.
+	Dynamic   bool `json:"dynamic"`
+	Protected bool `json:"protected"`
+	// Scheduled StartsAt henüz gelmedi; kampanya hedefi yayından önce görünmesin.
+	Scheduled bool `json:"scheduled"`
+	// Capped tek kullanımlık/limitli link; önizleme hak harcamadığı için hedef gösterilmez.
+	Capped bool   `json:"capped"`
+	Safety string `json:"safety"`
+	// Metadata hedef sayfanın başlık/açıklaması; henüz çekilmediyse boş.
+	Metadata *repo.Metadata `json:"metadata,omitempty"`
+}
+
+// Preview link bilgisini DB'den okur; yönlendirme olmadığı için tıklama sayılmaz ve limit harcanmaz.
+func (s *Service) Preview(ctx context.Context, code string) (*Preview, error) {
+	u, err := s.repo.GetByCode(code)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	if u == nil || u.Disabled {
+		return nil, ErrNotFound
+	}
+	if u.ExpiresAt != nil && u.ExpiresAt.Before(time.Now().UTC()) {
+		return nil, ErrNotFound
+	}
+	if u.MaxClicks != nil && u.Clicks >= *u.MaxClicks {
+		return nil, ErrGone
+	}
+
+	now := time.Now().UTC()
+	p := &Preview{
+		Code:      u.Code,
+		CreatedAt: u.CreatedAt,
+		StartsAt:  u.StartsAt,
+		ExpiresAt: u.ExpiresAt,
+		Dynamic:   len(u.Rules) > 0 || len(u.Destinations) > 0 || u.Template != nil || u.DeepLink != nil,
+		Protected: u.PasswordHash != "",
+		Scheduled: u.StartsAt != nil && now.Before(*u.StartsAt),
+		Capped:    u.MaxClicks != nil,
+		Safety:    SAFETY_OK,
+	}
+	switch {
+	case u.Flagged:
+		p.Safety = SAFETY_FLAGGED
+	case u.OwnerID == nil:
+		p.Safety = SAFETY_UNVERIFIED
+	}
+
+	if !p.Protected && !p.Scheduled && !p.Capped {
+		p.Target = u.Target
+		p.Metadata = u.Metadata
+		if t, err := url.Parse(u.Target); err == nil {
+			p.Domain = t.Hostname()
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,55 @@
+package short
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+// codeRepo önizlemenin okuduğu tek linki döner; diğer metodlar kullanılmaz.
+type codeRepo struct {
+	repo.Repository
+	u repo.URL
+}
+
+func (r codeRepo) GetByCode(code string) (*repo.URL, error) {
+	if code != r.u.Code {
+		return nil, nil
+	}
+	u := r.u
+	return &u, nil
+}
+
+func TestPreviewHidesCappedTarget(t *testing.T) {
+	one := int64(1)
+	md := &repo.Metadata{Title: "Gizli"}
+	tests := []struct {
+		name   string
+		u      repo.URL
+		capped bool
+	}{
+		{name: "capped", u: repo.URL{Code: "once", Target: "https://secret.example.com/a", MaxClicks: &one, Metadata: md}, capped: true},
+		{name: "uncapped", u: repo.URL{Code: "open", Target: "https://example.com/a", Metadata: md}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.u.CreatedAt = time.Now()
+			s := NewService(codeRepo{u: tt.u}, nil, "http://sho.rt", logger.GetLogger(), nil)
+
+			p, err := s.Preview(context.Background(), tt.u.Code)
+			if err != nil {
+				t.Fatalf("Preview: %v", err)
+			}
+			if p.Capped != tt.capped {
+				t.Errorf("capped = %v, want %v", p.Capped, tt.capped)
+			}
+			revealed := p.Target != "" || p.Domain != "" || p.Metadata != nil
+			if revealed == tt.capped {
+				t.Errorf("target = %q, domain = %q, metadata = %v", p.Target, p.Domain, p.Metadata)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package short
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("quota_exceeded")
+
+const (
+	QUOTA_LINKS_PER_MONTH = "links_per_month"
+	QUOTA_CUSTOM_ALIASES  = "custom_aliases"
+	QUOTA_TRACKED_CLICKS  = "tracked_clicks_per_month"
+	QUOTA_MAX_BATCH_SIZE  = "max_batch_size"
+)
+
+// QuotaExceededError hangi kotanın dolduğunu taşır. ResetAt doluysa kota yeni dönemde açılır (429),
+// boşsa ancak plan değişince açılır (402).
+type QuotaExceededError struct {
+	Quota   string
+	Limit   int64
+	Used    int64
+	ResetAt *time.Time
+}
+
+func (e *QuotaExceededError) Error() string { return ErrQuotaExceeded.Error() }
+
+func (e *QuotaExceededError) Unwrap() error { return ErrQuotaExceeded }
+
+// Quota sahip bazlı kota kontrolü ve kullanım sayımı; atanmamışsa kota uygulanmaz.
+type Quota interface {
+	// ReserveLink limit dolmadıysa link (ve alias) sayacını artırır.
+	ReserveLink(ctx context.Context, ownerID int64, customAlias bool) error
+	// ReleaseLink kaydedilemeyen linkin ayırdığı hakkı geri verir.
+	ReleaseLink(ctx context.Context, ownerID int64, customAlias bool)
+	// TrackClick tıklama kotası dolduysa false döner; yönlendirme yine yapılır, sadece kaydedilmez.
+	TrackClick(ctx context.Context, ownerID int64) bool
+	CheckBatch(ctx context.Context, ownerID int64, size int) error
+}
+
+func (s *Service) SetQuota(q Quota) {
+	s.quota = q
+}
+
+// CheckBatch toplu kısaltmada istenen link sayısının planın izin verdiği boyutu aşmadığını kontrol eder.
+func (s *Service) CheckBatch(ctx context.Context, ownerID int64, size int) error {
+	if s.quota == nil {
+		return nil
+	}
+	return s.quota.CheckBatch(ctx, ownerID, size)
+}
+
+func (s *Service) reserveLink(ctx context.Context, ownerID *int64, customAlias bool) error {
+	if ownerID == nil || s.quota == nil {
+		return nil
+	}
+	return s.quota.ReserveLink(ctx, *ownerID, customAlias)
+}
+
+func (s *Service) releaseLink(ctx context.Context, ownerID *int64, customAlias bool) {
+	if ownerID == nil || s.quota == nil {
+		return
+	}
+	s.quota.ReleaseLink(ctx, *ownerID, customAlias)
+}
+
+func (s *Service) trackClick(ctx context.Context, ownerID *int64) bool {
+	if ownerID == nil || s.quota == nil {
+		return true
+	}
+	return s.quota.TrackClick(ctx, *ownerID)
+}
@@ -2,25 +2,41 @@ package short
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/emrealsandev/Url-Shortener/internal/cache"
 	"github.com/emrealsandev/Url-Shortener/internal/config"
+	"github.com/emrealsandev/Url-Shortener/internal/geoip"
 	"github.com/emrealsandev/Url-Shortener/internal/logger"
 	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/routing"
 	"github.com/emrealsandev/Url-Shortener/internal/security"
 	"github.com/emrealsandev/Url-Shortener/pkg/base62"
+	"github.com/emrealsandev/Url-Shortener/pkg/useragent"
 	"strconv"
 	"strings"
 	"time"
 )
 
 var (
-	ErrInvalidURL = errors.New("invalid_url")
-	ErrConflict   = errors.New("conflict")
-	ErrExpired    = errors.New("expired")
-	ErrNotFound   = errors.New("not_found")
-	ErrSequence   = errors.New("sequence_error")
-	ErrSystem     = errors.New("system_error")
+	ErrInvalidURL   = errors.New("invalid_url")
+	ErrInvalidAlias = errors.New("invalid_alias")
+	ErrConflict     = errors.New("conflict")
+	ErrExpired      = errors.New("expired")
+	ErrNotFound     = errors.New("not_found")
+	ErrSequence     = errors.New("sequence_error")
+	ErrSystem       = errors.New("system_error")
+
+	ErrPasswordRequired = errors.New("password_required")
+	ErrGone             = errors.New("gone")
+	ErrInvalidMaxClicks = errors.New("invalid_max_clicks")
+	ErrNotStarted       = errors.New("not_started")
+	ErrInvalidSchedule  = errors.New("invalid_schedule")
+	ErrInvalidRules     = errors.New("invalid_rules")
+
+	ErrInvalidDestinations = errors.New("invalid_destinations")
+	ErrInvalidForward      = errors.New("invalid_forward")
+	ErrInvalidRedirectType = errors.New("invalid_redirect_type")
 )
 
 type Service struct {
@@ -28,40 +44,68 @@ type Service struct {
 	cache   cache.Cache
 	baseURL string
 	logger  logger.Logger
+	geo     geoip.Locator
+	// metadata opsiyonel; SetMetadataQueue ile verilir.
+	metadata MetadataQueue
+	// quota opsiyonel; SetQuota ile verilir.
+	quota Quota
 }
 
-func NewService(r repo.Repository, c cache.Cache, baseURL string, logger logger.Logger) *Service {
-	return &Service{repo: r, cache: c, baseURL: baseURL, logger: logger}
+func NewService(r repo.Repository, c cache.Cache, baseURL string, logger logger.Logger, geo geoip.Locator) *Service {
+	return &Service{repo: r, cache: c, baseURL: baseURL, logger: logger, geo: geo}
 }
 
-func (s *Service) Shorten(ctx context.Context, inputURL string, customAlias *string, settings repo.Settings) (string, string, error) {
+func (s *Service) Shorten(ctx context.Context, inputURL string, customAlias *string, opts LinkOptions, settings repo.Settings) (string, string, error) {
 
-	target, err := security.NormalizeUrl(inputURL)
-	if err != nil {
-		return "", "", ErrInvalidURL
+	var target string
+	var template *repo.TemplateConfig
+	if opts.Template != nil {
+		// Şablon hedefi yer tutucularla saklanır, kontrolü örnek değerlerle yapılır.
+		if opts.PathForward {
+			return "", "", ErrInvalidTemplate
+		}
+		t, cfg, err := validateTemplate(inputURL, opts.Template)
+		if err != nil {
+			return "", "", err
+		}
+		target, template = t, cfg
+	} else {
+		t, err := security.NormalizeUrl(inputURL)
+		if err != nil {
+			return "", "", ErrInvalidURL
+		}
+		target = t
 	}
 
-	value, hasError, errorMsg := s.cache.GetCodeByURLKey(ctx, target)
-	if hasError {
-		s.logger.Error(errorMsg.Error())
-		return "", "", ErrSystem
-	}
+	// Opsiyon verilmişse mevcut linki dönmek opsiyonları yok saymak olur, dedupe sadece sade linklerde.
+	if opts.IsZero() {
+		value, hasError, errorMsg := s.cache.GetCodeByURLKey(ctx, target)
+		if hasError {
+			s.logger.Error(errorMsg.Error())
+			return "", "", ErrSystem
+		}
 
-	// rediste varsa onu dön
-	if value != "" {
-		s.logger.Info("cache hit")
-		return value, s.baseURL + "/" + value, nil
-	}
+		// rediste varsa onu dön
+		if value != "" {
+			s.logger.Info("cache hit")
+			return value, s.baseURL + "/" + value, nil
+		}
 
-	code, _ := s.repo.GetCodeByUrl(target)
+		code, _ := s.repo.GetCodeByUrl(target)
 
-	if code != "" {
-		s.logger.Info("code exist")
-		s.processCacheAfterShorten(ctx, code, target, settings)
-		return code, s.baseURL + "/" + code, nil
+		if code != "" {
+			s.logger.Info("code exist")
+			_ = s.cache.SetCodeByURLKey(ctx, target, code, s.cacheTTL(settings))
+			return code, s.baseURL + "/" + code, nil
+		}
 	}
 
+	var code string
 	if customAlias != nil && *customAlias != "" {
+		// "/abc+" önizleme adresi; "+" içeren alias yönlendirme yerine önizlemeye düşer.
+		if strings.Contains(*customAlias, "+") {
+			return "", "", ErrInvalidAlias
+		}
 		code = *customAlias
 	} else {
 		seq, err := s.GetSeqNum(ctx)
@@ -77,50 +121,277 @@ func (s *Service) Shorten(ctx context.Context, inputURL string, customAlias *str
 		code = base62.Encode(seq ^ salt)
 	}
 
+	if opts.MaxClicks != nil && *opts.MaxClicks <= 0 {
+		return "", "", ErrInvalidMaxClicks
+	}
+
+	rules, err := routing.Validate(opts.Rules)
+	if err != nil {
+		return "", "", ErrInvalidRules
+	}
+
+	destinations, err := routing.ValidateDestinations(opts.Destinations)
+	if err != nil {
+		return "", "", ErrInvalidDestinations
+	}
+
+	switch opts.QueryForward {
+	case "", repo.QUERY_FORWARD_MERGE, repo.QUERY_FORWARD_OVERRIDE:
+	default:
+		return "", "", ErrInvalidForward
+	}
+
+	utm, err := validateUTM(opts.UTM)
+	if err != nil {
+		return "", "", err
+	}
+
+	card, err := validateSocialCard(opts.SocialCard)
+	if err != nil {
+		return "", "", err
+	}
+
+	if opts.RedirectType != 0 && !ValidRedirectType(opts.RedirectType) {
+		return "", "", ErrInvalidRedirectType
+	}
+
+	deepLink, err := validateDeepLink(opts.DeepLink)
+	if err != nil {
+		return "", "", err
+	}
+
+	fallbacks, err := validateFallbacks(opts.FallbackTargets, target)
+	if err != nil {
+		return "", "", err
+	}
+	// Kural, şablon veya A/B hedefleri ana hedefi ezdiği için yedek hedefler bunlarla birlikte anlamsız.
+	if len(fallbacks) > 0 && (len(rules) > 0 || len(destinations) > 0 || template != nil) {
+		return "", "", ErrInvalidFallback
+	}
+
+	passwordHash, err := hashPassword(opts.Password)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Süre yayına giriş anından itibaren sayılır.
+	base := time.Now()
+	if opts.StartsAt != nil {
+		base = *opts.StartsAt
+	}
+
 	var exp *time.Time
 	if !settings.IsZero() && settings.TtlTime > 0 {
-		e := time.Now().Add(time.Duration(settings.TtlTime) * time.Hour).UTC()
+		e := base.Add(time.Duration(settings.TtlTime) * time.Hour).UTC()
 		exp = &e
 	}
 
-	u := repo.URL{Code: code, Target: target, CreatedAt: time.Now().UTC(), ExpiresAt: exp, Disabled: false}
+	// Kota en son, bütün doğrulamalardan sonra ayrılır; geçersiz istekler hak harcamasın.
+	hasAlias := customAlias != nil && *customAlias != ""
+	if err := s.reserveLink(ctx, opts.OwnerID, hasAlias); err != nil {
+		return "", "", err
+	}
+
+	u := repo.URL{
+		Code:            code,
+		Target:          target,
+		CreatedAt:       time.Now().UTC(),
+		StartsAt:        utc(opts.StartsAt),
+		ExpiresAt:       exp,
+		Disabled:        false,
+		Interstitial:    opts.Interstitial,
+		PasswordHash:    passwordHash,
+		MaxClicks:       opts.MaxClicks,
+		Rules:           rules,
+		Destinations:    destinations,
+		Sticky:          opts.Sticky && len(destinations) > 0,
+		QueryForward:    opts.QueryForward,
+		PathForward:     opts.PathForward,
+		Template:        template,
+		UTM:             utm,
+		DeepLink:        deepLink,
+		RedirectType:    opts.RedirectType,
+		SocialCard:      card,
+		FallbackTargets: fallbacks,
+		OwnerID:         opts.OwnerID,
+		// UTM'ler hedefe gömülmez; aynı hedefe farklı UTM'lerle açılan linkler sade linkin kodunu paylaşmaz.
+		HasOptions: !opts.IsZero(),
+	}
+	if hasAlias {
+		u.CustomAlias = customAlias
+	}
 	if err := s.repo.Insert(u); err != nil {
+		s.releaseLink(ctx, opts.OwnerID, hasAlias)
 		// repo duplicate → ErrConflict
 		return "", "", ErrConflict
 	}
 
-	s.processCacheAfterShorten(ctx, code, target, settings)
+	s.processCacheAfterShorten(ctx, u, settings)
+	s.enqueueMetadata(u)
 
 	return code, s.baseURL + "/" + code, nil
 }
 
-func (s *Service) Resolve(ctx context.Context, code string, settings repo.Settings) (string, error) {
+// Resolve kodu çözer; gerçek bir yönlendirme olacaksa tıklamayı arka planda kaydeder.
+func (s *Service) Resolve(ctx context.Context, code string, req Request, settings repo.Settings) (*Resolution, error) {
+	res, err := s.resolve(ctx, code, req, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.Interstitial && !req.DryRun {
+		s.recordClick(code, res, req)
+	}
+	return res, nil
+}
+
+func (s *Service) resolve(ctx context.Context, code string, req Request, settings repo.Settings) (*Resolution, error) {
 
 	value, hasError, errorMsg := s.cache.GetURLByCode(ctx, code)
 	if hasError {
 		s.logger.Error(errorMsg.Error())
-		return "", ErrSystem
+		return nil, ErrSystem
 	}
 
 	if value != "" {
-		return value, nil
+		var l cachedLink
+		if err := json.Unmarshal([]byte(value), &l); err == nil && l.Target != "" {
+			return s.resolveLink(code, l, req, settings)
+		}
+		// eski formatta (düz string) kayıt, DB'den okuyup üzerine yazıyoruz
+	}
+
+	u, err := s.active(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.MaxClicks != nil && u.Clicks >= *u.MaxClicks {
+		return nil, ErrGone
+	}
+
+	s.processCacheAfterShorten(ctx, *u, settings)
+
+	res, err := s.resolveLink(code, s.cachedLink(ctx, *u), req, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ara sayfa gösterilecekse henüz yönlendirme yok, hakkı harcamıyoruz.
+	if u.MaxClicks != nil && !res.Interstitial && !req.DryRun {
+		ok, err := s.repo.ConsumeClick(ctx, code)
+		if err != nil {
+			s.logger.Error("consume click failed", "code", code, "error", err)
+			return nil, ErrSystem
+		}
+		if !ok {
+			return nil, ErrGone
+		}
+	}
+	return res, nil
+}
+
+// resolveLink link durumunu kontrol eder ve kurallara göre hedefi seçer.
+// Kural eşleşirse onun hedefi, eşleşmezse A/B varyantlarından biri, o da yoksa Target kullanılır.
+func (s *Service) resolveLink(code string, l cachedLink, req Request, settings repo.Settings) (*Resolution, error) {
+	res, err := l.resolve(req, settings)
+	if err != nil {
+		return nil, err
+	}
+	// Sağlık kontrolü sadece ana hedefi izliyor; hedefi dinamik seçilen linklerde yedek hedef kullanılmaz.
+	if l.Failover != "" && !l.dynamicTarget() {
+		res.Target, res.Failover = l.Failover, true
+	}
+
+	if len(l.Rules) > 0 {
+		attrs := routing.Attributes{
+			Agent:     useragent.Parse(req.UserAgent),
+			Languages: routing.ParseAcceptLanguage(req.AcceptLanguage),
+			Query:     req.Query,
+			Now:       time.Now(),
+		}
+		if s.geo != nil && routing.NeedsCountry(l.Rules) {
+			attrs.Country = s.geo.Country(req.IP)
+		}
+
+		if target, ok := routing.Select(l.Rules, attrs); ok {
+			res.Target = target
+			return s.finish(res, l, req, settings)
+		}
+	}
+
+	if l.Template != nil {
+		target, err := expandTemplate(l.Target, *l.Template, req.Suffix, req.Query)
+		if err != nil {
+			return nil, err
+		}
+		res.Target = target
+	}
+
+	if len(l.Destinations) > 0 {
+		visitor := ""
+		if l.Sticky {
+			visitor = req.VisitorID
+		}
+		d := routing.Pick(l.Destinations, code, visitor)
+		res.Target, res.Variant, res.Sticky = d.Target, d.Name, l.Sticky
+	}
+	return s.finish(res, l, req, settings)
+}
+
+// finish seçilen hedefe passthrough ve UTM'i uygular, mobil ziyaretçi için uygulama açma kararını
+// ve yönlendirme kodu / tarayıcı cache süresini belirler.
+func (s *Service) finish(res *Resolution, l cachedLink, req Request, settings repo.Settings) (*Resolution, error) {
+	target, err := passthrough(res.Target, l, req)
+	if err != nil {
+		return nil, err
+	}
+	// UTM en son eklenir; hedefte veya taşınan query'de olan parametreler korunur.
+	target, err = applyUTM(target, l.UTM, req.Query)
+	if err != nil {
+		return nil, err
+	}
+	res.Target = target
+
+	// Önizleme botuna kart gösterilir; deep link kararı sadece gerçek ziyaretçiler için.
+	if req.Crawler && l.SocialCard != nil {
+		res.Card = l.SocialCard
+	} else if launch := appLaunch(l.DeepLink, useragent.Parse(req.UserAgent), res.Target); launch != nil {
+		if launch.Bridge {
+			res.App = launch
+		} else {
+			// Universal link / App Link düz 302 ile açılır; uygulama yoksa tarayıcı aynı adresi açar.
+			res.Target = launch.URL
+		}
 	}
 
+	res.Status = l.redirectStatus(settings)
+	res.MaxAge = l.browserMaxAge(res, settings)
+	return res, nil
+}
+
+// cachedLink sahibin UTM şablonunu da içeren cache kaydını hazırlar.
+func (s *Service) cachedLink(ctx context.Context, u repo.URL) cachedLink {
+	l := newCachedLink(u)
+	l.UTM = s.linkUTM(ctx, u)
+	return l
+}
+
+// active kodu DB'den okur; kapalı veya süresi dolmuş linkleri hata olarak döner.
+func (s *Service) active(code string) (*repo.URL, error) {
 	u, err := s.repo.GetByCode(code)
 	if err != nil || u == nil {
-		return "", ErrNotFound
+		return nil, ErrNotFound
 	}
 
 	if u.Disabled {
-		return "", ErrNotFound
+		return nil, ErrNotFound
 	}
 
 	if u.ExpiresAt != nil && u.ExpiresAt.Before(time.Now().UTC()) {
-		return "", ErrExpired
+		return nil, ErrExpired
 	}
-
-	s.processCacheAfterShorten(ctx, code, u.Target, settings)
-	return u.Target, nil
+	return u, nil
 }
 
 func (s *Service) GetSeqNum(ctx context.Context) (uint64, error) {
@@ -131,13 +402,101 @@ func (s *Service) GetSeqNum(ctx context.Context) (uint64, error) {
 	return seq, nil
 }
 
-func (s *Service) processCacheAfterShorten(ctx context.Context, code string, target string, settings repo.Settings) {
+// Disable linki kapatır ve iki yöndeki cache kaydını da siler.
+func (s *Service) Disable(ctx context.Context, code string) error {
+	return s.update(ctx, code, func() error { return s.repo.SetDisabled(ctx, code, true) })
+}
+
+// SetFlagged şikayet durumunu günceller; cache silindiği için sonraki yönlendirmede hemen etkili olur.
+func (s *Service) SetFlagged(ctx context.Context, code string, flagged bool) error {
+	return s.update(ctx, code, func() error { return s.repo.SetFlagged(ctx, code, flagged) })
+}
+
+// Reschedule linkin yayın penceresini değiştirir; nil verilen sınır kaldırılır.
+func (s *Service) Reschedule(ctx context.Context, code string, startsAt, expiresAt *time.Time) error {
+	startsAt, expiresAt = utc(startsAt), utc(expiresAt)
+	if startsAt != nil && expiresAt != nil && !expiresAt.After(*startsAt) {
+		return ErrInvalidSchedule
+	}
+	return s.update(ctx, code, func() error { return s.repo.SetSchedule(ctx, code, startsAt, expiresAt) })
+}
+
+func (s *Service) update(ctx context.Context, code string, apply func() error) error {
+	u, err := s.repo.GetByCode(code)
+	if err != nil {
+		return ErrSystem
+	}
+	if u == nil {
+		return ErrNotFound
+	}
+
+	if err := apply(); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+		return ErrSystem
+	}
+
+	s.invalidateCache(ctx, code, u.Target)
+	return nil
+}
+
+func (s *Service) invalidateCache(ctx context.Context, code string, target string) {
+	if err := s.cache.DelURLByCode(ctx, code); err != nil {
+		s.logger.Error("cache purge failed", "code", code, "error", err)
+	}
+	if err := s.cache.DelCodeByURLKey(ctx, target); err != nil {
+		s.logger.Error("cache purge failed", "target", target, "error", err)
+	}
+}
+
+func (s *Service) cacheTTL(settings repo.Settings) time.Duration {
 	exp := time.Duration(5) * time.Minute
 
 	if !settings.IsZero() && settings.RedisTtlTime > 0 {
 		exp = time.Duration(settings.RedisTtlTime) * time.Minute
 	}
+	return exp
+}
+
+func (s *Service) processCacheAfterShorten(ctx context.Context, u repo.URL, settings repo.Settings) {
+	// Tıklama limitli linkler hiç cache'lenmez; her yönlendirme repo'daki atomik sayaçtan geçmeli.
+	if u.MaxClicks != nil {
+		return
+	}
+
+	exp := s.cacheTTL(settings)
 
-	_ = s.cache.SetURLByCode(ctx, code, target, exp)
-	_ = s.cache.SetCodeByURLKey(ctx, target, code, exp)
+	// Cache kaydı bir sonraki sınırdan (yayın başlangıcı / bitişi) daha uzun yaşamamalı.
+	now := time.Now()
+	for _, boundary := range []*time.Time{u.StartsAt, u.ExpiresAt} {
+		if boundary == nil || !boundary.After(now) {
+			continue
+		}
+		if left := boundary.Sub(now); left < exp {
+			exp = left
+		}
+	}
+	if u.ExpiresAt != nil && !u.ExpiresAt.After(now) {
+		return
+	}
+
+	payload, err := json.Marshal(s.cachedLink(ctx, u))
+	if err != nil {
+		return
+	}
+
+	_ = s.cache.SetURLByCode(ctx, u.Code, string(payload), exp)
+	// Opsiyonlu linkler dedupe'a girmediği için URL→code eşlemesine yazılmaz.
+	if !u.HasOptions {
+		_ = s.cache.SetCodeByURLKey(ctx, u.Target, u.Code, exp)
+	}
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC()
+	return &v
 }
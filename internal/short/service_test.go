@@ -0,0 +1,22 @@
+package short
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+func TestShortenRejectsPreviewSuffixAlias(t *testing.T) {
+	// Opsiyon verilince dedupe atlanır; cache'e hiç gidilmeden alias kontrol edilir.
+	yes := true
+	for _, alias := range []string{"abc+", "a+b", "+"} {
+		s := NewService(codeRepo{}, nil, "http://sho.rt", logger.GetLogger(), nil)
+		_, _, err := s.Shorten(context.Background(), "https://example.com/a", &alias, LinkOptions{Interstitial: &yes}, repo.Settings{})
+		if !errors.Is(err, ErrInvalidAlias) {
+			t.Errorf("alias %q: err = %v, want %v", alias, err, ErrInvalidAlias)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package short
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+)
+
+var ErrInvalidSocialCard = errors.New("invalid_social_card")
+
+const (
+	MAX_CARD_TITLE_LEN       = 200
+	MAX_CARD_DESCRIPTION_LEN = 500
+)
+
+// validateSocialCard boşlukları temizler, görseli normalize eder; tüm alanlar boşsa nil döner.
+func validateSocialCard(c *repo.SocialCard) (*repo.SocialCard, error) {
+	if c == nil {
+		return nil, nil
+	}
+	out := repo.SocialCard{
+		Title:       strings.TrimSpace(c.Title),
+		Description: strings.TrimSpace(c.Description),
+	}
+	if utf8.RuneCountInString(out.Title) > MAX_CARD_TITLE_LEN ||
+		utf8.RuneCountInString(out.Description) > MAX_CARD_DESCRIPTION_LEN {
+		return nil, ErrInvalidSocialCard
+	}
+	if image := strings.TrimSpace(c.Image); image != "" {
+		normalized, err := security.NormalizeUrl(image)
+		if err != nil {
+			return nil, ErrInvalidSocialCard
+		}
+		out.Image = normalized
+	}
+
+	if out == (repo.SocialCard{}) {
+		return nil, nil
+	}
+	return &out, nil
+}
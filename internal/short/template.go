@@ -0,0 +1,119 @@
+package short
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+	"github.com/emrealsandev/Url-Shortener/internal/security"
+)
+
+var (
+	ErrInvalidTemplate = errors.New("invalid_template")
+	ErrTemplateParam   = errors.New("invalid_parameter")
+)
+
+var placeholderRe = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// validateTemplate şablonu iki farklı örnek değerle genişletip kontrol eder:
+// sonuç geçerli bir URL olmalı ve host değişkenlerden etkilenmemeli.
+func validateTemplate(target string, cfg *repo.TemplateConfig) (string, *repo.TemplateConfig, error) {
+	target = strings.TrimSpace(target)
+	if !placeholderRe.MatchString(target) {
+		return "", nil, ErrInvalidTemplate
+	}
+
+	c := *cfg
+	switch c.OnMissing {
+	case "":
+		c.OnMissing = repo.TEMPLATE_MISSING_ERROR
+	case repo.TEMPLATE_MISSING_ERROR, repo.TEMPLATE_MISSING_EMPTY:
+	default:
+		return "", nil, ErrInvalidTemplate
+	}
+
+	var hosts [2]string
+	for i, sample := range []string{"a", "b"} {
+		expanded := substitute(target, func(string) string { return sample })
+		normalized, err := security.NormalizeUrl(expanded)
+		if err != nil {
+			return "", nil, ErrInvalidTemplate
+		}
+		u, _ := url.Parse(normalized)
+		hosts[i] = u.Host
+	}
+	if hosts[0] != hosts[1] {
+		return "", nil, ErrInvalidTemplate
+	}
+
+	return target, &c, nil
+}
+
+// expandTemplate yer tutucuları path eki, query ve varsayılanlardan doldurur.
+// Değerler konumuna göre (path/query) escape edilir ve sonuç tekrar NormalizeUrl'den geçer.
+func expandTemplate(target string, cfg repo.TemplateConfig, suffix string, query map[string]string) (string, error) {
+	vars := map[string]string{}
+	if suffix != "" {
+		segments := strings.Split(strings.Trim(suffix, "/"), "/")
+		if len(segments) > len(cfg.PathParams) {
+			return "", ErrNotFound
+		}
+		for i, seg := range segments {
+			v, err := url.PathUnescape(seg)
+			if err != nil {
+				return "", ErrTemplateParam
+			}
+			vars[cfg.PathParams[i]] = v
+		}
+	}
+
+	var missing bool
+	lookup := func(name string) (string, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+		if v, ok := query[name]; ok {
+			return v, true
+		}
+		if v, ok := cfg.Defaults[name]; ok {
+			return v, true
+		}
+		return "", cfg.OnMissing == repo.TEMPLATE_MISSING_EMPTY
+	}
+
+	queryStart := strings.IndexByte(target, '?')
+	var b strings.Builder
+	last := 0
+	for _, m := range placeholderRe.FindAllStringSubmatchIndex(target, -1) {
+		b.WriteString(target[last:m[0]])
+		v, ok := lookup(target[m[2]:m[3]])
+		if !ok {
+			missing = true
+		}
+		if queryStart >= 0 && m[0] > queryStart {
+			b.WriteString(url.QueryEscape(v))
+		} else {
+			b.WriteString(url.PathEscape(v))
+		}
+		last = m[1]
+	}
+	b.WriteString(target[last:])
+
+	if missing {
+		return "", ErrTemplateParam
+	}
+
+	expanded, err := security.NormalizeUrl(b.String())
+	if err != nil {
+		return "", ErrTemplateParam
+	}
+	return expanded, nil
+}
+
+func substitute(target string, value func(name string) string) string {
+	return placeholderRe.ReplaceAllStringFunc(target, func(m string) string {
+		return value(m[1 : len(m)-1])
+	})
+}
@@ -0,0 +1,139 @@
+package short
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+var ErrInvalidUTM = errors.New("invalid_utm")
+
+// UTM_FLAG gelen istekte "?utm=off" UTM eklemeyi kapatır.
+const UTM_FLAG = "utm"
+
+const MAX_UTM_VALUE_LEN = 200
+
+// utmFields sabit sırada; hedefe eklenirken sıra korunur.
+func utmFields(p *repo.UTMParams) []struct{ key, value string } {
+	return []struct{ key, value string }{
+		{"utm_source", p.Source},
+		{"utm_medium", p.Medium},
+		{"utm_campaign", p.Campaign},
+		{"utm_term", p.Term},
+		{"utm_content", p.Content},
+	}
+}
+
+// validateUTM boşlukları temizler; hepsi boşsa nil döner.
+func validateUTM(p *repo.UTMParams) (*repo.UTMParams, error) {
+	if p == nil {
+		return nil, nil
+	}
+	out := repo.UTMParams{
+		Source:   strings.TrimSpace(p.Source),
+		Medium:   strings.TrimSpace(p.Medium),
+		Campaign: strings.TrimSpace(p.Campaign),
+		Term:     strings.TrimSpace(p.Term),
+		Content:  strings.TrimSpace(p.Content),
+	}
+	if out == (repo.UTMParams{}) {
+		return nil, nil
+	}
+	for _, f := range utmFields(&out) {
+		if len(f.value) > MAX_UTM_VALUE_LEN {
+			return nil, ErrInvalidUTM
+		}
+	}
+	return &out, nil
+}
+
+// mergeUTM link şablonundaki dolu alanlar sahibin şablonunu ezer.
+func mergeUTM(owner, link *repo.UTMParams) *repo.UTMParams {
+	if owner == nil {
+		return link
+	}
+	if link == nil {
+		return owner
+	}
+	return &repo.UTMParams{
+		Source:   firstNonEmpty(link.Source, owner.Source),
+		Medium:   firstNonEmpty(link.Medium, owner.Medium),
+		Campaign: firstNonEmpty(link.Campaign, owner.Campaign),
+		Term:     firstNonEmpty(link.Term, owner.Term),
+		Content:  firstNonEmpty(link.Content, owner.Content),
+	}
+}
+
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+// applyUTM eksik UTM parametrelerini hedefe ekler. İstekteki utm_* değerleri şablonu ezer,
+// hedefte zaten olanlara dokunulmaz.
+func applyUTM(target string, utm *repo.UTMParams, query map[string]string) (string, error) {
+	if utm == nil {
+		return target, nil
+	}
+	switch strings.ToLower(query[UTM_FLAG]) {
+	case "0", "off", "false":
+		return target, nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", ErrSystem
+	}
+	values := u.Query()
+	changed := false
+	for _, f := range utmFields(utm) {
+		v := f.value
+		if override := query[f.key]; override != "" {
+			v = override
+		}
+		if v == "" || values.Has(f.key) {
+			continue
+		}
+		values.Set(f.key, v)
+		changed = true
+	}
+	if !changed {
+		return target, nil
+	}
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+// linkUTM sahibin şablonunu linkinkiyle birleştirir; sahip okunamazsa sadece link şablonu kullanılır.
+func (s *Service) linkUTM(ctx context.Context, u repo.URL) *repo.UTMParams {
+	if u.OwnerID == nil {
+		return u.UTM
+	}
+	owner, err := s.repo.GetOwner(ctx, *u.OwnerID)
+	if err != nil {
+		s.logger.Error("owner lookup failed", "owner_id", *u.OwnerID, "error", err)
+		return u.UTM
+	}
+	if owner == nil {
+		return u.UTM
+	}
+	return mergeUTM(owner.UTM, u.UTM)
+}
+
+// SetOwnerUTM sahibin UTM şablonunu günceller. Cache'teki linkler en geç cache TTL sonunda yeni şablonu alır.
+func (s *Service) SetOwnerUTM(ctx context.Context, ownerID int64, utm *repo.UTMParams) error {
+	utm, err := validateUTM(utm)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetOwnerUTM(ctx, ownerID, utm); err != nil {
+		s.logger.Error("set owner utm failed", "owner_id", ownerID, "error", err)
+		return ErrSystem
+	}
+	return nil
+}
@@ -0,0 +1,435 @@
+package workspace
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/emrealsandev/Url-Shortener/internal/logger"
+	mailer "github.com/emrealsandev/Url-Shortener/internal/mail"
+	"github.com/emrealsandev/Url-Shortener/internal/policy"
+	"github.com/emrealsandev/Url-Shortener/internal/repo"
+)
+
+var (
+	ErrInvalidName        = errors.New("invalid_name")
+	ErrInvalidRole        = errors.New("invalid_role")
+	ErrInvalidEmail       = errors.New("invalid_email")
+	ErrNotFound           = errors.New("not_found")
+	ErrLastOwner          = errors.New("last_owner")
+	ErrAlreadyMember      = errors.New("already_member")
+	ErrInvalidInvitation  = errors.New("invalid_invitation")
+	ErrInvitationEmail    = errors.New("invitation_email_mismatch")
+	ErrTooManyInvitations = errors.New("too_many_invitations")
+	ErrSystem             = errors.New("system_error")
+)
+
+const (
+	MAX_NAME_LENGTH         = 100
+	INVITATION_TTL          = 7 * 24 * time.Hour
+	MAX_PENDING_INVITATIONS = 50
+	MAIL_TIMEOUT            = 30 * time.Second
+)
+
+// View workspace ve isteği yapanın oradaki rolü.
+type View struct {
+	repo.Workspace
+	Role policy.Role `json:"role"`
+}
+
+// Service workspace, üyelik ve davet işlemleri. Hangi rolün ne yapabileceği policy paketindedir; route'lar
+// action'ı middleware ile kontrol eder, burada sadece rol hiyerarşisi (kimin kimi yönetebileceği) uygulanır.
+type Service struct {
+	workspaces repo.WorkspaceRepository
+	users      repo.UserRepository
+	mail       mailer.Sender
+	baseURL    string
+	logger     logger.Logger
+}
+
+func NewService(workspaces repo.WorkspaceRepository, users repo.UserRepository, mail mailer.Sender, baseURL string, logger logger.Logger) *Service {
+	return &Service{workspaces: workspaces, users: users, mail: mail, baseURL: strings.TrimRight(baseURL, "/"), logger: logger}
+}
+
+// Create workspace'i açar; kurucu owner olur.
+func (s *Service) Create(ctx context.Context, user *repo.User, name string) (*View, error) {
+	name, err := normalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	w := &repo.Workspace{Name: name, CreatedBy: user.ID, CreatedAt: now, UpdatedAt: now}
+	owner := repo.Membership{UserID: user.ID, Email: user.Email, Role: string(policy.ROLE_OWNER), CreatedAt: now, UpdatedAt: now}
+	if err := s.workspaces.Create(ctx, w, owner); err != nil {
+		s.logger.Error("workspace create failed", "user_id", user.ID, "error", err)
+		return nil, ErrSystem
+	}
+	return &View{Workspace: *w, Role: policy.ROLE_OWNER}, nil
+}
+
+// List kullanıcının üye olduğu workspace'ler, isme göre.
+func (s *Service) List(ctx context.Context, userID int64) ([]View, error) {
+	memberships, err := s.workspaces.ListMemberships(ctx, userID)
+	if err != nil {
+		s.logger.Error("membership list failed", "user_id", userID, "error", err)
+		return nil, ErrSystem
+	}
+	roles := make(map[int64]policy.Role, len(memberships))
+	ids := make([]int64, 0, len(memberships))
+	for _, m := range memberships {
+		roles[m.WorkspaceID] = policy.Role(m.Role)
+		ids = append(ids, m.WorkspaceID)
+	}
+
+	workspaces, err := s.workspaces.GetMany(ctx, ids)
+	if err != nil {
+		s.logger.Error("workspace list failed", "user_id", userID, "error", err)
+		return nil, ErrSystem
+	}
+	out := make([]View, 0, len(workspaces))
+	for _, w := range workspaces {
+		out = append(out, View{Workspace: w, Role: roles[w.ID]})
+	}
+	return out, nil
+}
+
+func (s *Service) Get(ctx context.Context, sub policy.Subject) (*View, error) {
+	id, err := workspaceID(sub)
+	if err != nil {
+		return nil, err
+	}
+	w, err := s.workspaces.Get(ctx, id)
+	if err != nil {
+		s.logger.Error("workspace lookup failed", "workspace_id", id, "error", err)
+		return nil, ErrSystem
+	}
+	if w == nil {
+		return nil, ErrNotFound
+	}
+	return &View{Workspace: *w, Role: sub.Role}, nil
+}
+
+func (s *Service) Rename(ctx context.Context, sub policy.Subject, name string) (*View, error) {
+	id, err := workspaceID(sub)
+	if err != nil {
+		return nil, err
+	}
+	name, err = normalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.workspaces.Rename(ctx, id, name); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		s.logger.Error("workspace rename failed", "workspace_id", id, "error", err)
+		return nil, ErrSystem
+	}
+	return s.Get(ctx, sub)
+}
+
+func (s *Service) Members(ctx context.Context, sub policy.Subject) ([]repo.Membership, error) {
+	id, err := workspaceID(sub)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.workspaces.ListMembers(ctx, id)
+	if err != nil {
+		s.logger.Error("member list failed", "workspace_id", id, "error", err)
+		return nil, ErrSystem
+	}
+	return members, nil
+}
+
+// SetRole üyenin rolünü değiştirir. Son owner'ın rolü düşürülemez.
+func (s *Service) SetRole(ctx context.Context, sub policy.Subject, userID int64, role policy.Role) (*repo.Membership, error) {
+	id, err := workspaceID(sub)
+	if err != nil {
+		return nil, err
+	}
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	m, err := s.member(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	current := policy.Role(m.Role)
+	if !sub.Role.CanManage(current, role) {
+		return nil, policy.ErrForbidden
+	}
+	if current == policy.ROLE_OWNER && role != policy.ROLE_OWNER {
+		if err := s.keepOwner(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	m.Role = string(role)
+	if err := s.workspaces.PutMember(ctx, *m); err != nil {
+		s.logger.Error("member update failed", "workspace_id", id, "user_id", userID, "error", err)
+		return nil, ErrSystem
+	}
+	return m, nil
+}
+
+// RemoveMember üyeyi çıkarır. Herkes kendi üyeliğinden ayrılabilir; son owner ayrılamaz.
+func (s *Service) RemoveMember(ctx context.Context, sub policy.Subject, userID int64) error {
+	id, err := workspaceID(sub)
+	if err != nil {
+		return err
+	}
+	m, err := s.member(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	current := policy.Role(m.Role)
+	self := sub.UserID != nil && *sub.UserID == userID
+	if !self && !sub.Role.CanManage(current, "") {
+		return policy.ErrForbidden
+	}
+	if current == policy.ROLE_OWNER {
+		if err := s.keepOwner(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	if err := s.workspaces.RemoveMember(ctx, id, userID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+		s.logger.Error("member remove failed", "workspace_id", id, "user_id", userID, "error", err)
+		return ErrSystem
+	}
+	return nil
+}
+
+// Invite e-postaya tek kullanımlık bir davet linki gönderir. Davetteki rol davet edenin verebileceği bir rol olmalıdır.
+func (s *Service) Invite(ctx context.Context, sub policy.Subject, email string, role policy.Role) (*repo.Invitation, error) {
+	id, err := workspaceID(sub)
+	if err != nil {
+		return nil, err
+	}
+	email, err = normalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	if !sub.Role.CanManage("", role) {
+		return nil, policy.ErrForbidden
+	}
+
+	w, err := s.Get(ctx, sub)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.workspaces.ListInvitations(ctx, id)
+	if err != nil {
+		s.logger.Error("invitation list failed", "workspace_id", id, "error", err)
+		return nil, ErrSystem
+	}
+	if len(pending) >= MAX_PENDING_INVITATIONS {
+		return nil, ErrTooManyInvitations
+	}
+	if u, err := s.users.GetByEmail(ctx, email); err != nil {
+		s.logger.Error("user lookup failed", "error", err)
+		return nil, ErrSystem
+	} else if u != nil {
+		if m, err := s.workspaces.GetMember(ctx, id, u.ID); err != nil {
+			s.logger.Error("membership lookup failed", "workspace_id", id, "error", err)
+			return nil, ErrSystem
+		} else if m != nil {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	invID, err := randomToken(12)
+	if err != nil {
+		return nil, ErrSystem
+	}
+	now := time.Now().UTC()
+	inv := repo.Invitation{
+		ID:          invID,
+		TokenHash:   hashToken(token),
+		WorkspaceID: id,
+		Email:       email,
+		Role:        string(role),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(INVITATION_TTL),
+	}
+	if sub.UserID != nil {
+		inv.InvitedBy = *sub.UserID
+	}
+	if err := s.workspaces.CreateInvitation(ctx, inv); err != nil {
+		s.logger.Error("invitation create failed", "workspace_id", id, "error", err)
+		return nil, ErrSystem
+	}
+
+	msg := mailer.Message{
+		To:      email,
+		Subject: w.Name + " workspace davetiyesi",
+		Body: w.Name + " workspace'ine " + string(role) + " olarak davet edildin. Daveti kabul etmek için " +
+			"bu e-posta adresiyle giriş yapıp aşağıdaki linki aç (7 gün geçerli):\n\n" +
+			s.baseURL + "/account?invite=" + token + "\n",
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), MAIL_TIMEOUT)
+		defer cancel()
+		if err := s.mail.Send(ctx, msg); err != nil {
+			s.logger.Error("invitation mail failed", "workspace_id", id, "error", err)
+		}
+	}()
+	return &inv, nil
+}
+
+func (s *Service) Invitations(ctx context.Context, sub policy.Subject) ([]repo.Invitation, error) {
+	id, err := workspaceID(sub)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.workspaces.ListInvitations(ctx, id)
+	if err != nil {
+		s.logger.Error("invitation list failed", "workspace_id", id, "error", err)
+		return nil, ErrSystem
+	}
+	return out, nil
+}
+
+func (s *Service) RevokeInvitation(ctx context.Context, sub policy.Subject, invitationID string) error {
+	id, err := workspaceID(sub)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.workspaces.DeleteInvitation(ctx, id, invitationID)
+	if err != nil {
+		s.logger.Error("invitation delete failed", "workspace_id", id, "error", err)
+		return ErrSystem
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Accept daveti oturumdaki kullanıcı adına kabul eder. Davet sadece gönderildiği e-postanın hesabıyla kabul edilebilir.
+func (s *Service) Accept(ctx context.Context, user *repo.User, token string) (*View, error) {
+	if token == "" {
+		return nil, ErrInvalidInvitation
+	}
+	inv, err := s.workspaces.GetInvitationByToken(ctx, hashToken(token))
+	if err != nil {
+		s.logger.Error("invitation lookup failed", "error", err)
+		return nil, ErrSystem
+	}
+	if inv == nil {
+		return nil, ErrInvalidInvitation
+	}
+	if !strings.EqualFold(inv.Email, user.Email) {
+		return nil, ErrInvitationEmail
+	}
+
+	existing, err := s.workspaces.GetMember(ctx, inv.WorkspaceID, user.ID)
+	if err != nil {
+		s.logger.Error("membership lookup failed", "workspace_id", inv.WorkspaceID, "error", err)
+		return nil, ErrSystem
+	}
+	if existing != nil {
+		return nil, ErrAlreadyMember
+	}
+
+	// silmeyi kazanan kabul eder; aynı davet iki kez kullanılamaz
+	claimed, err := s.workspaces.DeleteInvitation(ctx, inv.WorkspaceID, inv.ID)
+	if err != nil {
+		s.logger.Error("invitation delete failed", "workspace_id", inv.WorkspaceID, "error", err)
+		return nil, ErrSystem
+	}
+	if !claimed {
+		return nil, ErrInvalidInvitation
+	}
+
+	now := time.Now().UTC()
+	m := repo.Membership{WorkspaceID: inv.WorkspaceID, UserID: user.ID, Email: user.Email, Role: inv.Role, CreatedAt: now, UpdatedAt: now}
+	if err := s.workspaces.PutMember(ctx, m); err != nil {
+		s.logger.Error("member add failed", "workspace_id", inv.WorkspaceID, "user_id", user.ID, "error", err)
+		return nil, ErrSystem
+	}
+
+	role := policy.Role(inv.Role)
+	return s.Get(ctx, policy.Subject{UserID: &user.ID, OwnerID: inv.WorkspaceID, WorkspaceID: &inv.WorkspaceID, Role: role})
+}
+
+func (s *Service) member(ctx context.Context, workspaceID, userID int64) (*repo.Membership, error) {
+	m, err := s.workspaces.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("membership lookup failed", "workspace_id", workspaceID, "user_id", userID, "error", err)
+		return nil, ErrSystem
+	}
+	if m == nil {
+		return nil, ErrNotFound
+	}
+	return m, nil
+}
+
+// keepOwner workspace'in sahipsiz kalmasını engeller.
+func (s *Service) keepOwner(ctx context.Context, workspaceID int64) error {
+	n, err := s.workspaces.CountMembers(ctx, workspaceID, string(policy.ROLE_OWNER))
+	if err != nil {
+		s.logger.Error("owner count failed", "workspace_id", workspaceID, "error", err)
+		return ErrSystem
+	}
+	if n <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// workspaceID kişisel alan bir workspace değildir; workspace uçları ona uygulanmaz.
+func workspaceID(sub policy.Subject) (int64, error) {
+	if sub.WorkspaceID == nil {
+		return 0, ErrNotFound
+	}
+	return *sub.WorkspaceID, nil
+}
+
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MAX_NAME_LENGTH {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
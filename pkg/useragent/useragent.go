@@ -0,0 +1,83 @@
+package useragent
+
+import "strings"
+
+const (
+	DEVICE_DESKTOP = "desktop"
+	DEVICE_MOBILE  = "mobile"
+	DEVICE_TABLET  = "tablet"
+	DEVICE_BOT     = "bot"
+
+	OS_IOS     = "ios"
+	OS_ANDROID = "android"
+	OS_WINDOWS = "windows"
+	OS_MACOS   = "macos"
+	OS_LINUX   = "linux"
+	OS_OTHER   = "other"
+)
+
+type Info struct {
+	Device string
+	OS     string
+}
+
+var botMarkers = []string{"bot", "crawler", "spider", "slurp", "curl/", "wget/", "python-requests", "go-http-client"}
+
+// Parse User-Agent'tan kaba cihaz ve işletim sistemi bilgisini çıkarır.
+// Tam bir UA parser değil; yönlendirme kuralları için yeterli sınıflandırma.
+func Parse(ua string) Info {
+	s := strings.ToLower(ua)
+	info := Info{Device: DEVICE_DESKTOP, OS: OS_OTHER}
+
+	switch {
+	case strings.Contains(s, "iphone"), strings.Contains(s, "ipod"):
+		info.OS, info.Device = OS_IOS, DEVICE_MOBILE
+	case strings.Contains(s, "ipad"):
+		info.OS, info.Device = OS_IOS, DEVICE_TABLET
+	case strings.Contains(s, "android"):
+		info.OS = OS_ANDROID
+		// Android tabletler "Mobile" token'ı göndermez.
+		if strings.Contains(s, "mobile") {
+			info.Device = DEVICE_MOBILE
+		} else {
+			info.Device = DEVICE_TABLET
+		}
+	case strings.Contains(s, "windows"):
+		info.OS = OS_WINDOWS
+	case strings.Contains(s, "mac os x"), strings.Contains(s, "macintosh"):
+		info.OS = OS_MACOS
+	case strings.Contains(s, "linux"), strings.Contains(s, "x11"):
+		info.OS = OS_LINUX
+	}
+
+	if s == "" {
+		info.Device = DEVICE_BOT
+		return info
+	}
+	for _, m := range botMarkers {
+		if strings.Contains(s, m) {
+			info.Device = DEVICE_BOT
+			break
+		}
+	}
+	return info
+}
+
+// crawlerMarkers link önizleme kartı için sayfayı çeken sosyal medya / mesajlaşma botları.
+// Arama motorları bilerek listede yok; onlar gerçek yönlendirmeyi takip etmeli.
+var crawlerMarkers = []string{
+	"facebookexternalhit", "facebot", "twitterbot", "linkedinbot", "slackbot", "slack-imgproxy",
+	"discordbot", "telegrambot", "whatsapp", "pinterest", "redditbot", "applebot", "skypeuripreview",
+	"embedly", "vkshare", "mastodon", "bluesky", "iframely",
+}
+
+// IsCrawler UA bir link önizleme botuna mı ait.
+func IsCrawler(ua string) bool {
+	s := strings.ToLower(ua)
+	for _, m := range crawlerMarkers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
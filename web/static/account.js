@@ -0,0 +1,408 @@
+// Oturum bilgisi; değiştiren isteklerde X-CSRF-Token olarak gönderilir
+let csrfToken = '';
+let mode = 'login';
+// Seçili workspace; boşsa kişisel alan. /v1/me/ isteklerine X-Workspace-ID olarak eklenir
+let workspaceId = '';
+let workspaces = [];
+
+const sections = ['authSection', 'forgotSection', 'resetSection', 'accountSection'];
+const errorBox = document.getElementById('error');
+const errorMessage = document.getElementById('errorMessage');
+const notice = document.getElementById('notice');
+
+const errorMessages = {
+    invalid_email: 'Geçersiz e-posta adresi',
+    invalid_password: 'Şifre 8-72 karakter olmalı',
+    email_taken: 'Bu e-posta ile zaten bir hesap var',
+    invalid_credentials: 'E-posta veya şifre hatalı',
+    too_many_attempts: 'Çok fazla deneme yaptın. Lütfen biraz bekle.',
+    invalid_token: 'Sıfırlama linki geçersiz veya süresi dolmuş',
+    rate_limited: 'Çok fazla istek gönderdin. Lütfen biraz bekle.',
+    sso_denied: 'Şirket hesabıyla giriş iptal edildi',
+    sso_not_allowed: 'Bu hesapla giriş yapma yetkin yok',
+    sso_email_unverified: 'Şirket hesabında doğrulanmış bir e-posta yok',
+    sso_account_exists: 'Bu e-postayla şifreli bir hesap var; e-posta ve şifrenle giriş yap',
+    sso_unavailable: 'Kimlik sağlayıcıya ulaşılamadı. Lütfen tekrar deneyin.',
+    invalid_state: 'Giriş isteğinin süresi doldu. Lütfen tekrar deneyin.',
+    invalid_name: 'Workspace adı 1-100 karakter olmalı',
+    invalid_role: 'Geçersiz rol',
+    invalid_invitation: 'Davet linki geçersiz, kullanılmış veya süresi dolmuş',
+    invitation_email_mismatch: 'Bu davet başka bir e-posta adresine gönderilmiş',
+    already_member: 'Zaten bu workspace\'in üyesi',
+    too_many_invitations: 'Bekleyen davet sayısı sınıra ulaştı',
+    workspace_not_found: 'Workspace bulunamadı',
+    forbidden: 'Bu işlem için yetkin yok',
+    invalid_scope: 'En az bir geçerli kapsam seç',
+    invalid_expiry: 'Geçerlilik 1-365 gün olmalı',
+    too_many_tokens: 'Aktif token sayısı sınıra ulaştı',
+};
+
+function show(id) {
+    sections.forEach((s) => {
+        document.getElementById(s).style.display = s === id ? 'block' : 'none';
+    });
+}
+
+function showError(text) {
+    notice.style.display = 'none';
+    errorMessage.textContent = errorMessages[text] || 'Bir hata oluştu. Lütfen tekrar deneyin.';
+    errorBox.style.display = 'flex';
+}
+
+function showNotice(text) {
+    errorBox.style.display = 'none';
+    notice.textContent = text;
+    notice.style.display = 'block';
+}
+
+function clearMessages() {
+    errorBox.style.display = 'none';
+    notice.style.display = 'none';
+}
+
+async function api(method, path, body) {
+    const headers = {};
+    if (body !== undefined) {
+        headers['Content-Type'] = 'application/json';
+    }
+    if (csrfToken && method !== 'GET') {
+        headers['X-CSRF-Token'] = csrfToken;
+    }
+    if (workspaceId && path.startsWith('/v1/me/')) {
+        headers['X-Workspace-ID'] = workspaceId;
+    }
+    const response = await fetch(path, {
+        method: method,
+        headers: headers,
+        credentials: 'same-origin',
+        body: body !== undefined ? JSON.stringify(body) : undefined,
+    });
+    if (!response.ok) {
+        throw new Error(await response.text());
+    }
+    return response;
+}
+
+async function signedIn(data) {
+    csrfToken = data.csrf_token;
+    document.getElementById('userEmail').textContent = data.user.email;
+    show('accountSection');
+    await acceptInvitation();
+    await loadWorkspaces();
+    await loadLinks();
+    await loadTokens();
+}
+
+async function loadTokens() {
+    const response = await api('GET', '/v1/tokens');
+    const data = await response.json();
+    const list = document.getElementById('tokenList');
+    list.innerHTML = '';
+    const now = new Date();
+
+    data.tokens.forEach((token) => {
+        const expired = new Date(token.expires_at) <= now;
+        const item = document.createElement('li');
+        item.className = 'link-item' + (token.revoked_at || expired ? ' disabled' : '');
+
+        const info = document.createElement('div');
+        const title = document.createElement('div');
+        title.textContent = token.name + ' (' + token.prefix + '…)';
+        const meta = document.createElement('div');
+        meta.className = 'muted';
+        meta.textContent = token.scopes.join(', ') + ' · son kullanım: ' +
+            (token.last_used_at ? new Date(token.last_used_at).toLocaleString() : 'hiç') +
+            ' · bitiş: ' + new Date(token.expires_at).toLocaleDateString();
+        info.append(title, meta);
+        item.append(info);
+
+        if (token.revoked_at || expired) {
+            const label = document.createElement('span');
+            label.className = 'muted';
+            label.textContent = token.revoked_at ? 'İptal edildi' : 'Süresi doldu';
+            item.append(label);
+        } else {
+            const btn = document.createElement('button');
+            btn.type = 'button';
+            btn.className = 'link-btn';
+            btn.textContent = 'İptal et';
+            btn.addEventListener('click', async () => {
+                if (!confirm(token.name + ' token\'ı iptal edilsin mi?')) {
+                    return;
+                }
+                try {
+                    await api('DELETE', '/v1/tokens/' + encodeURIComponent(token.id));
+                    await loadTokens();
+                } catch (err) {
+                    showError(err.message);
+                }
+            });
+            item.append(btn);
+        }
+        list.append(item);
+    });
+}
+
+// Davet linkiyle gelindiyse (giriş yapılmamışsa girişten sonra) daveti kabul eder
+async function acceptInvitation() {
+    const token = sessionStorage.getItem('invite');
+    if (!token) {
+        return;
+    }
+    sessionStorage.removeItem('invite');
+    try {
+        const response = await api('POST', '/v1/invitations/accept', {token: token});
+        const ws = await response.json();
+        workspaceId = String(ws.id);
+        showNotice(ws.name + ' workspace\'ine katıldın.');
+    } catch (err) {
+        showError(err.message);
+    }
+}
+
+async function loadWorkspaces() {
+    try {
+        const response = await api('GET', '/v1/workspaces');
+        workspaces = (await response.json()).workspaces;
+    } catch (err) {
+        workspaces = [];
+    }
+    if (!workspaces.some((ws) => String(ws.id) === workspaceId)) {
+        workspaceId = '';
+    }
+
+    const select = document.getElementById('workspaceSelect');
+    select.innerHTML = '';
+    select.append(new Option('Kişisel', ''));
+    workspaces.forEach((ws) => select.append(new Option(ws.name + ' (' + ws.role + ')', String(ws.id))));
+    select.value = workspaceId;
+    updateInviteForm();
+}
+
+// Davet formu sadece owner/admin rolünde görünür
+function updateInviteForm() {
+    const ws = workspaces.find((w) => String(w.id) === workspaceId);
+    const canInvite = ws && (ws.role === 'owner' || ws.role === 'admin');
+    document.getElementById('inviteForm').style.display = canInvite ? 'flex' : 'none';
+}
+
+async function loadLinks() {
+    const response = await api('GET', '/v1/me/links?limit=100');
+    const data = await response.json();
+    const list = document.getElementById('linkList');
+    list.innerHTML = '';
+    document.getElementById('emptyLinks').style.display = data.links.length ? 'none' : 'block';
+
+    data.links.forEach((link) => {
+        const item = document.createElement('li');
+        item.className = 'link-item' + (link.disabled ? ' disabled' : '');
+
+        const info = document.createElement('div');
+        const short = document.createElement('a');
+        short.href = link.short_url;
+        short.target = '_blank';
+        short.textContent = link.short_url;
+        const target = document.createElement('div');
+        target.className = 'muted';
+        target.textContent = link.title ? link.title + ' — ' + link.target : link.target;
+        info.append(short, target);
+        item.append(info);
+
+        if (!link.disabled) {
+            const btn = document.createElement('button');
+            btn.type = 'button';
+            btn.className = 'link-btn';
+            btn.textContent = 'Kapat';
+            btn.addEventListener('click', async () => {
+                if (!confirm(link.short_url + ' kapatılsın mı? Bu işlem geri alınamaz.')) {
+                    return;
+                }
+                try {
+                    await api('DELETE', '/v1/me/links/' + encodeURIComponent(link.code));
+                    await loadLinks();
+                } catch (err) {
+                    showError(err.message);
+                }
+            });
+            item.append(btn);
+        } else {
+            const label = document.createElement('span');
+            label.className = 'muted';
+            label.textContent = 'Kapalı';
+            item.append(label);
+        }
+        list.append(item);
+    });
+}
+
+// Giriş / kayıt sekmeleri
+document.querySelectorAll('.tab').forEach((tab) => {
+    tab.addEventListener('click', () => {
+        mode = tab.dataset.tab;
+        document.querySelectorAll('.tab').forEach((t) => t.classList.toggle('active', t === tab));
+        document.getElementById('authBtn').textContent = mode === 'login' ? 'Giriş yap' : 'Kayıt ol';
+        document.getElementById('password').autocomplete = mode === 'login' ? 'current-password' : 'new-password';
+        clearMessages();
+    });
+});
+
+document.getElementById('authForm').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    clearMessages();
+    try {
+        const response = await api('POST', '/v1/auth/' + mode, {
+            email: document.getElementById('email').value.trim(),
+            password: document.getElementById('password').value,
+        });
+        document.getElementById('password').value = '';
+        await signedIn(await response.json());
+    } catch (err) {
+        showError(err.message);
+    }
+});
+
+document.getElementById('forgotBtn').addEventListener('click', () => {
+    clearMessages();
+    show('forgotSection');
+});
+
+document.getElementById('backBtn').addEventListener('click', () => {
+    clearMessages();
+    show('authSection');
+});
+
+document.getElementById('forgotForm').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    try {
+        await api('POST', '/v1/auth/password/forgot', {email: document.getElementById('forgotEmail').value.trim()});
+        showNotice('Bu e-posta ile bir hesap varsa sıfırlama linki gönderildi.');
+    } catch (err) {
+        showError(err.message);
+    }
+});
+
+document.getElementById('resetForm').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    const token = new URLSearchParams(location.search).get('reset');
+    try {
+        await api('POST', '/v1/auth/password/reset', {token: token, password: document.getElementById('newPassword').value});
+        // token adres çubuğunda kalmasın
+        history.replaceState(null, '', '/account');
+        show('authSection');
+        showNotice('Şifren değişti, yeni şifrenle giriş yapabilirsin.');
+    } catch (err) {
+        showError(err.message);
+    }
+});
+
+document.getElementById('workspaceSelect').addEventListener('change', async (e) => {
+    clearMessages();
+    workspaceId = e.target.value;
+    updateInviteForm();
+    try {
+        await loadLinks();
+    } catch (err) {
+        showError(err.message);
+    }
+});
+
+document.getElementById('newWorkspaceBtn').addEventListener('click', async () => {
+    const name = prompt('Workspace adı');
+    if (name === null) {
+        return;
+    }
+    clearMessages();
+    try {
+        const response = await api('POST', '/v1/workspaces', {name: name});
+        workspaceId = String((await response.json()).id);
+        await loadWorkspaces();
+        await loadLinks();
+    } catch (err) {
+        showError(err.message);
+    }
+});
+
+document.getElementById('inviteForm').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    clearMessages();
+    const email = document.getElementById('inviteEmail');
+    try {
+        await api('POST', '/v1/workspaces/' + encodeURIComponent(workspaceId) + '/invitations', {
+            email: email.value.trim(),
+            role: document.getElementById('inviteRole').value,
+        });
+        showNotice(email.value.trim() + ' adresine davet gönderildi.');
+        email.value = '';
+    } catch (err) {
+        showError(err.message);
+    }
+});
+
+document.getElementById('tokenForm').addEventListener('submit', async (e) => {
+    e.preventDefault();
+    clearMessages();
+    const scopes = Array.from(document.querySelectorAll('input[name="scope"]:checked')).map((el) => el.value);
+    try {
+        const response = await api('POST', '/v1/tokens', {
+            name: document.getElementById('tokenName').value.trim(),
+            scopes: scopes,
+            expires_in_days: Number(document.getElementById('tokenDays').value),
+        });
+        const created = await response.json();
+        // token bir daha gösterilmez
+        const box = document.getElementById('newToken');
+        box.textContent = 'Token\'ı şimdi kopyala, bir daha gösterilmeyecek: ' + created.token;
+        box.style.display = 'block';
+        document.getElementById('tokenName').value = '';
+        await loadTokens();
+    } catch (err) {
+        showError(err.message);
+    }
+});
+
+document.getElementById('logoutBtn').addEventListener('click', async () => {
+    try {
+        await api('POST', '/v1/auth/logout');
+    } catch (err) {
+        // oturum zaten düşmüş olabilir
+    }
+    csrfToken = '';
+    workspaceId = '';
+    document.getElementById('newToken').style.display = 'none';
+    show('authSection');
+});
+
+async function loadProviders() {
+    try {
+        const response = await api('GET', '/v1/auth/providers');
+        const providers = await response.json();
+        document.getElementById('ssoBtn').style.display = providers.oidc ? 'block' : 'none';
+    } catch (err) {
+        // SSO butonu gizli kalır
+    }
+}
+
+window.addEventListener('load', async () => {
+    const params = new URLSearchParams(location.search);
+    if (params.has('reset')) {
+        show('resetSection');
+        return;
+    }
+    loadProviders();
+    if (params.has('invite')) {
+        // SSO dönüşünde de kaybolmasın; token adres çubuğunda kalmasın
+        sessionStorage.setItem('invite', params.get('invite'));
+        history.replaceState(null, '', '/account');
+        showNotice('Daveti kabul etmek için davet edilen e-posta adresiyle giriş yap.');
+    }
+    if (params.has('sso_error')) {
+        showError(params.get('sso_error'));
+        history.replaceState(null, '', '/account');
+    }
+    try {
+        const response = await api('GET', '/v1/auth/me');
+        await signedIn(await response.json());
+    } catch (err) {
+        show('authSection');
+    }
+});
@@ -14,6 +14,9 @@ const copyBtn = document.getElementById('copyBtn');
 const newBtn = document.getElementById('newBtn');
 const errorMessage = document.getElementById('errorMessage');
 
+// Oturum açıksa linkler hesaba bağlanır; değiştiren isteklerde CSRF token'ı gerekir
+let csrfToken = '';
+
 // Toggle custom alias input
 customAliasToggle.addEventListener('change', (e) => {
     if (e.target.checked) {
@@ -56,11 +59,23 @@ form.addEventListener('submit', async (e) => {
     }
 
     try {
+        const headers = {
+            'Content-Type': 'application/json',
+        };
+
+        // Sunucu istiyorsa proof-of-work bulmacasını çöz; oturum açık kullanıcılardan istenmez
+        const pow = csrfToken ? null : await solveChallenge();
+        if (csrfToken) {
+            headers['X-CSRF-Token'] = csrfToken;
+        }
+        if (pow) {
+            headers['X-PoW-Challenge'] = pow.challenge;
+            headers['X-PoW-Solution'] = pow.solution;
+        }
+
         const response = await fetch('/v1/shorten', {
             method: 'POST',
-            headers: {
-                'Content-Type': 'application/json',
-            },
+            headers: headers,
             body: JSON.stringify(requestBody)
         });
 
@@ -80,7 +95,9 @@ form.addEventListener('submit', async (e) => {
             errorMsg = 'Bu özel link adı zaten kullanılıyor. Lütfen başka bir isim deneyin.';
         } else if (err.message.includes('bad_request')) {
             errorMsg = 'Geçersiz istek. Lütfen bilgileri kontrol edin.';
-        } else if (err.message.includes('rate limit')) {
+        } else if (err.message.includes('pow')) {
+            errorMsg = 'Doğrulama başarısız oldu. Lütfen tekrar deneyin.';
+        } else if (err.message.includes('rate_limited')) {
             errorMsg = 'Çok fazla istek gönderdiniz. Lütfen biraz bekleyin.';
         }
 
@@ -88,6 +105,40 @@ form.addEventListener('submit', async (e) => {
     }
 });
 
+// Proof-of-work: sha256(challenge + ":" + solution) en az `difficulty` sıfır bitle başlamalı.
+// Bulmaca kapalıysa null döner.
+async function solveChallenge() {
+    const response = await fetch('/v1/challenge');
+    if (!response.ok) {
+        throw new Error(await response.text());
+    }
+
+    const challenge = await response.json();
+    if (!challenge.required) {
+        return null;
+    }
+
+    const encoder = new TextEncoder();
+    for (let i = 0; ; i++) {
+        const solution = i.toString(36);
+        const digest = await crypto.subtle.digest('SHA-256', encoder.encode(challenge.challenge + ':' + solution));
+        if (leadingZeroBits(new Uint8Array(digest)) >= challenge.difficulty) {
+            return { challenge: challenge.challenge, solution: solution };
+        }
+    }
+}
+
+function leadingZeroBits(bytes) {
+    let n = 0;
+    for (const b of bytes) {
+        if (b !== 0) {
+            return n + Math.clz32(b) - 24;
+        }
+        n += 8;
+    }
+    return n;
+}
+
 // Copy button
 copyBtn.addEventListener('click', async () => {
     const url = shortUrl.href;
@@ -160,6 +211,17 @@ function hideAll() {
 }
 
 // Auto-focus on load
-window.addEventListener('load', () => {
+window.addEventListener('load', async () => {
     urlInput.focus();
+
+    try {
+        const response = await fetch('/v1/auth/me', {credentials: 'same-origin'});
+        if (response.ok) {
+            const data = await response.json();
+            csrfToken = data.csrf_token;
+            document.getElementById('accountLink').textContent = data.user.email;
+        }
+    } catch (err) {
+        // anonim devam
+    }
 });
\ No newline at end of file